@@ -0,0 +1,78 @@
+package main
+
+import (
+	"context"
+	"log"
+	"log/slog"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/username/progetto/auth/internal/migrations"
+	"github.com/username/progetto/shared/pkg/database/postgres"
+	"gorm.io/gorm"
+)
+
+// Usage: migrate [up | down [steps] | version], up is the default
+func main() {
+	dsn := os.Getenv("APP_DB_DSN")
+	if dsn == "" {
+		log.Fatal("APP_DB_DSN environment variable is not set")
+	}
+
+	command := "up"
+	if len(os.Args) > 1 {
+		command = os.Args[1]
+	}
+
+	log.Println("Waiting for Postgres to be ready...")
+	var db *gorm.DB
+	var err error
+	for i := 0; i < 30; i++ {
+		db, err = postgres.NewPostgres(dsn, slog.Default())
+		if err == nil {
+			break
+		}
+		log.Printf("Postgres unavailable: %v. Retrying...", err)
+		time.Sleep(2 * time.Second)
+	}
+	if err != nil {
+		log.Fatalf("Failed to connect to Postgres after retries: %v", err)
+	}
+
+	migrator, err := postgres.NewMigrator(db, migrations.FS, migrations.Shared...)
+	if err != nil {
+		log.Fatalf("Failed to load migrations: %v", err)
+	}
+
+	ctx := context.Background()
+	switch command {
+	case "up":
+		applied, err := migrator.Up(ctx)
+		if err != nil {
+			log.Fatalf("Migration failed: %v", err)
+		}
+		log.Printf("Applied %d migrations.", applied)
+	case "down":
+		steps := 1
+		if len(os.Args) > 2 {
+			steps, err = strconv.Atoi(os.Args[2])
+			if err != nil || steps < 1 {
+				log.Fatalf("Invalid number of steps %q", os.Args[2])
+			}
+		}
+		reverted, err := migrator.Down(ctx, steps)
+		if err != nil {
+			log.Fatalf("Rollback failed: %v", err)
+		}
+		log.Printf("Reverted %d migrations.", reverted)
+	case "version":
+		version, err := migrator.Version(ctx)
+		if err != nil {
+			log.Fatalf("Failed to read version: %v", err)
+		}
+		log.Printf("Schema version: %d", version)
+	default:
+		log.Fatalf("Unknown command %q, expected up, down or version", command)
+	}
+}
@@ -0,0 +1,43 @@
+// Command mockoidc runs an OpenID Connect provider that logs in a configurable user
+// without any interaction, so social login can be exercised offline.
+package main
+
+import (
+	"log"
+	"net/http"
+	"os"
+
+	"github.com/username/progetto/auth/internal/oidc"
+)
+
+func main() {
+	addr := getEnv("MOCK_OIDC_ADDR", ":9400")
+
+	provider, err := oidc.NewMockProvider(oidc.MockConfig{
+		Issuer:       getEnv("MOCK_OIDC_ISSUER", "http://mock-oidc:9400"),
+		ClientID:     getEnv("MOCK_OIDC_CLIENT_ID", "vibely"),
+		ClientSecret: getEnv("MOCK_OIDC_CLIENT_SECRET", "vibely-secret"),
+		User: oidc.MockUser{
+			Subject:           getEnv("MOCK_OIDC_SUBJECT", "mock-user-1"),
+			Email:             getEnv("MOCK_OIDC_EMAIL", "mock.user@example.com"),
+			EmailVerified:     getEnv("MOCK_OIDC_EMAIL_VERIFIED", "true") == "true",
+			Name:              getEnv("MOCK_OIDC_NAME", "Mock User"),
+			PreferredUsername: getEnv("MOCK_OIDC_USERNAME", "mockuser"),
+		},
+	})
+	if err != nil {
+		log.Fatalf("failed to create mock provider: %v", err)
+	}
+
+	log.Printf("Mock OIDC provider listening on %s", addr)
+	if err := http.ListenAndServe(addr, provider.Handler()); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func getEnv(key, fallback string) string {
+	if v := os.Getenv(key); v != "" {
+		return v
+	}
+	return fallback
+}
@@ -2,29 +2,91 @@ package config
 
 import (
 	"fmt"
+	"math"
 	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/username/progetto/auth/internal/oidc"
 )
 
 type Config struct {
-	DbDSN                string
-	RedisAddr            string
-	KafkaBrokers         string
-	JwtSecret            string
-	OtelExporterEndpoint string
-	OtelServiceName      string
+	DbDSN                   string
+	RedisAddr               string
+	KafkaBrokers            string
+	JwtAlgorithm            string
+	JwtRotationInterval     time.Duration
+	UnverifiedUserMaxAge    time.Duration
+	UnverifiedPurgeInterval time.Duration
+	TotpEncryptionKey       string
+	SigningKeyEncryptionKey string
+	TotpIssuer              string
+	OIDCProviders           []oidc.Config
+	DeletionRetryInterval   time.Duration
+	DeletionRetryAfter      time.Duration
+	OutboxRelayInterval     time.Duration
+	OutboxRetention         time.Duration
+	PasswordAlgorithm       string
+	Argon2MemoryKiB         int
+	Argon2Iterations        int
+	Argon2Parallelism       int
+	BcryptCost              int
+	TrustedServices         string
+	OtelExporterEndpoint    string
+	OtelServiceName         string
 }
 
 func Load() *Config {
 	return &Config{
-		DbDSN:                mustGetEnv("APP_DB_DSN"),
-		RedisAddr:            mustGetEnv("APP_REDIS_ADDR"),
-		KafkaBrokers:         mustGetEnv("APP_KAFKA_BROKERS"),
-		JwtSecret:            mustGetEnv("APP_JWT_SECRET"),
-		OtelExporterEndpoint: getEnv("OTEL_EXPORTER_OTLP_ENDPOINT", "alloy:4317"),
-		OtelServiceName:      getEnv("OTEL_SERVICE_NAME", "auth-service"),
+		DbDSN:                   mustGetEnv("APP_DB_DSN"),
+		RedisAddr:               mustGetEnv("APP_REDIS_ADDR"),
+		KafkaBrokers:            mustGetEnv("APP_KAFKA_BROKERS"),
+		JwtAlgorithm:            getEnv("APP_JWT_ALGORITHM", "EdDSA"),
+		JwtRotationInterval:     getDurationEnv("APP_JWT_ROTATION_INTERVAL", 30*24*time.Hour),
+		UnverifiedUserMaxAge:    getDurationEnv("APP_UNVERIFIED_USER_MAX_AGE", 7*24*time.Hour),
+		UnverifiedPurgeInterval: getDurationEnv("APP_UNVERIFIED_PURGE_INTERVAL", time.Hour),
+		TotpEncryptionKey:       mustGetEnv("APP_TOTP_ENCRYPTION_KEY"),
+		SigningKeyEncryptionKey: mustGetEnv("APP_SIGNING_KEY_ENCRYPTION_KEY"),
+		TotpIssuer:              getEnv("APP_TOTP_ISSUER", "Vibely"),
+		OIDCProviders:           loadOIDCProviders(),
+		DeletionRetryInterval:   getDurationEnv("APP_DELETION_RETRY_INTERVAL", time.Minute),
+		DeletionRetryAfter:      getDurationEnv("APP_DELETION_RETRY_AFTER", 10*time.Minute),
+		OutboxRelayInterval:     getDurationEnv("APP_OUTBOX_RELAY_INTERVAL", time.Second),
+		OutboxRetention:         getDurationEnv("APP_OUTBOX_RETENTION", 24*time.Hour),
+		PasswordAlgorithm:       getEnv("APP_PASSWORD_ALGORITHM", "argon2id"),
+		Argon2MemoryKiB:         getIntRangeEnv("APP_ARGON2_MEMORY_KIB", 19*1024, 8, math.MaxUint32),
+		Argon2Iterations:        getIntRangeEnv("APP_ARGON2_ITERATIONS", 2, 1, math.MaxUint32),
+		Argon2Parallelism:       getIntRangeEnv("APP_ARGON2_PARALLELISM", 1, 1, math.MaxUint8),
+		BcryptCost:              getIntEnv("APP_BCRYPT_COST", 10),
+		TrustedServices:         mustGetEnv("APP_TRUSTED_SERVICES"),
+		OtelExporterEndpoint:    getEnv("OTEL_EXPORTER_OTLP_ENDPOINT", "alloy:4317"),
+		OtelServiceName:         getEnv("OTEL_SERVICE_NAME", "auth-service"),
 	}
 }
 
+// loadOIDCProviders reads APP_OIDC_PROVIDERS (e.g. "google,mock") and the
+// APP_OIDC_<NAME>_ISSUER, _CLIENT_ID, _CLIENT_SECRET, _REDIRECT_URL and _SCOPES of each provider
+func loadOIDCProviders() []oidc.Config {
+	var providers []oidc.Config
+	for _, name := range strings.Split(os.Getenv("APP_OIDC_PROVIDERS"), ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		prefix := "APP_OIDC_" + strings.ToUpper(name) + "_"
+		providers = append(providers, oidc.Config{
+			Name:         name,
+			Issuer:       mustGetEnv(prefix + "ISSUER"),
+			ClientID:     mustGetEnv(prefix + "CLIENT_ID"),
+			ClientSecret: getEnv(prefix+"CLIENT_SECRET", ""),
+			RedirectURL:  mustGetEnv(prefix + "REDIRECT_URL"),
+			Scopes:       strings.Fields(getEnv(prefix+"SCOPES", "openid email profile")),
+		})
+	}
+	return providers
+}
+
 func mustGetEnv(key string) string {
 	v := os.Getenv(key)
 	if v == "" {
@@ -39,3 +101,36 @@ func getEnv(key, fallback string) string {
 	}
 	return fallback
 }
+
+func getDurationEnv(key string, fallback time.Duration) time.Duration {
+	v := os.Getenv(key)
+	if v == "" {
+		return fallback
+	}
+	d, err := time.ParseDuration(v)
+	if err != nil {
+		panic(fmt.Sprintf("%s is not a valid duration: %v", key, err))
+	}
+	return d
+}
+
+func getIntEnv(key string, fallback int) int {
+	v := os.Getenv(key)
+	if v == "" {
+		return fallback
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil {
+		panic(fmt.Sprintf("%s is not a valid integer: %v", key, err))
+	}
+	return n
+}
+
+// getIntRangeEnv is getIntEnv for values converted to narrower types, which must not wrap around
+func getIntRangeEnv(key string, fallback, minValue, maxValue int) int {
+	n := getIntEnv(key, fallback)
+	if n < minValue || n > maxValue {
+		panic(fmt.Sprintf("%s must be between %d and %d", key, minValue, maxValue))
+	}
+	return n
+}
@@ -2,6 +2,7 @@ package events
 
 import (
 	"context"
+	"fmt"
 	"log/slog"
 
 	"github.com/ThreeDotsLabs/watermill/message"
@@ -51,6 +52,17 @@ func NewEventRouter(logger *slog.Logger, brokers string, authSvc service.AuthSer
 		sagaHandler.HandleUserCreationFailed,
 	)
 
+	// Account deletion confirmations, one topic per service holding a replica
+	deletedTopics := []string{"user_deleted_post", "user_deleted_social", "user_deleted_search", "user_deleted_messaging"}
+	for _, topic := range deletedTopics {
+		router.AddConsumerHandler(
+			fmt.Sprintf("auth_%s_handler", topic),
+			topic,
+			subscriber,
+			sagaHandler.HandleUserDeleted,
+		)
+	}
+
 	return &EventRouter{
 		Router:     router,
 		Subscriber: subscriber,
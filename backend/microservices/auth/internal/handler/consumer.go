@@ -3,6 +3,7 @@ package handler
 import (
 	"encoding/json"
 	"log/slog"
+	"strings"
 
 	"github.com/ThreeDotsLabs/watermill/message"
 	"github.com/username/progetto/auth/internal/service"
@@ -46,3 +47,30 @@ func (h *SagaHandler) HandleUserCreationFailed(msg *message.Message) error {
 	slog.InfoContext(msg.Context(), "Successfully compensated user creation (user deleted)", "user_id", userID)
 	return nil
 }
+
+// HandleUserDeleted records the confirmation of a service, the topic tells which one
+func (h *SagaHandler) HandleUserDeleted(msg *message.Message) error {
+	var payload struct {
+		DeletionID string `json:"deletion_id"`
+		UserID     string `json:"user_id"`
+	}
+	if err := json.Unmarshal(msg.Payload, &payload); err != nil || payload.DeletionID == "" {
+		slog.ErrorContext(msg.Context(), "invalid user deletion confirmation", "error", err)
+		return nil // Ack, nothing we can do
+	}
+
+	topic := message.SubscribeTopicFromCtx(msg.Context())
+	serviceName := strings.TrimPrefix(topic, "user_deleted_")
+
+	if err := h.authService.ConfirmUserDeletion(msg.Context(), payload.DeletionID, serviceName); err != nil {
+		if err == service.ErrDeletionNotFound {
+			slog.WarnContext(msg.Context(), "confirmation for unknown account deletion", "deletion_id", payload.DeletionID)
+			return nil
+		}
+		slog.ErrorContext(msg.Context(), "failed to confirm user deletion", "error", err, "deletion_id", payload.DeletionID, "service", serviceName)
+		return err // Retry
+	}
+
+	slog.InfoContext(msg.Context(), "user deletion confirmed", "deletion_id", payload.DeletionID, "user_id", payload.UserID, "service", serviceName)
+	return nil
+}
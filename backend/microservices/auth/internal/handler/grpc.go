@@ -2,14 +2,22 @@ package handler
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
 
 	"log/slog"
 
+	"github.com/username/progetto/auth/internal/model"
+	"github.com/username/progetto/auth/internal/repository"
 	"github.com/username/progetto/auth/internal/service"
 	"github.com/username/progetto/auth/internal/validator"
 	authv1 "github.com/username/progetto/proto/gen/go/auth/v1"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
 )
 
 type AuthHandler struct {
@@ -27,11 +35,14 @@ func NewAuthHandler(service service.AuthService) *AuthHandler {
 
 func (h *AuthHandler) Register(ctx context.Context, req *authv1.RegisterRequest) (*authv1.RegisterResponse, error) {
 	if err := validator.ValidateRegister(req.Email, req.Password, req.Username); err != nil {
-		return nil, status.Error(codes.InvalidArgument, err.Error())
+		return nil, invalidArgument(err)
 	}
 
 	userID, accessToken, refreshToken, expiresIn, err := h.service.Register(ctx, req.Email, req.Password, req.Username)
 	if err != nil {
+		if err == service.ErrEmailTaken || err == service.ErrUsernameTaken {
+			return nil, alreadyExists(err)
+		}
 		h.logger.ErrorContext(ctx, "failed to register user", "error", err, "email", req.Email)
 		return nil, status.Errorf(codes.Internal, "failed to register: %v", err)
 	}
@@ -45,16 +56,34 @@ func (h *AuthHandler) Register(ctx context.Context, req *authv1.RegisterRequest)
 }
 
 func (h *AuthHandler) Login(ctx context.Context, req *authv1.LoginRequest) (*authv1.LoginResponse, error) {
-	accessToken, refreshToken, expiresIn, err := h.service.Login(ctx, req.Email, req.Password)
+	accessToken, refreshToken, expiresIn, challengeToken, err := h.service.Login(ctx, req.Email, req.Password)
 	if err != nil {
 		if err == service.ErrInvalidCredentials {
 			h.logger.WarnContext(ctx, "invalid login attempt", "email", req.Email)
 			return nil, status.Error(codes.Unauthenticated, "invalid credentials")
 		}
+		if st, ok := suspendedStatus(err); ok {
+			h.logger.WarnContext(ctx, "login attempt on suspended account", "email", req.Email)
+			return nil, st.Err()
+		}
+		var lockedErr *service.AccountLockedError
+		if errors.As(err, &lockedErr) {
+			h.logger.WarnContext(ctx, "login attempt on locked account", "email", req.Email, "retry_after", lockedErr.RetryAfter)
+			st, _ := status.New(codes.ResourceExhausted, "too many failed login attempts, try again later").
+				WithDetails(&errdetails.RetryInfo{RetryDelay: durationpb.New(lockedErr.RetryAfter)})
+			return nil, st.Err()
+		}
 		h.logger.ErrorContext(ctx, "failed to login", "error", err, "email", req.Email)
 		return nil, status.Errorf(codes.Internal, "failed to login: %v", err)
 	}
 
+	if challengeToken != "" {
+		return &authv1.LoginResponse{
+			TwoFactorRequired: true,
+			ChallengeToken:    challengeToken,
+		}, nil
+	}
+
 	return &authv1.LoginResponse{
 		AccessToken:  accessToken,
 		RefreshToken: refreshToken,
@@ -69,6 +98,14 @@ func (h *AuthHandler) Refresh(ctx context.Context, req *authv1.RefreshRequest) (
 			h.logger.WarnContext(ctx, "invalid refresh token attempt")
 			return nil, status.Error(codes.Unauthenticated, "invalid or expired refresh token")
 		}
+		if err == service.ErrTokenReused {
+			h.logger.WarnContext(ctx, "refresh token reuse detected, session revoked")
+			return nil, status.Error(codes.Unauthenticated, "refresh token reused, session revoked")
+		}
+		if st, ok := suspendedStatus(err); ok {
+			h.logger.WarnContext(ctx, "refresh attempt on suspended account")
+			return nil, st.Err()
+		}
 		h.logger.ErrorContext(ctx, "failed to refresh token", "error", err)
 		return nil, status.Errorf(codes.Internal, "failed to refresh token: %v", err)
 	}
@@ -79,3 +116,643 @@ func (h *AuthHandler) Refresh(ctx context.Context, req *authv1.RefreshRequest) (
 		ExpiresIn:    expiresIn,
 	}, nil
 }
+
+func (h *AuthHandler) Logout(ctx context.Context, req *authv1.LogoutRequest) (*authv1.LogoutResponse, error) {
+	if err := h.service.Logout(ctx, req.RefreshToken); err != nil {
+		if err == service.ErrInvalidToken {
+			h.logger.WarnContext(ctx, "logout with invalid refresh token")
+			return nil, status.Error(codes.Unauthenticated, "invalid or expired refresh token")
+		}
+		h.logger.ErrorContext(ctx, "failed to logout", "error", err)
+		return nil, status.Errorf(codes.Internal, "failed to logout: %v", err)
+	}
+
+	return &authv1.LogoutResponse{}, nil
+}
+
+func (h *AuthHandler) ListSessions(ctx context.Context, req *authv1.ListSessionsRequest) (*authv1.ListSessionsResponse, error) {
+	families, currentID, err := h.service.ListSessions(ctx, req.AccessToken)
+	if err != nil {
+		if err == service.ErrInvalidToken {
+			h.logger.WarnContext(ctx, "list sessions with invalid access token")
+			return nil, status.Error(codes.Unauthenticated, "invalid or expired access token")
+		}
+		h.logger.ErrorContext(ctx, "failed to list sessions", "error", err)
+		return nil, status.Errorf(codes.Internal, "failed to list sessions: %v", err)
+	}
+
+	resp := &authv1.ListSessionsResponse{Sessions: make([]*authv1.Session, len(families))}
+	for i, family := range families {
+		resp.Sessions[i] = &authv1.Session{
+			SessionId:  family.ID,
+			Device:     family.Device,
+			Ip:         family.IP,
+			LastIp:     family.LastIP,
+			UserAgent:  family.UserAgent,
+			CreatedAt:  family.CreatedAt.Unix(),
+			LastUsedAt: family.LastUsedAt.Unix(),
+			Current:    family.ID == currentID,
+		}
+	}
+	return resp, nil
+}
+
+func (h *AuthHandler) RevokeSession(ctx context.Context, req *authv1.RevokeSessionRequest) (*authv1.RevokeSessionResponse, error) {
+	if err := h.service.RevokeSession(ctx, req.AccessToken, req.SessionId); err != nil {
+		switch err {
+		case service.ErrInvalidToken:
+			h.logger.WarnContext(ctx, "revoke session with invalid access token")
+			return nil, status.Error(codes.Unauthenticated, "invalid or expired access token")
+		case service.ErrSessionNotFound:
+			return nil, status.Error(codes.NotFound, "session not found")
+		}
+		h.logger.ErrorContext(ctx, "failed to revoke session", "error", err)
+		return nil, status.Errorf(codes.Internal, "failed to revoke session: %v", err)
+	}
+	return &authv1.RevokeSessionResponse{}, nil
+}
+
+func (h *AuthHandler) LogoutAll(ctx context.Context, req *authv1.LogoutAllRequest) (*authv1.LogoutAllResponse, error) {
+	revoked, err := h.service.LogoutAll(ctx, req.AccessToken)
+	if err != nil {
+		if err == service.ErrInvalidToken {
+			h.logger.WarnContext(ctx, "logout all with invalid access token")
+			return nil, status.Error(codes.Unauthenticated, "invalid or expired access token")
+		}
+		h.logger.ErrorContext(ctx, "failed to logout all sessions", "error", err)
+		return nil, status.Errorf(codes.Internal, "failed to logout all sessions: %v", err)
+	}
+
+	return &authv1.LogoutAllResponse{
+		RevokedSessions: revoked,
+	}, nil
+}
+
+func (h *AuthHandler) GetJWKS(ctx context.Context, req *authv1.GetJWKSRequest) (*authv1.GetJWKSResponse, error) {
+	jwks, err := h.service.JWKS(ctx)
+	if err != nil {
+		h.logger.ErrorContext(ctx, "failed to build jwks", "error", err)
+		return nil, status.Errorf(codes.Internal, "failed to get jwks: %v", err)
+	}
+
+	resp := &authv1.GetJWKSResponse{Keys: make([]*authv1.JWK, 0, len(jwks.Keys))}
+	for _, k := range jwks.Keys {
+		resp.Keys = append(resp.Keys, &authv1.JWK{
+			Kty: k.Kty,
+			Kid: k.Kid,
+			Use: k.Use,
+			Alg: k.Alg,
+			N:   k.N,
+			E:   k.E,
+			Crv: k.Crv,
+			X:   k.X,
+		})
+	}
+	return resp, nil
+}
+
+func (h *AuthHandler) RequestPasswordReset(ctx context.Context, req *authv1.RequestPasswordResetRequest) (*authv1.RequestPasswordResetResponse, error) {
+	if req.Email == "" {
+		return nil, status.Error(codes.InvalidArgument, "email is required")
+	}
+
+	if err := h.service.RequestPasswordReset(ctx, req.Email); err != nil {
+		if err == service.ErrTooManyRequests {
+			h.logger.WarnContext(ctx, "password reset rate limit exceeded", "email", req.Email)
+			return nil, status.Error(codes.ResourceExhausted, "too many password reset requests, try again later")
+		}
+		h.logger.ErrorContext(ctx, "failed to request password reset", "error", err, "email", req.Email)
+		return nil, status.Errorf(codes.Internal, "failed to request password reset: %v", err)
+	}
+
+	return &authv1.RequestPasswordResetResponse{}, nil
+}
+
+func (h *AuthHandler) ConfirmPasswordReset(ctx context.Context, req *authv1.ConfirmPasswordResetRequest) (*authv1.ConfirmPasswordResetResponse, error) {
+	if err := validator.ValidatePasswordReset(req.Token, req.NewPassword); err != nil {
+		return nil, invalidArgument(err)
+	}
+
+	if err := h.service.ConfirmPasswordReset(ctx, req.Token, req.NewPassword); err != nil {
+		if err == service.ErrInvalidToken {
+			h.logger.WarnContext(ctx, "invalid password reset token")
+			return nil, status.Error(codes.Unauthenticated, "invalid or expired reset token")
+		}
+		h.logger.ErrorContext(ctx, "failed to reset password", "error", err)
+		return nil, status.Errorf(codes.Internal, "failed to reset password: %v", err)
+	}
+
+	return &authv1.ConfirmPasswordResetResponse{}, nil
+}
+
+func (h *AuthHandler) RequestEmailVerification(ctx context.Context, req *authv1.RequestEmailVerificationRequest) (*authv1.RequestEmailVerificationResponse, error) {
+	if err := h.service.RequestEmailVerification(ctx, req.AccessToken); err != nil {
+		if err == service.ErrInvalidToken {
+			h.logger.WarnContext(ctx, "email verification request with invalid access token")
+			return nil, status.Error(codes.Unauthenticated, "invalid or expired access token")
+		}
+		if err == service.ErrAlreadyVerified {
+			return nil, status.Error(codes.FailedPrecondition, "email already verified")
+		}
+		h.logger.ErrorContext(ctx, "failed to request email verification", "error", err)
+		return nil, status.Errorf(codes.Internal, "failed to request email verification: %v", err)
+	}
+
+	return &authv1.RequestEmailVerificationResponse{}, nil
+}
+
+func (h *AuthHandler) ConfirmEmailVerification(ctx context.Context, req *authv1.ConfirmEmailVerificationRequest) (*authv1.ConfirmEmailVerificationResponse, error) {
+	if req.Token == "" {
+		return nil, status.Error(codes.InvalidArgument, "token is required")
+	}
+
+	if err := h.service.ConfirmEmailVerification(ctx, req.Token); err != nil {
+		if err == service.ErrInvalidToken {
+			h.logger.WarnContext(ctx, "invalid email verification token")
+			return nil, status.Error(codes.Unauthenticated, "invalid or expired verification token")
+		}
+		if err == service.ErrEmailTaken {
+			return nil, alreadyExists(err)
+		}
+		h.logger.ErrorContext(ctx, "failed to verify email", "error", err)
+		return nil, status.Errorf(codes.Internal, "failed to verify email: %v", err)
+	}
+
+	return &authv1.ConfirmEmailVerificationResponse{}, nil
+}
+
+func (h *AuthHandler) EnrollTwoFactor(ctx context.Context, req *authv1.EnrollTwoFactorRequest) (*authv1.EnrollTwoFactorResponse, error) {
+	secret, uri, err := h.service.EnrollTwoFactor(ctx, req.AccessToken)
+	if err != nil {
+		if err == service.ErrInvalidToken {
+			h.logger.WarnContext(ctx, "2fa enrollment with invalid access token")
+			return nil, status.Error(codes.Unauthenticated, "invalid or expired access token")
+		}
+		if err == service.ErrTwoFactorAlreadyEnabled {
+			return nil, status.Error(codes.FailedPrecondition, "two-factor authentication already enabled")
+		}
+		h.logger.ErrorContext(ctx, "failed to enroll 2fa", "error", err)
+		return nil, status.Errorf(codes.Internal, "failed to enroll two-factor authentication: %v", err)
+	}
+
+	return &authv1.EnrollTwoFactorResponse{
+		Secret:          secret,
+		ProvisioningUri: uri,
+	}, nil
+}
+
+func (h *AuthHandler) ConfirmTwoFactor(ctx context.Context, req *authv1.ConfirmTwoFactorRequest) (*authv1.ConfirmTwoFactorResponse, error) {
+	if req.Code == "" {
+		return nil, status.Error(codes.InvalidArgument, "code is required")
+	}
+
+	recoveryCodes, err := h.service.ConfirmTwoFactor(ctx, req.AccessToken, req.Code)
+	if err != nil {
+		switch err {
+		case service.ErrInvalidToken:
+			h.logger.WarnContext(ctx, "2fa confirmation with invalid access token")
+			return nil, status.Error(codes.Unauthenticated, "invalid or expired access token")
+		case service.ErrInvalidTwoFactorCode:
+			return nil, status.Error(codes.InvalidArgument, "invalid code")
+		case service.ErrTwoFactorNotEnrolled, service.ErrTwoFactorAlreadyEnabled:
+			return nil, status.Error(codes.FailedPrecondition, err.Error())
+		}
+		h.logger.ErrorContext(ctx, "failed to confirm 2fa", "error", err)
+		return nil, status.Errorf(codes.Internal, "failed to confirm two-factor authentication: %v", err)
+	}
+
+	return &authv1.ConfirmTwoFactorResponse{
+		RecoveryCodes: recoveryCodes,
+	}, nil
+}
+
+func (h *AuthHandler) VerifyTwoFactor(ctx context.Context, req *authv1.VerifyTwoFactorRequest) (*authv1.VerifyTwoFactorResponse, error) {
+	if req.ChallengeToken == "" || req.Code == "" {
+		return nil, status.Error(codes.InvalidArgument, "challenge token and code are required")
+	}
+
+	accessToken, refreshToken, expiresIn, err := h.service.VerifyTwoFactor(ctx, req.ChallengeToken, req.Code)
+	if err != nil {
+		if err == service.ErrInvalidToken {
+			h.logger.WarnContext(ctx, "invalid or exhausted 2fa challenge")
+			return nil, status.Error(codes.Unauthenticated, "invalid or expired challenge, login again")
+		}
+		if err == service.ErrInvalidTwoFactorCode {
+			h.logger.WarnContext(ctx, "invalid 2fa code")
+			return nil, status.Error(codes.Unauthenticated, "invalid code")
+		}
+		var lockedErr *service.AccountLockedError
+		if errors.As(err, &lockedErr) {
+			h.logger.WarnContext(ctx, "2fa attempt on locked account", "retry_after", lockedErr.RetryAfter)
+			st, _ := status.New(codes.ResourceExhausted, "too many failed login attempts, try again later").
+				WithDetails(&errdetails.RetryInfo{RetryDelay: durationpb.New(lockedErr.RetryAfter)})
+			return nil, st.Err()
+		}
+		h.logger.ErrorContext(ctx, "failed to verify 2fa", "error", err)
+		return nil, status.Errorf(codes.Internal, "failed to verify two-factor code: %v", err)
+	}
+
+	return &authv1.VerifyTwoFactorResponse{
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
+		ExpiresIn:    expiresIn,
+	}, nil
+}
+
+func (h *AuthHandler) GetOIDCAuthURL(ctx context.Context, req *authv1.GetOIDCAuthURLRequest) (*authv1.GetOIDCAuthURLResponse, error) {
+	authURL, state, err := h.service.OIDCAuthURL(ctx, req.Provider)
+	if err != nil {
+		if err == service.ErrUnknownProvider {
+			return nil, status.Errorf(codes.NotFound, "unknown identity provider %q", req.Provider)
+		}
+		h.logger.ErrorContext(ctx, "failed to build oidc auth url", "error", err, "provider", req.Provider)
+		return nil, status.Errorf(codes.Unavailable, "identity provider unavailable: %v", err)
+	}
+
+	return &authv1.GetOIDCAuthURLResponse{
+		AuthUrl: authURL,
+		State:   state,
+	}, nil
+}
+
+func (h *AuthHandler) OIDCCallback(ctx context.Context, req *authv1.OIDCCallbackRequest) (*authv1.OIDCCallbackResponse, error) {
+	if req.Code == "" || req.State == "" {
+		return nil, status.Error(codes.InvalidArgument, "code and state are required")
+	}
+
+	userID, accessToken, refreshToken, expiresIn, challengeToken, err := h.service.OIDCCallback(ctx, req.Provider, req.Code, req.State)
+	if err != nil {
+		switch err {
+		case service.ErrUnknownProvider:
+			return nil, status.Errorf(codes.NotFound, "unknown identity provider %q", req.Provider)
+		case service.ErrInvalidToken:
+			h.logger.WarnContext(ctx, "invalid oidc callback", "provider", req.Provider)
+			return nil, status.Error(codes.Unauthenticated, "invalid or expired login, start again")
+		case service.ErrEmailNotVerified:
+			h.logger.WarnContext(ctx, "oidc login without verified email", "provider", req.Provider)
+			return nil, status.Error(codes.FailedPrecondition, "the provider did not verify your email")
+		case service.ErrAccountNotVerified:
+			h.logger.WarnContext(ctx, "oidc login on unverified account", "provider", req.Provider)
+			return nil, status.Error(codes.FailedPrecondition, "verify your email with the link we sent before signing in with this provider")
+		}
+		if st, ok := suspendedStatus(err); ok {
+			h.logger.WarnContext(ctx, "oidc login attempt on suspended account", "provider", req.Provider)
+			return nil, st.Err()
+		}
+		h.logger.ErrorContext(ctx, "failed to complete oidc login", "error", err, "provider", req.Provider)
+		return nil, status.Errorf(codes.Internal, "failed to complete login: %v", err)
+	}
+
+	if challengeToken != "" {
+		return &authv1.OIDCCallbackResponse{
+			UserId:            userID,
+			TwoFactorRequired: true,
+			ChallengeToken:    challengeToken,
+		}, nil
+	}
+
+	return &authv1.OIDCCallbackResponse{
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
+		ExpiresIn:    expiresIn,
+		UserId:       userID,
+	}, nil
+}
+
+func userToProto(user *model.User) *authv1.User {
+	return &authv1.User{
+		UserId:        fmt.Sprintf("%d", user.ID),
+		Username:      user.Username,
+		Email:         user.Email,
+		EmailVerified: user.EmailVerified,
+		DisplayName:   user.DisplayName,
+		Bio:           user.Bio,
+		AvatarUrl:     user.AvatarURL,
+		BookGenres:    user.BookGenres,
+		FilmGenres:    user.FilmGenres,
+		MusicGenres:   user.MusicGenres,
+		Version:       user.Version,
+		CreatedAt:     user.CreatedAt.Unix(),
+		Role:          user.Role,
+	}
+}
+
+// userUpdateError maps the errors shared by the profile RPCs
+func (h *AuthHandler) userUpdateError(ctx context.Context, op string, err error) error {
+	switch err {
+	case service.ErrInvalidToken:
+		h.logger.WarnContext(ctx, op+" with invalid access token")
+		return status.Error(codes.Unauthenticated, "invalid or expired access token")
+	case service.ErrUserNotFound:
+		return status.Error(codes.NotFound, "user not found")
+	case service.ErrUsernameTaken, service.ErrEmailTaken:
+		return alreadyExists(err)
+	}
+	h.logger.ErrorContext(ctx, "failed to "+op, "error", err)
+	return status.Errorf(codes.Internal, "failed to %s: %v", op, err)
+}
+
+func (h *AuthHandler) GetUser(ctx context.Context, req *authv1.GetUserRequest) (*authv1.GetUserResponse, error) {
+	user, err := h.service.GetUser(ctx, req.UserId)
+	if err != nil {
+		if err == service.ErrUserNotFound {
+			return nil, status.Error(codes.NotFound, "user not found")
+		}
+		h.logger.ErrorContext(ctx, "failed to get user", "error", err, "user_id", req.UserId)
+		return nil, status.Errorf(codes.Internal, "failed to get user: %v", err)
+	}
+
+	return &authv1.GetUserResponse{User: userToProto(user)}, nil
+}
+
+func (h *AuthHandler) UpdateProfile(ctx context.Context, req *authv1.UpdateProfileRequest) (*authv1.UpdateProfileResponse, error) {
+	update := service.ProfileUpdate{
+		DisplayName: req.DisplayName,
+		Bio:         req.Bio,
+		AvatarURL:   req.AvatarUrl,
+	}
+	if req.BookGenres != nil {
+		update.BookGenres = &req.BookGenres.Genres
+	}
+	if req.FilmGenres != nil {
+		update.FilmGenres = &req.FilmGenres.Genres
+	}
+	if req.MusicGenres != nil {
+		update.MusicGenres = &req.MusicGenres.Genres
+	}
+
+	err := validator.ValidateProfile(validator.ProfileRequest{
+		DisplayName: req.GetDisplayName(),
+		Bio:         req.GetBio(),
+		AvatarURL:   req.GetAvatarUrl(),
+		BookGenres:  req.GetBookGenres().GetGenres(),
+		FilmGenres:  req.GetFilmGenres().GetGenres(),
+		MusicGenres: req.GetMusicGenres().GetGenres(),
+	})
+	if err != nil {
+		return nil, invalidArgument(err)
+	}
+
+	user, err := h.service.UpdateProfile(ctx, req.AccessToken, update)
+	if err != nil {
+		return nil, h.userUpdateError(ctx, "update profile", err)
+	}
+
+	return &authv1.UpdateProfileResponse{User: userToProto(user)}, nil
+}
+
+func (h *AuthHandler) ChangeUsername(ctx context.Context, req *authv1.ChangeUsernameRequest) (*authv1.ChangeUsernameResponse, error) {
+	if err := validator.ValidateUsername(req.Username); err != nil {
+		return nil, invalidArgument(err)
+	}
+
+	user, err := h.service.ChangeUsername(ctx, req.AccessToken, req.Username)
+	if err != nil {
+		return nil, h.userUpdateError(ctx, "change username", err)
+	}
+
+	return &authv1.ChangeUsernameResponse{User: userToProto(user)}, nil
+}
+
+func (h *AuthHandler) ChangeEmail(ctx context.Context, req *authv1.ChangeEmailRequest) (*authv1.ChangeEmailResponse, error) {
+	if err := validator.ValidateEmail(req.NewEmail); err != nil {
+		return nil, invalidArgument(err)
+	}
+
+	user, err := h.service.ChangeEmail(ctx, req.AccessToken, req.NewEmail, req.Password)
+	if err != nil {
+		if err == service.ErrInvalidCredentials {
+			h.logger.WarnContext(ctx, "email change with wrong password")
+			return nil, status.Error(codes.PermissionDenied, "invalid password")
+		}
+		return nil, h.userUpdateError(ctx, "change email", err)
+	}
+
+	return &authv1.ChangeEmailResponse{User: userToProto(user)}, nil
+}
+
+func (h *AuthHandler) DeleteAccount(ctx context.Context, req *authv1.DeleteAccountRequest) (*authv1.DeleteAccountResponse, error) {
+	deletionID, err := h.service.DeleteAccount(ctx, req.AccessToken, req.Password)
+	if err != nil {
+		switch err {
+		case service.ErrInvalidToken:
+			h.logger.WarnContext(ctx, "account deletion with invalid access token")
+			return nil, status.Error(codes.Unauthenticated, "invalid or expired access token")
+		case service.ErrInvalidCredentials:
+			h.logger.WarnContext(ctx, "account deletion with wrong password")
+			return nil, status.Error(codes.PermissionDenied, "invalid password")
+		}
+		h.logger.ErrorContext(ctx, "failed to delete account", "error", err)
+		return nil, status.Errorf(codes.Internal, "failed to delete account: %v", err)
+	}
+
+	return &authv1.DeleteAccountResponse{DeletionId: deletionID}, nil
+}
+
+func (h *AuthHandler) GetAccountDeletion(ctx context.Context, req *authv1.GetAccountDeletionRequest) (*authv1.GetAccountDeletionResponse, error) {
+	deletion, err := h.service.GetAccountDeletion(ctx, req.AccessToken, req.DeletionId)
+	if err != nil {
+		switch err {
+		case service.ErrInvalidToken:
+			return nil, status.Error(codes.Unauthenticated, "invalid or expired access token")
+		case service.ErrDeletionNotFound:
+			return nil, status.Error(codes.NotFound, "account deletion not found")
+		}
+		h.logger.ErrorContext(ctx, "failed to get account deletion", "error", err, "deletion_id", req.DeletionId)
+		return nil, status.Errorf(codes.Internal, "failed to get account deletion: %v", err)
+	}
+
+	resp := &authv1.GetAccountDeletionResponse{
+		DeletionId:      deletion.ID,
+		Status:          deletion.Status,
+		PendingServices: deletion.PendingServices,
+		Attempts:        int32(deletion.Attempts),
+		RequestedAt:     deletion.RequestedAt.Unix(),
+	}
+	if deletion.CompletedAt != nil {
+		resp.CompletedAt = deletion.CompletedAt.Unix()
+	}
+	return resp, nil
+}
+
+func (h *AuthHandler) ListRoles(ctx context.Context, req *authv1.ListRolesRequest) (*authv1.ListRolesResponse, error) {
+	roles, err := h.service.ListRoles(ctx, req.AccessToken)
+	if err != nil {
+		return nil, h.adminError(ctx, "list roles", err)
+	}
+
+	resp := &authv1.ListRolesResponse{Roles: make([]*authv1.Role, len(roles))}
+	for i := range roles {
+		resp.Roles[i] = &authv1.Role{
+			Name:        roles[i].Name,
+			Description: roles[i].Description,
+			Permissions: roles[i].PermissionNames(),
+		}
+	}
+	return resp, nil
+}
+
+func (h *AuthHandler) AssignRole(ctx context.Context, req *authv1.AssignRoleRequest) (*authv1.AssignRoleResponse, error) {
+	user, err := h.service.AssignRole(ctx, req.AccessToken, req.UserId, req.Role)
+	if err != nil {
+		if err == service.ErrUnknownRole {
+			return nil, status.Errorf(codes.InvalidArgument, "unknown role %q", req.Role)
+		}
+		return nil, h.adminError(ctx, "assign role", err)
+	}
+	return &authv1.AssignRoleResponse{User: userToProto(user)}, nil
+}
+
+// adminError maps the errors shared by the admin RPCs
+func (h *AuthHandler) adminError(ctx context.Context, op string, err error) error {
+	switch err {
+	case service.ErrInvalidToken:
+		h.logger.WarnContext(ctx, op+" with invalid access token")
+		return status.Error(codes.Unauthenticated, "invalid or expired access token")
+	case service.ErrPermissionDenied:
+		return status.Error(codes.PermissionDenied, "permission denied")
+	case service.ErrUserNotFound:
+		return status.Error(codes.NotFound, "user not found")
+	}
+	h.logger.ErrorContext(ctx, "failed to "+op, "error", err)
+	return status.Errorf(codes.Internal, "failed to %s: %v", op, err)
+}
+
+func (h *AuthHandler) ListUsers(ctx context.Context, req *authv1.ListUsersRequest) (*authv1.ListUsersResponse, error) {
+	filter := repository.UserFilter{Query: req.Query, Role: req.Role, Suspended: req.Suspended}
+	users, nextPageToken, err := h.service.ListUsers(ctx, req.AccessToken, filter, int(req.PageSize), req.PageToken)
+	if err != nil {
+		if err == service.ErrInvalidPageToken {
+			return nil, status.Error(codes.InvalidArgument, "invalid page token")
+		}
+		return nil, h.adminError(ctx, "list users", err)
+	}
+
+	resp := &authv1.ListUsersResponse{Users: make([]*authv1.User, len(users)), NextPageToken: nextPageToken}
+	for i := range users {
+		resp.Users[i] = userToProto(&users[i])
+	}
+	return resp, nil
+}
+
+func (h *AuthHandler) AdminGetUser(ctx context.Context, req *authv1.AdminGetUserRequest) (*authv1.AdminGetUserResponse, error) {
+	view, err := h.service.AdminGetUser(ctx, req.AccessToken, req.UserId)
+	if err != nil {
+		return nil, h.adminError(ctx, "get user", err)
+	}
+
+	resp := &authv1.AdminGetUserResponse{
+		User:  userToProto(view.User),
+		Audit: make([]*authv1.AuditEntry, len(view.Audit)),
+	}
+	if view.Suspension != nil {
+		resp.Suspension = suspensionToProto(view.Suspension)
+	}
+	for i, entry := range view.Audit {
+		resp.Audit[i] = &authv1.AuditEntry{
+			ActorId:   fmt.Sprintf("%d", entry.ActorID),
+			Action:    entry.Action,
+			Details:   entry.Details,
+			CreatedAt: entry.CreatedAt.Unix(),
+		}
+	}
+	return resp, nil
+}
+
+func (h *AuthHandler) SuspendUser(ctx context.Context, req *authv1.SuspendUserRequest) (*authv1.SuspendUserResponse, error) {
+	var expiresAt *time.Time
+	if req.ExpiresAt != 0 {
+		t := time.Unix(req.ExpiresAt, 0)
+		expiresAt = &t
+	}
+
+	suspension, err := h.service.SuspendUser(ctx, req.AccessToken, req.UserId, req.Reason, expiresAt)
+	if err != nil {
+		if err == service.ErrInvalidSuspension {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+		return nil, h.adminError(ctx, "suspend user", err)
+	}
+	return &authv1.SuspendUserResponse{Suspension: suspensionToProto(suspension)}, nil
+}
+
+func (h *AuthHandler) ReinstateUser(ctx context.Context, req *authv1.ReinstateUserRequest) (*authv1.ReinstateUserResponse, error) {
+	if err := h.service.ReinstateUser(ctx, req.AccessToken, req.UserId, req.Reason); err != nil {
+		if err == service.ErrNotSuspended {
+			return nil, status.Error(codes.FailedPrecondition, "user is not suspended")
+		}
+		return nil, h.adminError(ctx, "reinstate user", err)
+	}
+	return &authv1.ReinstateUserResponse{}, nil
+}
+
+func (h *AuthHandler) ForcePasswordReset(ctx context.Context, req *authv1.ForcePasswordResetRequest) (*authv1.ForcePasswordResetResponse, error) {
+	if err := h.service.ForcePasswordReset(ctx, req.AccessToken, req.UserId); err != nil {
+		return nil, h.adminError(ctx, "force password reset", err)
+	}
+	return &authv1.ForcePasswordResetResponse{}, nil
+}
+
+func suspensionToProto(suspension *model.Suspension) *authv1.Suspension {
+	resp := &authv1.Suspension{
+		Reason:    suspension.Reason,
+		Banned:    suspension.IsBan(),
+		CreatedBy: fmt.Sprintf("%d", suspension.CreatedBy),
+		CreatedAt: suspension.CreatedAt.Unix(),
+	}
+	if suspension.ExpiresAt != nil {
+		resp.ExpiresAt = suspension.ExpiresAt.Unix()
+	}
+	return resp
+}
+
+// invalidArgument maps a validation error to InvalidArgument, with the field violations as BadRequest
+func invalidArgument(err error) error {
+	var validationErr *validator.ValidationError
+	if !errors.As(err, &validationErr) {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	badRequest := &errdetails.BadRequest{}
+	for _, v := range validationErr.Violations {
+		badRequest.FieldViolations = append(badRequest.FieldViolations, &errdetails.BadRequest_FieldViolation{
+			Field:       v.Field,
+			Description: v.Description,
+		})
+	}
+	st, _ := status.New(codes.InvalidArgument, "invalid request").WithDetails(badRequest)
+	return st.Err()
+}
+
+// alreadyExists maps ErrEmailTaken and ErrUsernameTaken to AlreadyExists, with the taken field as a field violation
+func alreadyExists(err error) error {
+	field := "username"
+	if err == service.ErrEmailTaken {
+		field = "email"
+	}
+	st, _ := status.New(codes.AlreadyExists, err.Error()).WithDetails(&errdetails.BadRequest{
+		FieldViolations: []*errdetails.BadRequest_FieldViolation{{Field: field, Description: err.Error()}},
+	})
+	return st.Err()
+}
+
+// suspendedStatus maps an AccountSuspendedError to PermissionDenied, with the reason and expiry as ErrorInfo
+func suspendedStatus(err error) (*status.Status, bool) {
+	var suspendedErr *service.AccountSuspendedError
+	if !errors.As(err, &suspendedErr) {
+		return nil, false
+	}
+
+	info := &errdetails.ErrorInfo{
+		Reason:   "ACCOUNT_SUSPENDED",
+		Domain:   "auth",
+		Metadata: map[string]string{"reason": suspendedErr.Reason},
+	}
+	if suspendedErr.ExpiresAt != nil {
+		info.Metadata["expires_at"] = strconv.FormatInt(suspendedErr.ExpiresAt.Unix(), 10)
+	} else {
+		info.Reason = "ACCOUNT_BANNED"
+	}
+	st, _ := status.New(codes.PermissionDenied, suspendedErr.Error()).WithDetails(info)
+	return st, true
+}
@@ -0,0 +1,118 @@
+-- Schema previously created by GORM AutoMigrate. IF NOT EXISTS lets databases
+-- migrated that way adopt the versioned migrations: their users table is kept
+-- as it was, 0002 and 0004 add the columns it is missing.
+-- There is no down script: on adopted databases it would drop tables holding live data.
+
+CREATE TABLE IF NOT EXISTS users (
+    id BIGSERIAL PRIMARY KEY,
+    username TEXT,
+    email TEXT,
+    password TEXT NOT NULL,
+    role TEXT DEFAULT 'user',
+    email_verified BOOLEAN NOT NULL DEFAULT false,
+    email_verified_at TIMESTAMPTZ,
+    display_name TEXT,
+    bio TEXT,
+    avatar_url TEXT,
+    book_genres TEXT,
+    film_genres TEXT,
+    music_genres TEXT,
+    version BIGINT NOT NULL DEFAULT 1,
+    created_at TIMESTAMPTZ,
+    updated_at TIMESTAMPTZ,
+    deleted_at TIMESTAMPTZ
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_users_username ON users (username);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_users_email ON users (email);
+CREATE INDEX IF NOT EXISTS idx_users_deleted_at ON users (deleted_at);
+
+CREATE TABLE IF NOT EXISTS signing_keys (
+    id TEXT PRIMARY KEY,
+    algorithm TEXT NOT NULL,
+    private_key TEXT NOT NULL,
+    created_at TIMESTAMPTZ
+);
+
+CREATE TABLE IF NOT EXISTS two_factors (
+    user_id BIGINT PRIMARY KEY,
+    encrypted_secret TEXT NOT NULL,
+    enabled BOOLEAN NOT NULL DEFAULT false,
+    enabled_at TIMESTAMPTZ,
+    last_used_step BIGINT,
+    created_at TIMESTAMPTZ,
+    updated_at TIMESTAMPTZ
+);
+
+CREATE TABLE IF NOT EXISTS recovery_codes (
+    id BIGSERIAL PRIMARY KEY,
+    user_id BIGINT NOT NULL,
+    code_hash TEXT NOT NULL,
+    used_at TIMESTAMPTZ
+);
+CREATE INDEX IF NOT EXISTS idx_recovery_codes_user_id ON recovery_codes (user_id);
+
+CREATE TABLE IF NOT EXISTS identities (
+    id BIGSERIAL PRIMARY KEY,
+    user_id BIGINT NOT NULL,
+    provider TEXT NOT NULL,
+    subject TEXT NOT NULL,
+    email TEXT,
+    created_at TIMESTAMPTZ
+);
+CREATE INDEX IF NOT EXISTS idx_identities_user_id ON identities (user_id);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_identity_provider_subject ON identities (provider, subject);
+
+CREATE TABLE IF NOT EXISTS account_deletions (
+    id TEXT PRIMARY KEY,
+    user_id BIGINT NOT NULL,
+    status TEXT NOT NULL,
+    pending_services TEXT,
+    attempts BIGINT NOT NULL DEFAULT 0,
+    requested_at TIMESTAMPTZ,
+    last_attempt_at TIMESTAMPTZ,
+    completed_at TIMESTAMPTZ
+);
+CREATE INDEX IF NOT EXISTS idx_account_deletions_user_id ON account_deletions (user_id);
+CREATE INDEX IF NOT EXISTS idx_account_deletions_status ON account_deletions (status);
+CREATE INDEX IF NOT EXISTS idx_account_deletions_last_attempt_at ON account_deletions (last_attempt_at);
+
+CREATE TABLE IF NOT EXISTS permissions (
+    name TEXT PRIMARY KEY,
+    description TEXT
+);
+
+CREATE TABLE IF NOT EXISTS roles (
+    name TEXT PRIMARY KEY,
+    description TEXT
+);
+
+CREATE TABLE IF NOT EXISTS role_permissions (
+    role_name TEXT NOT NULL,
+    permission_name TEXT NOT NULL,
+    PRIMARY KEY (role_name, permission_name),
+    CONSTRAINT fk_role_permissions_role FOREIGN KEY (role_name) REFERENCES roles (name),
+    CONSTRAINT fk_role_permissions_permission FOREIGN KEY (permission_name) REFERENCES permissions (name)
+);
+
+CREATE TABLE IF NOT EXISTS suspensions (
+    id BIGSERIAL PRIMARY KEY,
+    user_id BIGINT NOT NULL,
+    reason TEXT NOT NULL,
+    expires_at TIMESTAMPTZ,
+    created_by BIGINT NOT NULL,
+    created_at TIMESTAMPTZ,
+    lifted_at TIMESTAMPTZ,
+    lifted_by BIGINT
+);
+CREATE INDEX IF NOT EXISTS idx_suspensions_user_id ON suspensions (user_id);
+
+CREATE TABLE IF NOT EXISTS audit_entries (
+    id BIGSERIAL PRIMARY KEY,
+    actor_id BIGINT NOT NULL,
+    action TEXT NOT NULL,
+    target_user_id BIGINT NOT NULL,
+    details TEXT,
+    created_at TIMESTAMPTZ
+);
+CREATE INDEX IF NOT EXISTS idx_audit_entries_actor_id ON audit_entries (actor_id);
+CREATE INDEX IF NOT EXISTS idx_audit_entries_target_user_id ON audit_entries (target_user_id);
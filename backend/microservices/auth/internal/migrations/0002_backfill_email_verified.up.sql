@@ -0,0 +1,11 @@
+-- Accounts created before email verification existed never received a link,
+-- they count as verified so the unverified account purge leaves them alone.
+
+ALTER TABLE users ADD COLUMN IF NOT EXISTS email_verified BOOLEAN NOT NULL DEFAULT false;
+ALTER TABLE users ADD COLUMN IF NOT EXISTS email_verified_at TIMESTAMPTZ;
+
+UPDATE users
+SET email_verified = true,
+    email_verified_at = COALESCE(created_at, now())
+WHERE email_verified = false
+  AND email_verified_at IS NULL;
@@ -0,0 +1,10 @@
+-- Databases created by GORM AutoMigrate kept their users table through 0001,
+-- which only had the original columns. Add the ones introduced since then.
+
+ALTER TABLE users ADD COLUMN IF NOT EXISTS display_name TEXT;
+ALTER TABLE users ADD COLUMN IF NOT EXISTS bio TEXT;
+ALTER TABLE users ADD COLUMN IF NOT EXISTS avatar_url TEXT;
+ALTER TABLE users ADD COLUMN IF NOT EXISTS book_genres TEXT;
+ALTER TABLE users ADD COLUMN IF NOT EXISTS film_genres TEXT;
+ALTER TABLE users ADD COLUMN IF NOT EXISTS music_genres TEXT;
+ALTER TABLE users ADD COLUMN IF NOT EXISTS version BIGINT NOT NULL DEFAULT 1;
@@ -0,0 +1,18 @@
+// Package migrations embeds the SQL migrations of the auth database,
+// applied by postgres.Migrator at startup and by cmd/migrate.
+package migrations
+
+import (
+	"embed"
+
+	"github.com/username/progetto/shared/pkg/database/postgres"
+	"github.com/username/progetto/shared/pkg/outbox"
+)
+
+//go:embed *.sql
+var FS embed.FS
+
+// Shared are the migrations of the tables defined by shared packages, versioned with the files of FS
+var Shared = []postgres.Migration{
+	{Version: 3, Name: "outbox", Up: outbox.Schema},
+}
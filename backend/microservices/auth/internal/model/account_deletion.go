@@ -0,0 +1,22 @@
+package model
+
+import "time"
+
+const (
+	DeletionPending   = "pending"
+	DeletionCompleted = "completed"
+)
+
+// AccountDeletion tracks the erasure of a user across the services holding a replica.
+// The user row is hard-deleted once every service confirmed, this record only keeps the user ID.
+type AccountDeletion struct {
+	ID              string   `gorm:"primaryKey"`
+	UserID          uint     `gorm:"index;not null"`
+	Status          string   `gorm:"not null;index"`
+	PendingServices []string `gorm:"serializer:json"`
+	// Attempts counts how many times user_deletion_requested was published
+	Attempts      int `gorm:"not null;default:0"`
+	RequestedAt   time.Time
+	LastAttemptAt time.Time `gorm:"index"`
+	CompletedAt   *time.Time
+}
@@ -0,0 +1,14 @@
+package model
+
+import "time"
+
+// Identity links a user to an account at an external OpenID Connect provider
+type Identity struct {
+	ID       uint   `gorm:"primaryKey"`
+	UserID   uint   `gorm:"index;not null"`
+	Provider string `gorm:"not null;uniqueIndex:idx_identity_provider_subject"`
+	// Subject is the stable "sub" claim of the provider, emails can change
+	Subject   string `gorm:"not null;uniqueIndex:idx_identity_provider_subject"`
+	Email     string
+	CreatedAt time.Time
+}
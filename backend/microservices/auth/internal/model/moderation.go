@@ -0,0 +1,38 @@
+package model
+
+import "time"
+
+// Suspension blocks the login of a user until it expires or is lifted, a ban never expires
+type Suspension struct {
+	ID        uint   `gorm:"primaryKey"`
+	UserID    uint   `gorm:"index;not null"`
+	Reason    string `gorm:"not null"`
+	ExpiresAt *time.Time
+	CreatedBy uint `gorm:"not null"`
+	CreatedAt time.Time
+	LiftedAt  *time.Time
+	LiftedBy  *uint
+}
+
+func (s *Suspension) IsBan() bool {
+	return s.ExpiresAt == nil
+}
+
+// Admin actions recorded in the audit log
+const (
+	AuditRoleAssigned        = "role_assigned"
+	AuditUserSuspended       = "user_suspended"
+	AuditUserBanned          = "user_banned"
+	AuditUserReinstated      = "user_reinstated"
+	AuditPasswordResetForced = "password_reset_forced"
+)
+
+// AuditEntry records an action of an admin on a user
+type AuditEntry struct {
+	ID           uint              `gorm:"primaryKey"`
+	ActorID      uint              `gorm:"index;not null"`
+	Action       string            `gorm:"not null"`
+	TargetUserID uint              `gorm:"index;not null"`
+	Details      map[string]string `gorm:"serializer:json"`
+	CreatedAt    time.Time
+}
@@ -0,0 +1,22 @@
+package model
+
+// Role groups permissions, each user has one role (User.Role)
+type Role struct {
+	Name        string `gorm:"primaryKey"`
+	Description string
+	Permissions []Permission `gorm:"many2many:role_permissions;"`
+}
+
+type Permission struct {
+	Name        string `gorm:"primaryKey"`
+	Description string
+}
+
+// PermissionNames returns the names of the permissions of the role
+func (r *Role) PermissionNames() []string {
+	names := make([]string, len(r.Permissions))
+	for i, p := range r.Permissions {
+		names[i] = p.Name
+	}
+	return names
+}
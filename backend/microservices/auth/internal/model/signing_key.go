@@ -0,0 +1,12 @@
+package model
+
+import "time"
+
+// SigningKey is a private key used to sign access tokens.
+// Its ID is published as the JWT "kid" header.
+type SigningKey struct {
+	ID         string `gorm:"primaryKey"`
+	Algorithm  string `gorm:"not null"`
+	PrivateKey string `gorm:"not null"` // PKCS#8 PEM, encrypted at rest
+	CreatedAt  time.Time
+}
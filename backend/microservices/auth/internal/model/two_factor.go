@@ -0,0 +1,24 @@
+package model
+
+import "time"
+
+// TwoFactor holds the TOTP enrollment of a user.
+// The secret is encrypted, it is only decrypted to check codes.
+type TwoFactor struct {
+	UserID          uint   `gorm:"primaryKey"`
+	EncryptedSecret string `gorm:"not null"`
+	Enabled         bool   `gorm:"not null;default:false"`
+	EnabledAt       *time.Time
+	// LastUsedStep prevents the same code from being used twice
+	LastUsedStep int64
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+}
+
+// RecoveryCode is a hashed single-use fallback for a lost authenticator
+type RecoveryCode struct {
+	ID       uint   `gorm:"primaryKey"`
+	UserID   uint   `gorm:"index;not null"`
+	CodeHash string `gorm:"not null"`
+	UsedAt   *time.Time
+}
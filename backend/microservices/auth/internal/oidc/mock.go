@@ -0,0 +1,203 @@
+package oidc
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/username/progetto/shared/pkg/jwtutil"
+)
+
+// MockUser is the account every login on the mock provider resolves to
+type MockUser struct {
+	Subject           string
+	Email             string
+	EmailVerified     bool
+	Name              string
+	PreferredUsername string
+}
+
+// MockConfig configures a MockProvider
+type MockConfig struct {
+	Issuer       string
+	ClientID     string
+	ClientSecret string
+	User         MockUser
+}
+
+type mockAuthorization struct {
+	redirectURI   string
+	nonce         string
+	codeChallenge string
+	expiresAt     time.Time
+}
+
+// MockProvider is an OpenID Connect provider for local development and tests.
+// It approves every authorization request without a login page and issues RS256 ID tokens.
+type MockProvider struct {
+	cfg MockConfig
+	key *rsa.PrivateKey
+	kid string
+
+	mu    sync.Mutex
+	codes map[string]mockAuthorization
+}
+
+func NewMockProvider(cfg MockConfig) (*MockProvider, error) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		return nil, err
+	}
+	return &MockProvider{
+		cfg:   cfg,
+		key:   key,
+		kid:   "mock-" + randomString(6),
+		codes: make(map[string]mockAuthorization),
+	}, nil
+}
+
+func (m *MockProvider) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /.well-known/openid-configuration", m.discovery)
+	mux.HandleFunc("GET /authorize", m.authorize)
+	mux.HandleFunc("POST /token", m.token)
+	mux.HandleFunc("GET /jwks", m.jwks)
+	return mux
+}
+
+func (m *MockProvider) discovery(w http.ResponseWriter, r *http.Request) {
+	issuer := strings.TrimSuffix(m.cfg.Issuer, "/")
+	writeJSON(w, http.StatusOK, Discovery{
+		Issuer:                m.cfg.Issuer,
+		AuthorizationEndpoint: issuer + "/authorize",
+		TokenEndpoint:         issuer + "/token",
+		JWKSURI:               issuer + "/jwks",
+	})
+}
+
+func (m *MockProvider) authorize(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	if query.Get("client_id") != m.cfg.ClientID {
+		http.Error(w, "unknown client_id", http.StatusBadRequest)
+		return
+	}
+	if query.Get("response_type") != "code" || query.Get("code_challenge_method") != "S256" || query.Get("code_challenge") == "" {
+		http.Error(w, "only the code flow with S256 PKCE is supported", http.StatusBadRequest)
+		return
+	}
+
+	redirectURI, err := url.Parse(query.Get("redirect_uri"))
+	if err != nil || redirectURI.Scheme == "" {
+		http.Error(w, "invalid redirect_uri", http.StatusBadRequest)
+		return
+	}
+
+	code := randomString(24)
+	m.mu.Lock()
+	m.codes[code] = mockAuthorization{
+		redirectURI:   query.Get("redirect_uri"),
+		nonce:         query.Get("nonce"),
+		codeChallenge: query.Get("code_challenge"),
+		expiresAt:     time.Now().Add(time.Minute),
+	}
+	m.mu.Unlock()
+
+	params := redirectURI.Query()
+	params.Set("code", code)
+	params.Set("state", query.Get("state"))
+	redirectURI.RawQuery = params.Encode()
+	http.Redirect(w, r, redirectURI.String(), http.StatusFound)
+}
+
+func (m *MockProvider) token(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		writeTokenError(w, "invalid_request")
+		return
+	}
+	if r.PostForm.Get("grant_type") != "authorization_code" {
+		writeTokenError(w, "unsupported_grant_type")
+		return
+	}
+	if r.PostForm.Get("client_id") != m.cfg.ClientID || r.PostForm.Get("client_secret") != m.cfg.ClientSecret {
+		writeTokenError(w, "invalid_client")
+		return
+	}
+
+	// Codes are single use
+	m.mu.Lock()
+	authorization, ok := m.codes[r.PostForm.Get("code")]
+	delete(m.codes, r.PostForm.Get("code"))
+	m.mu.Unlock()
+
+	if !ok || time.Now().After(authorization.expiresAt) ||
+		authorization.redirectURI != r.PostForm.Get("redirect_uri") ||
+		authorization.codeChallenge != CodeChallenge(r.PostForm.Get("code_verifier")) {
+		writeTokenError(w, "invalid_grant")
+		return
+	}
+
+	now := time.Now()
+	claims := jwt.MapClaims{
+		"iss":            m.cfg.Issuer,
+		"sub":            m.cfg.User.Subject,
+		"aud":            m.cfg.ClientID,
+		"exp":            now.Add(5 * time.Minute).Unix(),
+		"iat":            now.Unix(),
+		"nonce":          authorization.nonce,
+		"email":          m.cfg.User.Email,
+		"email_verified": m.cfg.User.EmailVerified,
+	}
+	if m.cfg.User.Name != "" {
+		claims["name"] = m.cfg.User.Name
+	}
+	if m.cfg.User.PreferredUsername != "" {
+		claims["preferred_username"] = m.cfg.User.PreferredUsername
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = m.kid
+	idToken, err := token.SignedString(m.key)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"access_token": randomString(24),
+		"token_type":   "Bearer",
+		"expires_in":   300,
+		"id_token":     idToken,
+	})
+}
+
+func (m *MockProvider) jwks(w http.ResponseWriter, r *http.Request) {
+	jwk, err := jwtutil.NewJWK(m.kid, &m.key.PublicKey)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusOK, jwtutil.JWKS{Keys: []jwtutil.JWK{jwk}})
+}
+
+func writeTokenError(w http.ResponseWriter, code string) {
+	writeJSON(w, http.StatusBadRequest, map[string]string{"error": code})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+func randomString(n int) string {
+	b := make([]byte, n)
+	rand.Read(b)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
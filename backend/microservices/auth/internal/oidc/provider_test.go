@@ -0,0 +1,91 @@
+package oidc
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+)
+
+func TestProviderAgainstMock(t *testing.T) {
+	mux := http.NewServeMux()
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	mock, err := NewMockProvider(MockConfig{
+		Issuer:       srv.URL,
+		ClientID:     "client",
+		ClientSecret: "secret",
+		User:         MockUser{Subject: "42", Email: "jane@example.com", EmailVerified: true},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	mux.Handle("/", mock.Handler())
+
+	provider := NewProvider(Config{
+		Name:         "mock",
+		Issuer:       srv.URL,
+		ClientID:     "client",
+		ClientSecret: "secret",
+		RedirectURL:  "http://app.test/callback",
+	})
+	ctx := context.Background()
+
+	verifier, err := GenerateCodeVerifier()
+	if err != nil {
+		t.Fatal(err)
+	}
+	authURL, err := provider.AuthCodeURL(ctx, "state-1", "nonce-1", verifier)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Follow the authorization request up to the redirect to the app
+	client := &http.Client{CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse }}
+	resp, err := client.Get(authURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	location, err := url.Parse(resp.Header.Get("Location"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := location.Query().Get("state"); got != "state-1" {
+		t.Fatalf("state = %q, want state-1", got)
+	}
+	code := location.Query().Get("code")
+
+	if _, err := provider.Exchange(ctx, code, "wrong-verifier"); err == nil {
+		t.Fatal("exchange with a wrong code verifier succeeded")
+	}
+
+	// The failed exchange consumed the code
+	resp, err = client.Get(authURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	location, _ = url.Parse(resp.Header.Get("Location"))
+	code = location.Query().Get("code")
+
+	idToken, err := provider.Exchange(ctx, code, verifier)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := provider.VerifyIDToken(ctx, idToken, "other-nonce"); !errors.Is(err, ErrInvalidIDToken) {
+		t.Fatalf("nonce mismatch: err = %v, want ErrInvalidIDToken", err)
+	}
+
+	claims, err := provider.VerifyIDToken(ctx, idToken, "nonce-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if claims.Subject != "42" || claims.Email != "jane@example.com" || !claims.EmailVerified {
+		t.Fatalf("unexpected claims %+v", claims)
+	}
+}
@@ -0,0 +1,74 @@
+// Package password hashes passwords with a configurable algorithm.
+// Hashes carry their algorithm and parameters, so stored hashes keep working
+// when the configuration changes and can be upgraded at the next login.
+package password
+
+import (
+	"errors"
+	"strings"
+)
+
+var (
+	ErrUnknownAlgorithm = errors.New("unknown password hash algorithm")
+	ErrMalformedHash    = errors.New("malformed password hash")
+)
+
+// Algorithm is a password hashing scheme
+type Algorithm interface {
+	// Name is the algorithm name of the configuration, e.g. "argon2id"
+	Name() string
+	// Recognizes tells whether the encoded hash was produced by this algorithm
+	Recognizes(encoded string) bool
+	Hash(password string) (string, error)
+	// Verify reports whether the password matches the encoded hash
+	Verify(password, encoded string) (bool, error)
+	// Outdated tells whether the encoded hash uses other parameters than the configured ones
+	Outdated(encoded string) bool
+}
+
+// Hasher hashes with the preferred algorithm and verifies with any of the known ones
+type Hasher struct {
+	preferred  Algorithm
+	algorithms []Algorithm
+}
+
+// NewHasher returns a Hasher hashing with the named algorithm among the given ones
+func NewHasher(preferred string, algorithms ...Algorithm) (*Hasher, error) {
+	for _, algorithm := range algorithms {
+		if algorithm.Name() == preferred {
+			return &Hasher{preferred: algorithm, algorithms: algorithms}, nil
+		}
+	}
+	return nil, ErrUnknownAlgorithm
+}
+
+func (h *Hasher) Hash(password string) (string, error) {
+	return h.preferred.Hash(password)
+}
+
+// Verify reports whether the password matches and, if it does, whether the hash
+// should be replaced because it uses another algorithm or outdated parameters
+func (h *Hasher) Verify(password, encoded string) (ok bool, needsRehash bool, err error) {
+	algorithm := h.algorithmOf(encoded)
+	if algorithm == nil {
+		return false, false, ErrUnknownAlgorithm
+	}
+
+	ok, err = algorithm.Verify(password, encoded)
+	if err != nil || !ok {
+		return false, false, err
+	}
+	return true, algorithm != h.preferred || algorithm.Outdated(encoded), nil
+}
+
+func (h *Hasher) algorithmOf(encoded string) Algorithm {
+	if !strings.HasPrefix(encoded, "$") {
+		return nil
+	}
+	for _, algorithm := range h.algorithms {
+		if algorithm.Recognizes(encoded) {
+			return algorithm
+		}
+	}
+	return nil
+}
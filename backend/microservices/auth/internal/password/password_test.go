@@ -0,0 +1,170 @@
+package password
+
+import (
+	"errors"
+	"testing"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// Cheap parameters, the tests check the encoding and not the cost
+var testArgon2idParams = Argon2idParams{Memory: 1024, Iterations: 1, Parallelism: 1, SaltLength: 16, KeyLength: 32}
+
+func newTestHasher(t *testing.T, preferred string, argon2Params Argon2idParams, bcryptCost int) *Hasher {
+	t.Helper()
+	h, err := NewHasher(preferred, NewArgon2id(argon2Params), NewBcrypt(bcryptCost))
+	if err != nil {
+		t.Fatalf("NewHasher() error = %v", err)
+	}
+	return h
+}
+
+func TestHasherVerify(t *testing.T) {
+	argon2Hasher := newTestHasher(t, "argon2id", testArgon2idParams, bcrypt.MinCost)
+	bcryptHasher := newTestHasher(t, "bcrypt", testArgon2idParams, bcrypt.MinCost)
+
+	stronger := testArgon2idParams
+	stronger.Iterations = 2
+	strongerHasher := newTestHasher(t, "argon2id", stronger, bcrypt.MinCost)
+
+	argon2Hash, err := argon2Hasher.Hash("correct horse")
+	if err != nil {
+		t.Fatalf("Hash() error = %v", err)
+	}
+	bcryptHash, err := bcryptHasher.Hash("correct horse")
+	if err != nil {
+		t.Fatalf("Hash() error = %v", err)
+	}
+
+	tests := []struct {
+		name            string
+		hasher          *Hasher
+		password        string
+		encoded         string
+		wantOK          bool
+		wantNeedsRehash bool
+	}{
+		{name: "argon2id match", hasher: argon2Hasher, password: "correct horse", encoded: argon2Hash, wantOK: true},
+		{name: "argon2id mismatch", hasher: argon2Hasher, password: "wrong horse", encoded: argon2Hash},
+		{name: "bcrypt match", hasher: bcryptHasher, password: "correct horse", encoded: bcryptHash, wantOK: true},
+		{name: "bcrypt mismatch", hasher: bcryptHasher, password: "wrong horse", encoded: bcryptHash},
+		{name: "bcrypt upgraded to argon2id", hasher: argon2Hasher, password: "correct horse", encoded: bcryptHash, wantOK: true, wantNeedsRehash: true},
+		{name: "outdated argon2id parameters", hasher: strongerHasher, password: "correct horse", encoded: argon2Hash, wantOK: true, wantNeedsRehash: true},
+		{name: "no rehash on mismatch", hasher: argon2Hasher, password: "wrong horse", encoded: bcryptHash},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ok, needsRehash, err := tt.hasher.Verify(tt.password, tt.encoded)
+			if err != nil {
+				t.Fatalf("Verify() error = %v", err)
+			}
+			if ok != tt.wantOK || needsRehash != tt.wantNeedsRehash {
+				t.Errorf("Verify() = (%v, %v), want (%v, %v)", ok, needsRehash, tt.wantOK, tt.wantNeedsRehash)
+			}
+		})
+	}
+}
+
+func TestHasherVerifyInvalidHash(t *testing.T) {
+	h := newTestHasher(t, "argon2id", testArgon2idParams, bcrypt.MinCost)
+
+	tests := []struct {
+		name    string
+		encoded string
+		wantErr error
+	}{
+		{name: "empty", encoded: "", wantErr: ErrUnknownAlgorithm},
+		{name: "unknown algorithm", encoded: "$scrypt$ln=15,r=8,p=1$c2FsdA$a2V5", wantErr: ErrUnknownAlgorithm},
+		{name: "missing key", encoded: "$argon2id$v=19$m=1024,t=1,p=1$c2FsdHNhbHRzYWx0c2FsdA", wantErr: ErrMalformedHash},
+		{name: "bad parameters", encoded: "$argon2id$v=19$m=x,t=1,p=1$c2FsdHNhbHRzYWx0c2FsdA$a2V5", wantErr: ErrMalformedHash},
+		{name: "zero iterations", encoded: "$argon2id$v=19$m=1024,t=0,p=1$c2FsdHNhbHRzYWx0c2FsdA$a2V5", wantErr: ErrMalformedHash},
+		{name: "zero parallelism", encoded: "$argon2id$v=19$m=1024,t=1,p=0$c2FsdHNhbHRzYWx0c2FsdA$a2V5", wantErr: ErrMalformedHash},
+		{name: "memory below parallelism", encoded: "$argon2id$v=19$m=8,t=1,p=4$c2FsdHNhbHRzYWx0c2FsdA$a2V5", wantErr: ErrMalformedHash},
+		{name: "other version", encoded: "$argon2id$v=16$m=1024,t=1,p=1$c2FsdHNhbHRzYWx0c2FsdA$a2V5", wantErr: ErrMalformedHash},
+		{name: "truncated bcrypt", encoded: "$2a$10$short", wantErr: ErrMalformedHash},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, _, err := h.Verify("password", tt.encoded); !errors.Is(err, tt.wantErr) {
+				t.Errorf("Verify() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestArgon2idParamsValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		modify  func(p *Argon2idParams)
+		wantErr bool
+	}{
+		{name: "defaults", modify: func(p *Argon2idParams) {}},
+		{name: "zero iterations", modify: func(p *Argon2idParams) { p.Iterations = 0 }, wantErr: true},
+		{name: "zero parallelism", modify: func(p *Argon2idParams) { p.Parallelism = 0 }, wantErr: true},
+		{name: "memory below 8 KiB per lane", modify: func(p *Argon2idParams) { p.Memory, p.Parallelism = 31, 4 }, wantErr: true},
+		{name: "minimum memory", modify: func(p *Argon2idParams) { p.Memory, p.Parallelism = 32, 4 }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := DefaultArgon2idParams()
+			tt.modify(&p)
+			if err := p.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestNewHasherUnknownAlgorithm(t *testing.T) {
+	if _, err := NewHasher("md5", NewArgon2id(testArgon2idParams), NewBcrypt(bcrypt.MinCost)); !errors.Is(err, ErrUnknownAlgorithm) {
+		t.Errorf("NewHasher() error = %v, want %v", err, ErrUnknownAlgorithm)
+	}
+}
+
+// The benchmarks use the production defaults, to size them against the login latency budget
+func BenchmarkArgon2idHash(b *testing.B) {
+	a := NewArgon2id(DefaultArgon2idParams())
+	for b.Loop() {
+		if _, err := a.Hash("correct horse battery staple"); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkArgon2idVerify(b *testing.B) {
+	a := NewArgon2id(DefaultArgon2idParams())
+	encoded, err := a.Hash("correct horse battery staple")
+	if err != nil {
+		b.Fatal(err)
+	}
+	for b.Loop() {
+		if _, err := a.Verify("correct horse battery staple", encoded); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkBcryptHash(b *testing.B) {
+	h := NewBcrypt(bcrypt.DefaultCost)
+	for b.Loop() {
+		if _, err := h.Hash("correct horse battery staple"); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkBcryptVerify(b *testing.B) {
+	h := NewBcrypt(bcrypt.DefaultCost)
+	encoded, err := h.Hash("correct horse battery staple")
+	if err != nil {
+		b.Fatal(err)
+	}
+	for b.Loop() {
+		if _, err := h.Verify("correct horse battery staple", encoded); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,118 @@
+package repository
+
+import (
+	"context"
+	"slices"
+	"time"
+
+	"github.com/username/progetto/auth/internal/model"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type AccountDeletionRepository interface {
+	// Start creates the deletion and soft-deletes the user in one transaction
+	Start(ctx context.Context, deletion *model.AccountDeletion) error
+	FindByID(ctx context.Context, id string) (*model.AccountDeletion, error)
+	// Confirm removes the service from the pending ones and returns the updated deletion
+	Confirm(ctx context.Context, id, service string) (*model.AccountDeletion, error)
+	// Complete hard-deletes the user and everything auth-service stores about it, then marks the deletion completed
+	Complete(ctx context.Context, id string) error
+	// FindStale returns up to limit pending deletions last attempted before the given time
+	FindStale(ctx context.Context, before time.Time, limit int) ([]model.AccountDeletion, error)
+	RecordAttempt(ctx context.Context, id string) error
+}
+
+type accountDeletionRepository struct {
+	db *gorm.DB
+}
+
+func NewAccountDeletionRepository(db *gorm.DB) AccountDeletionRepository {
+	return &accountDeletionRepository{db: db}
+}
+
+func (r *accountDeletionRepository) Start(ctx context.Context, deletion *model.AccountDeletion) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(deletion).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&model.User{}, deletion.UserID).Error
+	})
+}
+
+func (r *accountDeletionRepository) FindByID(ctx context.Context, id string) (*model.AccountDeletion, error) {
+	var deletion model.AccountDeletion
+	if err := r.db.WithContext(ctx).First(&deletion, "id = ?", id).Error; err != nil {
+		return nil, err
+	}
+	return &deletion, nil
+}
+
+func (r *accountDeletionRepository) Confirm(ctx context.Context, id, service string) (*model.AccountDeletion, error) {
+	var deletion model.AccountDeletion
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// Confirmations of different services can arrive at the same time
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&deletion, "id = ?", id).Error; err != nil {
+			return err
+		}
+
+		pending := slices.DeleteFunc(slices.Clone(deletion.PendingServices), func(s string) bool { return s == service })
+		if len(pending) == len(deletion.PendingServices) {
+			// Already confirmed, e.g. a redelivered event
+			return nil
+		}
+		deletion.PendingServices = pending
+		return tx.Model(&deletion).Select("pending_services").Updates(&deletion).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &deletion, nil
+}
+
+func (r *accountDeletionRepository) Complete(ctx context.Context, id string) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var deletion model.AccountDeletion
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&deletion, "id = ?", id).Error; err != nil {
+			return err
+		}
+		if deletion.Status == model.DeletionCompleted {
+			return nil
+		}
+
+		for _, table := range []interface{}{&model.RecoveryCode{}, &model.TwoFactor{}, &model.Identity{}, &model.Suspension{}} {
+			if err := tx.Where("user_id = ?", deletion.UserID).Delete(table).Error; err != nil {
+				return err
+			}
+		}
+		if err := tx.Unscoped().Delete(&model.User{}, deletion.UserID).Error; err != nil {
+			return err
+		}
+
+		now := time.Now()
+		return tx.Model(&deletion).Updates(map[string]interface{}{
+			"status":       model.DeletionCompleted,
+			"completed_at": now,
+		}).Error
+	})
+}
+
+func (r *accountDeletionRepository) FindStale(ctx context.Context, before time.Time, limit int) ([]model.AccountDeletion, error) {
+	var deletions []model.AccountDeletion
+	err := r.db.WithContext(ctx).
+		Where("status = ? AND last_attempt_at < ?", model.DeletionPending, before).
+		Order("last_attempt_at").
+		Limit(limit).
+		Find(&deletions).Error
+	if err != nil {
+		return nil, err
+	}
+	return deletions, nil
+}
+
+func (r *accountDeletionRepository) RecordAttempt(ctx context.Context, id string) error {
+	return r.db.WithContext(ctx).Model(&model.AccountDeletion{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"attempts":        gorm.Expr("attempts + 1"),
+		"last_attempt_at": time.Now(),
+	}).Error
+}
@@ -0,0 +1,60 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+type EmailVerificationRepository interface {
+	SaveVerificationToken(ctx context.Context, token, userID string, duration time.Duration) error
+	// ConsumeVerificationToken returns the user ID of the token and deletes it, so it can be used once
+	ConsumeVerificationToken(ctx context.Context, token string) (string, error)
+	// SaveEmailChangeToken stores the address the user is moving to until the token confirms it
+	SaveEmailChangeToken(ctx context.Context, token, userID, email string, duration time.Duration) error
+	// ConsumeEmailChangeToken returns the user ID and the new email of the token and deletes it
+	ConsumeEmailChangeToken(ctx context.Context, token string) (string, string, error)
+}
+
+type emailVerificationRepository struct {
+	client *redis.Client
+}
+
+func NewEmailVerificationRepository(client *redis.Client) EmailVerificationRepository {
+	return &emailVerificationRepository{client: client}
+}
+
+func verificationTokenKey(tokenHash string) string {
+	return "email_verification:" + tokenHash
+}
+
+func emailChangeTokenKey(tokenHash string) string {
+	return "email_change:" + tokenHash
+}
+
+func (r *emailVerificationRepository) SaveVerificationToken(ctx context.Context, token, userID string, duration time.Duration) error {
+	return r.client.Set(ctx, verificationTokenKey(hashToken(token)), userID, duration).Err()
+}
+
+func (r *emailVerificationRepository) ConsumeVerificationToken(ctx context.Context, token string) (string, error) {
+	return r.client.GetDel(ctx, verificationTokenKey(hashToken(token))).Result()
+}
+
+func (r *emailVerificationRepository) SaveEmailChangeToken(ctx context.Context, token, userID, email string, duration time.Duration) error {
+	return r.client.Set(ctx, emailChangeTokenKey(hashToken(token)), userID+":"+email, duration).Err()
+}
+
+func (r *emailVerificationRepository) ConsumeEmailChangeToken(ctx context.Context, token string) (string, string, error) {
+	value, err := r.client.GetDel(ctx, emailChangeTokenKey(hashToken(token))).Result()
+	if err != nil {
+		return "", "", err
+	}
+	userID, email, ok := strings.Cut(value, ":")
+	if !ok {
+		return "", "", errors.New("malformed email change token")
+	}
+	return userID, email, nil
+}
@@ -0,0 +1,33 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/username/progetto/auth/internal/model"
+	"gorm.io/gorm"
+)
+
+type IdentityRepository interface {
+	FindByProviderSubject(ctx context.Context, provider, subject string) (*model.Identity, error)
+	Create(ctx context.Context, identity *model.Identity) error
+}
+
+type identityRepository struct {
+	db *gorm.DB
+}
+
+func NewIdentityRepository(db *gorm.DB) IdentityRepository {
+	return &identityRepository{db: db}
+}
+
+func (r *identityRepository) FindByProviderSubject(ctx context.Context, provider, subject string) (*model.Identity, error) {
+	var identity model.Identity
+	if err := r.db.WithContext(ctx).Where("provider = ? AND subject = ?", provider, subject).First(&identity).Error; err != nil {
+		return nil, err
+	}
+	return &identity, nil
+}
+
+func (r *identityRepository) Create(ctx context.Context, identity *model.Identity) error {
+	return r.db.WithContext(ctx).Create(identity).Error
+}
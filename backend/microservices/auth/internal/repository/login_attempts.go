@@ -0,0 +1,98 @@
+package repository
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// LoginAttemptRepository tracks failed logins per subject (an email or a client IP)
+type LoginAttemptRepository interface {
+	// RecordFailure adds a failure and returns how many happened within the window
+	RecordFailure(ctx context.Context, subject string, window time.Duration) (int64, error)
+	ClearFailures(ctx context.Context, subject string) error
+	// Lock locks the subject and returns how many times it was locked within levelTTL, this one included
+	Lock(ctx context.Context, subject string, backoff func(level int64) time.Duration, levelTTL time.Duration) (time.Duration, int64, error)
+	// LockedFor returns the remaining lock time, zero if the subject is not locked
+	LockedFor(ctx context.Context, subject string) (time.Duration, error)
+}
+
+type loginAttemptRepository struct {
+	client *redis.Client
+}
+
+func NewLoginAttemptRepository(client *redis.Client) LoginAttemptRepository {
+	return &loginAttemptRepository{client: client}
+}
+
+func loginFailuresKey(subject string) string {
+	return "login_failures:" + subject
+}
+
+func loginLockKey(subject string) string {
+	return "login_lock:" + subject
+}
+
+func loginLockLevelKey(subject string) string {
+	return "login_lock_level:" + subject
+}
+
+// recordFailureScript keeps failures in a sorted set scored by time, so the window slides.
+// KEYS[1]: failures key
+// ARGV[1]: now (ms), ARGV[2]: window (ms), ARGV[3]: unique member
+var recordFailureScript = redis.NewScript(`
+redis.call("ZREMRANGEBYSCORE", KEYS[1], "-inf", ARGV[1] - ARGV[2])
+redis.call("ZADD", KEYS[1], ARGV[1], ARGV[3])
+redis.call("PEXPIRE", KEYS[1], ARGV[2])
+return redis.call("ZCARD", KEYS[1])
+`)
+
+func (r *loginAttemptRepository) RecordFailure(ctx context.Context, subject string, window time.Duration) (int64, error) {
+	now := time.Now()
+	member := strconv.FormatInt(now.UnixNano(), 10)
+	return recordFailureScript.Run(ctx, r.client,
+		[]string{loginFailuresKey(subject)},
+		now.UnixMilli(), window.Milliseconds(), member,
+	).Int64()
+}
+
+func (r *loginAttemptRepository) ClearFailures(ctx context.Context, subject string) error {
+	return r.client.Del(ctx, loginFailuresKey(subject)).Err()
+}
+
+func (r *loginAttemptRepository) Lock(ctx context.Context, subject string, backoff func(level int64) time.Duration, levelTTL time.Duration) (time.Duration, int64, error) {
+	level, err := r.client.Incr(ctx, loginLockLevelKey(subject)).Result()
+	if err != nil {
+		return 0, 0, err
+	}
+	// The level decays once the subject behaves for levelTTL
+	if err := r.client.Expire(ctx, loginLockLevelKey(subject), levelTTL).Err(); err != nil {
+		return 0, 0, err
+	}
+
+	duration := backoff(level)
+	_, err = r.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Set(ctx, loginLockKey(subject), level, duration)
+		// Start counting from scratch once the lock expires
+		pipe.Del(ctx, loginFailuresKey(subject))
+		return nil
+	})
+	if err != nil {
+		return 0, 0, err
+	}
+	return duration, level, nil
+}
+
+func (r *loginAttemptRepository) LockedFor(ctx context.Context, subject string) (time.Duration, error) {
+	ttl, err := r.client.PTTL(ctx, loginLockKey(subject)).Result()
+	if err != nil {
+		return 0, err
+	}
+	// Negative values mean the key does not exist or has no expiry
+	if ttl < 0 {
+		return 0, nil
+	}
+	return ttl, nil
+}
@@ -0,0 +1,104 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/ThreeDotsLabs/watermill/message"
+	"github.com/username/progetto/auth/internal/model"
+	"github.com/username/progetto/shared/pkg/outbox"
+	"gorm.io/gorm"
+)
+
+// activeSuspension matches the suspensions not lifted nor expired
+const activeSuspension = "lifted_at IS NULL AND (expires_at IS NULL OR expires_at > ?)"
+
+type ModerationRepository interface {
+	// Suspend replaces the active suspension of the user, recording the audit entry and the event in the same transaction
+	Suspend(ctx context.Context, suspension *model.Suspension, audit *model.AuditEntry, topic string, msg *message.Message) error
+	// Lift ends the active suspension of the user, it returns gorm.ErrRecordNotFound when there is none
+	Lift(ctx context.Context, userID, liftedBy uint, audit *model.AuditEntry, topic string, msg *message.Message) error
+	// FindActive returns the active suspension of the user or gorm.ErrRecordNotFound
+	FindActive(ctx context.Context, userID uint) (*model.Suspension, error)
+	Record(ctx context.Context, audit *model.AuditEntry) error
+	// ListAudit returns the latest limit audit entries about the user
+	ListAudit(ctx context.Context, userID uint, limit int) ([]model.AuditEntry, error)
+}
+
+type moderationRepository struct {
+	db *gorm.DB
+}
+
+func NewModerationRepository(db *gorm.DB) ModerationRepository {
+	return &moderationRepository{db: db}
+}
+
+func (r *moderationRepository) Suspend(ctx context.Context, suspension *model.Suspension, audit *model.AuditEntry, topic string, msg *message.Message) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		err := tx.Model(&model.Suspension{}).
+			Where("user_id = ?", suspension.UserID).
+			Where(activeSuspension, now).
+			Updates(map[string]interface{}{"lifted_at": now, "lifted_by": suspension.CreatedBy}).Error
+		if err != nil {
+			return err
+		}
+
+		if err := tx.Create(suspension).Error; err != nil {
+			return err
+		}
+		if err := tx.Create(audit).Error; err != nil {
+			return err
+		}
+		return outbox.Store(ctx, tx, topic, fmt.Sprintf("%d", suspension.UserID), msg)
+	})
+}
+
+func (r *moderationRepository) Lift(ctx context.Context, userID, liftedBy uint, audit *model.AuditEntry, topic string, msg *message.Message) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		result := tx.Model(&model.Suspension{}).
+			Where("user_id = ?", userID).
+			Where(activeSuspension, now).
+			Updates(map[string]interface{}{"lifted_at": now, "lifted_by": liftedBy})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+
+		if err := tx.Create(audit).Error; err != nil {
+			return err
+		}
+		return outbox.Store(ctx, tx, topic, fmt.Sprintf("%d", userID), msg)
+	})
+}
+
+func (r *moderationRepository) FindActive(ctx context.Context, userID uint) (*model.Suspension, error) {
+	var suspension model.Suspension
+	err := r.db.WithContext(ctx).
+		Where("user_id = ?", userID).
+		Where(activeSuspension, time.Now()).
+		Order("created_at DESC").
+		First(&suspension).Error
+	if err != nil {
+		return nil, err
+	}
+	return &suspension, nil
+}
+
+func (r *moderationRepository) Record(ctx context.Context, audit *model.AuditEntry) error {
+	return r.db.WithContext(ctx).Create(audit).Error
+}
+
+func (r *moderationRepository) ListAudit(ctx context.Context, userID uint, limit int) ([]model.AuditEntry, error) {
+	var entries []model.AuditEntry
+	err := r.db.WithContext(ctx).
+		Where("target_user_id = ?", userID).
+		Order("created_at DESC").
+		Limit(limit).
+		Find(&entries).Error
+	return entries, err
+}
@@ -0,0 +1,54 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// OIDCState is what the authorization request needs back at the callback
+type OIDCState struct {
+	Provider     string `json:"provider"`
+	CodeVerifier string `json:"code_verifier"`
+	Nonce        string `json:"nonce"`
+}
+
+type OIDCStateRepository interface {
+	SaveState(ctx context.Context, state string, data OIDCState, duration time.Duration) error
+	// ConsumeState returns the data of the state and deletes it, so a callback can't be replayed
+	ConsumeState(ctx context.Context, state string) (*OIDCState, error)
+}
+
+type oidcStateRepository struct {
+	client *redis.Client
+}
+
+func NewOIDCStateRepository(client *redis.Client) OIDCStateRepository {
+	return &oidcStateRepository{client: client}
+}
+
+func oidcStateKey(stateHash string) string {
+	return "oidc_state:" + stateHash
+}
+
+func (r *oidcStateRepository) SaveState(ctx context.Context, state string, data OIDCState, duration time.Duration) error {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+	return r.client.Set(ctx, oidcStateKey(hashToken(state)), payload, duration).Err()
+}
+
+func (r *oidcStateRepository) ConsumeState(ctx context.Context, state string) (*OIDCState, error) {
+	payload, err := r.client.GetDel(ctx, oidcStateKey(hashToken(state))).Bytes()
+	if err != nil {
+		return nil, err
+	}
+	var data OIDCState
+	if err := json.Unmarshal(payload, &data); err != nil {
+		return nil, err
+	}
+	return &data, nil
+}
@@ -0,0 +1,55 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+type PasswordResetRepository interface {
+	SaveResetToken(ctx context.Context, token, userID string, duration time.Duration) error
+	// ConsumeResetToken returns the user ID of the token and deletes it, so it can be used once
+	ConsumeResetToken(ctx context.Context, token string) (string, error)
+	// AllowResetRequest counts a request for the email and reports whether it is within the limit
+	AllowResetRequest(ctx context.Context, email string, limit int64, window time.Duration) (bool, error)
+}
+
+type passwordResetRepository struct {
+	client *redis.Client
+}
+
+func NewPasswordResetRepository(client *redis.Client) PasswordResetRepository {
+	return &passwordResetRepository{client: client}
+}
+
+func resetTokenKey(tokenHash string) string {
+	return "password_reset:" + tokenHash
+}
+
+func resetRateKey(email string) string {
+	return "password_reset_rate:" + email
+}
+
+func (r *passwordResetRepository) SaveResetToken(ctx context.Context, token, userID string, duration time.Duration) error {
+	return r.client.Set(ctx, resetTokenKey(hashToken(token)), userID, duration).Err()
+}
+
+func (r *passwordResetRepository) ConsumeResetToken(ctx context.Context, token string) (string, error) {
+	return r.client.GetDel(ctx, resetTokenKey(hashToken(token))).Result()
+}
+
+func (r *passwordResetRepository) AllowResetRequest(ctx context.Context, email string, limit int64, window time.Duration) (bool, error) {
+	key := resetRateKey(email)
+	count, err := r.client.Incr(ctx, key).Result()
+	if err != nil {
+		return false, err
+	}
+	// First request opens the window
+	if count == 1 {
+		if err := r.client.Expire(ctx, key, window).Err(); err != nil {
+			return false, err
+		}
+	}
+	return count <= limit, nil
+}
@@ -2,16 +2,51 @@ package repository
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
 
+	"github.com/ThreeDotsLabs/watermill/message"
 	"github.com/username/progetto/auth/internal/model"
+	"github.com/username/progetto/shared/pkg/outbox"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
+// EventFunc builds the event of a created or updated user, it is stored in the outbox with the user
+type EventFunc func(user *model.User) (topic string, msg *message.Message)
+
 type UserRepository interface {
-	Create(ctx context.Context, user *model.User) error
+	// Create saves the user and its event in one transaction, the outbox relay publishes the event.
+	// It returns gorm.ErrDuplicatedKey when the username or email is taken.
+	Create(ctx context.Context, user *model.User, newEvent EventFunc) error
 	FindByEmail(ctx context.Context, email string) (*model.User, error)
+	// EmailExists reports whether a user has the email, deleted users included as they keep it unique
+	EmailExists(ctx context.Context, email string) (bool, error)
 	FindByID(ctx context.Context, id uint) (*model.User, error)
+	UpdatePassword(ctx context.Context, id uint, hashedPassword string) error
+	// Update applies the changes and bumps the user version, storing the event of the updated user
+	// in the same transaction, and returns the updated user.
+	// It returns gorm.ErrDuplicatedKey when the new username or email is taken.
+	Update(ctx context.Context, id uint, changes map[string]interface{}, newEvent EventFunc) (*model.User, error)
+	// UpdateRole changes the role like Update, recording the audit entry in the same transaction
+	UpdateRole(ctx context.Context, id uint, role string, audit *model.AuditEntry, newEvent EventFunc) (*model.User, error)
+	MarkEmailVerified(ctx context.Context, id uint, verifiedAt time.Time) error
+	// FindUnverifiedBefore returns up to limit never verified users created before the given time
+	FindUnverifiedBefore(ctx context.Context, before time.Time, limit int) ([]model.User, error)
 	Delete(ctx context.Context, id uint) error
+	// List returns up to limit users matching the filter with an ID greater than afterID, ordered by ID
+	List(ctx context.Context, filter UserFilter, afterID uint, limit int) ([]model.User, error)
+}
+
+// UserFilter narrows List, empty fields match every user
+type UserFilter struct {
+	// Query matches part of the username or of the email
+	Query string
+	Role  string
+	// Suspended keeps only the users with (true) or without (false) an active suspension
+	Suspended *bool
 }
 
 type postgresRepository struct {
@@ -22,8 +57,14 @@ func NewPostgresRepository(db *gorm.DB) UserRepository {
 	return &postgresRepository{db: db}
 }
 
-func (r *postgresRepository) Create(ctx context.Context, user *model.User) error {
-	return r.db.WithContext(ctx).Create(user).Error
+func (r *postgresRepository) Create(ctx context.Context, user *model.User, newEvent EventFunc) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(user).Error; err != nil {
+			return err
+		}
+		topic, msg := newEvent(user)
+		return outbox.Store(ctx, tx, topic, fmt.Sprintf("%d", user.ID), msg)
+	})
 }
 
 func (r *postgresRepository) FindByEmail(ctx context.Context, email string) (*model.User, error) {
@@ -34,6 +75,14 @@ func (r *postgresRepository) FindByEmail(ctx context.Context, email string) (*mo
 	return &user, nil
 }
 
+func (r *postgresRepository) EmailExists(ctx context.Context, email string) (bool, error) {
+	var count int64
+	if err := r.db.WithContext(ctx).Unscoped().Model(&model.User{}).Where("email = ?", email).Count(&count).Error; err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
 func (r *postgresRepository) FindByID(ctx context.Context, id uint) (*model.User, error) {
 	var user model.User
 	if err := r.db.WithContext(ctx).First(&user, id).Error; err != nil {
@@ -42,6 +91,112 @@ func (r *postgresRepository) FindByID(ctx context.Context, id uint) (*model.User
 	return &user, nil
 }
 
+func (r *postgresRepository) UpdatePassword(ctx context.Context, id uint, hashedPassword string) error {
+	return r.db.WithContext(ctx).Model(&model.User{}).Where("id = ?", id).Update("password", hashedPassword).Error
+}
+
+func (r *postgresRepository) Update(ctx context.Context, id uint, changes map[string]interface{}, newEvent EventFunc) (*model.User, error) {
+	// Map updates skip the json serializer of the genre columns
+	for column, value := range changes {
+		if list, ok := value.([]string); ok {
+			encoded, err := json.Marshal(list)
+			if err != nil {
+				return nil, err
+			}
+			changes[column] = string(encoded)
+		}
+	}
+
+	var user *model.User
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var err error
+		user, err = updateUser(ctx, tx, id, changes, newEvent)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+func (r *postgresRepository) UpdateRole(ctx context.Context, id uint, role string, audit *model.AuditEntry, newEvent EventFunc) (*model.User, error) {
+	var user *model.User
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var err error
+		user, err = updateUser(ctx, tx, id, map[string]interface{}{"role": role}, newEvent)
+		if err != nil {
+			return err
+		}
+		return tx.Create(audit).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// updateUser applies the changes and stores the event of the updated user within the transaction
+func updateUser(ctx context.Context, tx *gorm.DB, id uint, changes map[string]interface{}, newEvent EventFunc) (*model.User, error) {
+	changes["version"] = gorm.Expr("version + 1")
+
+	var users []model.User
+	if err := tx.Model(&users).Clauses(clause.Returning{}).Where("id = ?", id).Updates(changes).Error; err != nil {
+		return nil, err
+	}
+	if len(users) == 0 {
+		return nil, gorm.ErrRecordNotFound
+	}
+	topic, msg := newEvent(&users[0])
+	if err := outbox.Store(ctx, tx, topic, fmt.Sprintf("%d", id), msg); err != nil {
+		return nil, err
+	}
+	return &users[0], nil
+}
+
+func (r *postgresRepository) MarkEmailVerified(ctx context.Context, id uint, verifiedAt time.Time) error {
+	return r.db.WithContext(ctx).Model(&model.User{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"email_verified":    true,
+		"email_verified_at": verifiedAt,
+	}).Error
+}
+
+func (r *postgresRepository) FindUnverifiedBefore(ctx context.Context, before time.Time, limit int) ([]model.User, error) {
+	var users []model.User
+	err := r.db.WithContext(ctx).
+		Where("email_verified = ? AND email_verified_at IS NULL AND created_at < ?", false, before).
+		Order("created_at").
+		Limit(limit).
+		Find(&users).Error
+	if err != nil {
+		return nil, err
+	}
+	return users, nil
+}
+
 func (r *postgresRepository) Delete(ctx context.Context, id uint) error {
 	return r.db.WithContext(ctx).Delete(&model.User{}, id).Error
 }
+
+func (r *postgresRepository) List(ctx context.Context, filter UserFilter, afterID uint, limit int) ([]model.User, error) {
+	query := r.db.WithContext(ctx).Where("id > ?", afterID)
+	if filter.Query != "" {
+		pattern := "%" + strings.NewReplacer("%", `\%`, "_", `\_`).Replace(filter.Query) + "%"
+		query = query.Where("(username ILIKE ? OR email ILIKE ?)", pattern, pattern)
+	}
+	if filter.Role != "" {
+		query = query.Where("role = ?", filter.Role)
+	}
+	if filter.Suspended != nil {
+		exists := "EXISTS (SELECT 1 FROM suspensions WHERE suspensions.user_id = users.id AND " + activeSuspension + ")"
+		if !*filter.Suspended {
+			exists = "NOT " + exists
+		}
+		query = query.Where(exists, time.Now())
+	}
+
+	var users []model.User
+	if err := query.Order("id").Limit(limit).Find(&users).Error; err != nil {
+		return nil, err
+	}
+	return users, nil
+}
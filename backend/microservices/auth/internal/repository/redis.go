@@ -2,15 +2,47 @@ package repository
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"strconv"
 	"time"
 
 	"github.com/redis/go-redis/v9"
 )
 
+// Family groups every refresh token issued from a single login.
+// Its ID is the session ID carried by access tokens.
+// IP is the one of the login, LastIP the one of the last refresh.
+type Family struct {
+	ID         string
+	UserID     string
+	Device     string
+	IP         string
+	UserAgent  string
+	LastIP     string
+	CreatedAt  time.Time
+	LastUsedAt time.Time
+}
+
 type TokenRepository interface {
-	SetRefreshToken(ctx context.Context, token string, userID string, duration time.Duration) error
-	GetUserIDByRefreshToken(ctx context.Context, token string) (string, error)
-	DeleteRefreshToken(ctx context.Context, token string) error
+	// CreateFamily stores a new family together with its first refresh token
+	CreateFamily(ctx context.Context, family *Family, token string, duration time.Duration) error
+	// LookupRefreshToken returns the family of the token and whether the token is still the current one.
+	// A token that is not current has already been rotated and presenting it again means reuse.
+	LookupRefreshToken(ctx context.Context, token string) (*Family, bool, error)
+	// RotateRefreshToken atomically replaces the current token of the family.
+	// It returns false if oldToken is no longer current (concurrent reuse).
+	// The session index of the user is extended with the family, so revoking all sessions still finds it.
+	RotateRefreshToken(ctx context.Context, family *Family, oldToken, newToken, ip string, duration time.Duration) (bool, error)
+	// GetFamily returns redis.Nil when the family was revoked or expired
+	GetFamily(ctx context.Context, familyID string) (*Family, error)
+	ListFamilies(ctx context.Context, userID string) ([]Family, error)
+	DeleteFamily(ctx context.Context, family *Family) error
+	// DeleteAllFamilies removes every family of the user and returns the killed ones
+	DeleteAllFamilies(ctx context.Context, userID string) ([]Family, error)
+	// RememberDevice adds the device to the known devices of the user.
+	// It returns true when the device is new and the user already had other devices.
+	RememberDevice(ctx context.Context, userID, fingerprint string, duration time.Duration) (bool, error)
 }
 
 type redisRepository struct {
@@ -21,14 +53,211 @@ func NewRedisRepository(client *redis.Client) TokenRepository {
 	return &redisRepository{client: client}
 }
 
-func (r *redisRepository) SetRefreshToken(ctx context.Context, token string, userID string, duration time.Duration) error {
-	return r.client.Set(ctx, "refresh:"+token, userID, duration).Err()
+// Tokens are stored hashed, so a Redis dump does not leak usable refresh tokens
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// refreshKey maps a token hash to its family. Rotated tokens are kept until they
+// expire so that replaying them can be detected.
+func refreshKey(tokenHash string) string {
+	return "refresh:" + tokenHash
+}
+
+func familyKey(familyID string) string {
+	return "refresh_family:" + familyID
+}
+
+// userSessionsKey indexes all families of a user
+func userSessionsKey(userID string) string {
+	return "user_sessions:" + userID
+}
+
+// userDevicesKey holds the fingerprints of the devices the user logged in from
+func userDevicesKey(userID string) string {
+	return "user_devices:" + userID
+}
+
+// rotateScript swaps the current token of a family only if the caller presented the current one.
+// KEYS[1]: family key, KEYS[2]: new token key, KEYS[3]: user sessions key
+// ARGV[1]: old token hash, ARGV[2]: new token hash, ARGV[3]: family ID, ARGV[4]: now, ARGV[5]: ttl seconds, ARGV[6]: client IP
+var rotateScript = redis.NewScript(`
+if redis.call("HGET", KEYS[1], "current_token") ~= ARGV[1] then
+	return 0
+end
+redis.call("HSET", KEYS[1], "current_token", ARGV[2], "last_used_at", ARGV[4], "last_ip", ARGV[6])
+redis.call("EXPIRE", KEYS[1], ARGV[5])
+redis.call("SET", KEYS[2], ARGV[3], "EX", ARGV[5])
+redis.call("EXPIRE", KEYS[3], ARGV[5])
+return 1
+`)
+
+// rememberDeviceScript adds a fingerprint to the known devices.
+// KEYS[1]: devices key. ARGV[1]: fingerprint, ARGV[2]: ttl seconds
+// Returns 1 if the fingerprint is new and the set was not empty (first login ever is not a new device).
+var rememberDeviceScript = redis.NewScript(`
+local known = redis.call("SCARD", KEYS[1])
+local added = redis.call("SADD", KEYS[1], ARGV[1])
+redis.call("EXPIRE", KEYS[1], ARGV[2])
+if added == 1 and known > 0 then
+	return 1
+end
+return 0
+`)
+
+func (r *redisRepository) CreateFamily(ctx context.Context, family *Family, token string, duration time.Duration) error {
+	tokenHash := hashToken(token)
+	_, err := r.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.HSet(ctx, familyKey(family.ID),
+			"user_id", family.UserID,
+			"current_token", tokenHash,
+			"device", family.Device,
+			"ip", family.IP,
+			"last_ip", family.IP,
+			"user_agent", family.UserAgent,
+			"created_at", family.CreatedAt.Unix(),
+			"last_used_at", family.LastUsedAt.Unix(),
+		)
+		pipe.Expire(ctx, familyKey(family.ID), duration)
+		pipe.Set(ctx, refreshKey(tokenHash), family.ID, duration)
+		pipe.SAdd(ctx, userSessionsKey(family.UserID), family.ID)
+		// The index lives as long as the newest family
+		pipe.Expire(ctx, userSessionsKey(family.UserID), duration)
+		return nil
+	})
+	return err
+}
+
+func (r *redisRepository) LookupRefreshToken(ctx context.Context, token string) (*Family, bool, error) {
+	tokenHash := hashToken(token)
+	familyID, err := r.client.Get(ctx, refreshKey(tokenHash)).Result()
+	if err != nil {
+		return nil, false, err
+	}
+
+	values, err := r.client.HGetAll(ctx, familyKey(familyID)).Result()
+	if err != nil {
+		return nil, false, err
+	}
+	if len(values) == 0 {
+		// Family already revoked
+		return nil, false, redis.Nil
+	}
+
+	return familyFromHash(familyID, values), values["current_token"] == tokenHash, nil
+}
+
+func (r *redisRepository) RotateRefreshToken(ctx context.Context, family *Family, oldToken, newToken, ip string, duration time.Duration) (bool, error) {
+	newHash := hashToken(newToken)
+	res, err := rotateScript.Run(ctx, r.client,
+		[]string{familyKey(family.ID), refreshKey(newHash), userSessionsKey(family.UserID)},
+		hashToken(oldToken), newHash, family.ID, time.Now().Unix(), int64(duration.Seconds()), ip,
+	).Int()
+	if err != nil {
+		return false, err
+	}
+	return res == 1, nil
+}
+
+func (r *redisRepository) GetFamily(ctx context.Context, familyID string) (*Family, error) {
+	values, err := r.client.HGetAll(ctx, familyKey(familyID)).Result()
+	if err != nil {
+		return nil, err
+	}
+	if len(values) == 0 {
+		return nil, redis.Nil
+	}
+	return familyFromHash(familyID, values), nil
+}
+
+func (r *redisRepository) ListFamilies(ctx context.Context, userID string) ([]Family, error) {
+	familyIDs, err := r.client.SMembers(ctx, userSessionsKey(userID)).Result()
+	if err != nil {
+		return nil, err
+	}
+	if len(familyIDs) == 0 {
+		return nil, nil
+	}
+
+	pipe := r.client.Pipeline()
+	cmds := make([]*redis.MapStringStringCmd, len(familyIDs))
+	for i, id := range familyIDs {
+		cmds[i] = pipe.HGetAll(ctx, familyKey(id))
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		return nil, err
+	}
+
+	var families []Family
+	var expired []interface{}
+	for i, id := range familyIDs {
+		values := cmds[i].Val()
+		if len(values) == 0 {
+			expired = append(expired, id)
+			continue
+		}
+		families = append(families, *familyFromHash(id, values))
+	}
+
+	// Lazily drop families that expired on their own
+	if len(expired) > 0 {
+		r.client.SRem(ctx, userSessionsKey(userID), expired...)
+	}
+
+	return families, nil
+}
+
+func (r *redisRepository) DeleteFamily(ctx context.Context, family *Family) error {
+	// Token keys are left to expire: a revoked family makes them invalid anyway
+	_, err := r.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Del(ctx, familyKey(family.ID))
+		pipe.SRem(ctx, userSessionsKey(family.UserID), family.ID)
+		return nil
+	})
+	return err
+}
+
+func (r *redisRepository) DeleteAllFamilies(ctx context.Context, userID string) ([]Family, error) {
+	families, err := r.ListFamilies(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	keys := make([]string, 0, len(families)+1)
+	for _, family := range families {
+		keys = append(keys, familyKey(family.ID))
+	}
+	keys = append(keys, userSessionsKey(userID))
+
+	if err := r.client.Del(ctx, keys...).Err(); err != nil {
+		return nil, err
+	}
+	return families, nil
 }
 
-func (r *redisRepository) GetUserIDByRefreshToken(ctx context.Context, token string) (string, error) {
-	return r.client.Get(ctx, "refresh:"+token).Result()
+func (r *redisRepository) RememberDevice(ctx context.Context, userID, fingerprint string, duration time.Duration) (bool, error) {
+	res, err := rememberDeviceScript.Run(ctx, r.client,
+		[]string{userDevicesKey(userID)},
+		fingerprint, int64(duration.Seconds()),
+	).Int()
+	if err != nil {
+		return false, err
+	}
+	return res == 1, nil
 }
 
-func (r *redisRepository) DeleteRefreshToken(ctx context.Context, token string) error {
-	return r.client.Del(ctx, "refresh:"+token).Err()
+func familyFromHash(familyID string, values map[string]string) *Family {
+	createdAt, _ := strconv.ParseInt(values["created_at"], 10, 64)
+	lastUsedAt, _ := strconv.ParseInt(values["last_used_at"], 10, 64)
+	return &Family{
+		ID:         familyID,
+		UserID:     values["user_id"],
+		Device:     values["device"],
+		IP:         values["ip"],
+		UserAgent:  values["user_agent"],
+		LastIP:     values["last_ip"],
+		CreatedAt:  time.Unix(createdAt, 0),
+		LastUsedAt: time.Unix(lastUsedAt, 0),
+	}
 }
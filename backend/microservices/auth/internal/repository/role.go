@@ -0,0 +1,66 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/username/progetto/auth/internal/model"
+	"github.com/username/progetto/shared/pkg/rbac"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type RoleRepository interface {
+	// Seed adds the missing default roles, permissions and grants, existing rows are left as they are
+	Seed(ctx context.Context, permissions []rbac.PermissionDefinition, roles []rbac.RoleDefinition) error
+	List(ctx context.Context) ([]model.Role, error)
+	FindByName(ctx context.Context, name string) (*model.Role, error)
+}
+
+type roleRepository struct {
+	db *gorm.DB
+}
+
+func NewRoleRepository(db *gorm.DB) RoleRepository {
+	return &roleRepository{db: db}
+}
+
+func (r *roleRepository) Seed(ctx context.Context, permissions []rbac.PermissionDefinition, roles []rbac.RoleDefinition) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		for _, p := range permissions {
+			permission := model.Permission{Name: p.Name, Description: p.Description}
+			if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&permission).Error; err != nil {
+				return err
+			}
+		}
+
+		for _, def := range roles {
+			role := model.Role{Name: def.Name, Description: def.Description}
+			if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Omit("Permissions").Create(&role).Error; err != nil {
+				return err
+			}
+			for _, permission := range def.Permissions {
+				grant := map[string]interface{}{"role_name": def.Name, "permission_name": permission}
+				if err := tx.Table("role_permissions").Clauses(clause.OnConflict{DoNothing: true}).Create(grant).Error; err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	})
+}
+
+func (r *roleRepository) List(ctx context.Context) ([]model.Role, error) {
+	var roles []model.Role
+	if err := r.db.WithContext(ctx).Preload("Permissions").Order("name").Find(&roles).Error; err != nil {
+		return nil, err
+	}
+	return roles, nil
+}
+
+func (r *roleRepository) FindByName(ctx context.Context, name string) (*model.Role, error) {
+	var role model.Role
+	if err := r.db.WithContext(ctx).Preload("Permissions").Where("name = ?", name).First(&role).Error; err != nil {
+		return nil, err
+	}
+	return &role, nil
+}
@@ -0,0 +1,40 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/username/progetto/auth/internal/model"
+	"gorm.io/gorm"
+)
+
+type SigningKeyRepository interface {
+	Create(ctx context.Context, key *model.SigningKey) error
+	// List returns every stored key, newest first
+	List(ctx context.Context) ([]model.SigningKey, error)
+	DeleteCreatedBefore(ctx context.Context, t time.Time) error
+}
+
+type signingKeyRepository struct {
+	db *gorm.DB
+}
+
+func NewSigningKeyRepository(db *gorm.DB) SigningKeyRepository {
+	return &signingKeyRepository{db: db}
+}
+
+func (r *signingKeyRepository) Create(ctx context.Context, key *model.SigningKey) error {
+	return r.db.WithContext(ctx).Create(key).Error
+}
+
+func (r *signingKeyRepository) List(ctx context.Context) ([]model.SigningKey, error) {
+	var keys []model.SigningKey
+	if err := r.db.WithContext(ctx).Order("created_at DESC").Find(&keys).Error; err != nil {
+		return nil, err
+	}
+	return keys, nil
+}
+
+func (r *signingKeyRepository) DeleteCreatedBefore(ctx context.Context, t time.Time) error {
+	return r.db.WithContext(ctx).Where("created_at < ?", t).Delete(&model.SigningKey{}).Error
+}
@@ -0,0 +1,84 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/username/progetto/auth/internal/model"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type TwoFactorRepository interface {
+	FindByUserID(ctx context.Context, userID uint) (*model.TwoFactor, error)
+	// SavePending stores a new, not yet enabled secret, replacing any previous pending enrollment
+	SavePending(ctx context.Context, twoFactor *model.TwoFactor) error
+	// Enable turns 2FA on and replaces the recovery codes in one transaction
+	Enable(ctx context.Context, userID uint, step int64, codes []model.RecoveryCode) error
+	// UseStep records a consumed TOTP step, returning false if it was not newer than the last one
+	UseStep(ctx context.Context, userID uint, step int64) (bool, error)
+	// UseRecoveryCode marks an unused code as used, returning false if there was none
+	UseRecoveryCode(ctx context.Context, userID uint, codeHash string) (bool, error)
+}
+
+type twoFactorRepository struct {
+	db *gorm.DB
+}
+
+func NewTwoFactorRepository(db *gorm.DB) TwoFactorRepository {
+	return &twoFactorRepository{db: db}
+}
+
+func (r *twoFactorRepository) FindByUserID(ctx context.Context, userID uint) (*model.TwoFactor, error) {
+	var twoFactor model.TwoFactor
+	if err := r.db.WithContext(ctx).First(&twoFactor, "user_id = ?", userID).Error; err != nil {
+		return nil, err
+	}
+	return &twoFactor, nil
+}
+
+func (r *twoFactorRepository) SavePending(ctx context.Context, twoFactor *model.TwoFactor) error {
+	return r.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"encrypted_secret", "enabled", "enabled_at", "last_used_step", "updated_at"}),
+	}).Create(twoFactor).Error
+}
+
+func (r *twoFactorRepository) Enable(ctx context.Context, userID uint, step int64, codes []model.RecoveryCode) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		err := tx.Model(&model.TwoFactor{}).Where("user_id = ?", userID).Updates(map[string]interface{}{
+			"enabled":        true,
+			"enabled_at":     now,
+			"last_used_step": step,
+		}).Error
+		if err != nil {
+			return err
+		}
+
+		if err := tx.Where("user_id = ?", userID).Delete(&model.RecoveryCode{}).Error; err != nil {
+			return err
+		}
+		return tx.Create(&codes).Error
+	})
+}
+
+func (r *twoFactorRepository) UseStep(ctx context.Context, userID uint, step int64) (bool, error) {
+	res := r.db.WithContext(ctx).Model(&model.TwoFactor{}).
+		Where("user_id = ? AND last_used_step < ?", userID, step).
+		Update("last_used_step", step)
+	if res.Error != nil {
+		return false, res.Error
+	}
+	return res.RowsAffected == 1, nil
+}
+
+func (r *twoFactorRepository) UseRecoveryCode(ctx context.Context, userID uint, codeHash string) (bool, error) {
+	res := r.db.WithContext(ctx).Model(&model.RecoveryCode{}).
+		Where("user_id = ? AND code_hash = ? AND used_at IS NULL", userID, codeHash).
+		Update("used_at", time.Now())
+	if res.Error != nil {
+		return false, res.Error
+	}
+	return res.RowsAffected == 1, nil
+}
@@ -0,0 +1,65 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+type TwoFactorChallengeRepository interface {
+	SaveChallenge(ctx context.Context, token, userID string, duration time.Duration) error
+	// RecordAttempt returns the user ID of the challenge and the number of attempts made so far, this one included
+	RecordAttempt(ctx context.Context, token string) (string, int64, error)
+	DeleteChallenge(ctx context.Context, token string) error
+}
+
+type twoFactorChallengeRepository struct {
+	client *redis.Client
+}
+
+func NewTwoFactorChallengeRepository(client *redis.Client) TwoFactorChallengeRepository {
+	return &twoFactorChallengeRepository{client: client}
+}
+
+func challengeKey(tokenHash string) string {
+	return "2fa_challenge:" + tokenHash
+}
+
+func (r *twoFactorChallengeRepository) SaveChallenge(ctx context.Context, token, userID string, duration time.Duration) error {
+	key := challengeKey(hashToken(token))
+	_, err := r.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.HSet(ctx, key, "user_id", userID, "attempts", 0)
+		pipe.Expire(ctx, key, duration)
+		return nil
+	})
+	return err
+}
+
+// recordAttemptScript increments the attempts of an existing challenge without recreating an expired one.
+// KEYS[1]: challenge key
+var recordAttemptScript = redis.NewScript(`
+local userID = redis.call("HGET", KEYS[1], "user_id")
+if not userID then
+	return false
+end
+local attempts = redis.call("HINCRBY", KEYS[1], "attempts", 1)
+return {userID, attempts}
+`)
+
+func (r *twoFactorChallengeRepository) RecordAttempt(ctx context.Context, token string) (string, int64, error) {
+	res, err := recordAttemptScript.Run(ctx, r.client, []string{challengeKey(hashToken(token))}).Slice()
+	if err != nil {
+		return "", 0, err
+	}
+	if len(res) != 2 {
+		return "", 0, redis.Nil
+	}
+	userID, _ := res[0].(string)
+	attempts, _ := res[1].(int64)
+	return userID, attempts, nil
+}
+
+func (r *twoFactorChallengeRepository) DeleteChallenge(ctx context.Context, token string) error {
+	return r.client.Del(ctx, challengeKey(hashToken(token))).Err()
+}
@@ -0,0 +1,253 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ThreeDotsLabs/watermill"
+	"github.com/ThreeDotsLabs/watermill/message"
+	"github.com/username/progetto/auth/internal/model"
+	"github.com/username/progetto/auth/internal/repository"
+	"github.com/username/progetto/shared/pkg/rbac"
+	"gorm.io/gorm"
+)
+
+const (
+	defaultUserPageSize = 20
+	maxUserPageSize     = 100
+	// Audit entries returned with a user
+	userAuditLimit = 50
+)
+
+var (
+	ErrNotSuspended      = errors.New("user is not suspended")
+	ErrInvalidSuspension = errors.New("a reason and an expiry in the future are required")
+	ErrInvalidPageToken  = errors.New("invalid page token")
+)
+
+// AccountSuspendedError is returned when a suspended user logs in or refreshes its session
+type AccountSuspendedError struct {
+	Reason string
+	// ExpiresAt is nil for a ban
+	ExpiresAt *time.Time
+}
+
+func (e *AccountSuspendedError) Error() string {
+	if e.ExpiresAt == nil {
+		return "account banned"
+	}
+	return fmt.Sprintf("account suspended until %s", e.ExpiresAt.UTC().Format(time.RFC3339))
+}
+
+// AdminUserView is a user as seen by an admin
+type AdminUserView struct {
+	User *model.User
+	// Suspension is the active one, nil when the user is not suspended
+	Suspension *model.Suspension
+	Audit      []model.AuditEntry
+}
+
+// ListUsers returns a page of users and the token of the next one, empty on the last page
+func (s *authService) ListUsers(ctx context.Context, accessToken string, filter repository.UserFilter, pageSize int, pageToken string) ([]model.User, string, error) {
+	if _, err := s.requirePermission(ctx, accessToken, rbac.PermUsersRead); err != nil {
+		return nil, "", err
+	}
+
+	if pageSize <= 0 {
+		pageSize = defaultUserPageSize
+	}
+	pageSize = min(pageSize, maxUserPageSize)
+
+	var afterID uint64
+	if pageToken != "" {
+		var err error
+		if afterID, err = strconv.ParseUint(pageToken, 10, 64); err != nil {
+			return nil, "", ErrInvalidPageToken
+		}
+	}
+
+	// One more user tells whether there is a next page
+	users, err := s.userRepo.List(ctx, filter, uint(afterID), pageSize+1)
+	if err != nil {
+		return nil, "", err
+	}
+	if len(users) <= pageSize {
+		return users, "", nil
+	}
+	users = users[:pageSize]
+	return users, strconv.FormatUint(uint64(users[pageSize-1].ID), 10), nil
+}
+
+func (s *authService) AdminGetUser(ctx context.Context, accessToken, userID string) (*AdminUserView, error) {
+	if _, err := s.requirePermission(ctx, accessToken, rbac.PermUsersRead); err != nil {
+		return nil, err
+	}
+
+	user, err := s.GetUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	view := &AdminUserView{User: user}
+
+	view.Suspension, err = s.moderationRepo.FindActive(ctx, user.ID)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+	if view.Audit, err = s.moderationRepo.ListAudit(ctx, user.ID, userAuditLimit); err != nil {
+		return nil, err
+	}
+	return view, nil
+}
+
+// SuspendUser blocks the login of a user until expiresAt, a nil expiresAt bans it.
+// It replaces the active suspension and revokes the sessions of the user.
+func (s *authService) SuspendUser(ctx context.Context, accessToken, userID, reason string, expiresAt *time.Time) (*model.Suspension, error) {
+	admin, err := s.requirePermission(ctx, accessToken, rbac.PermUsersManage)
+	if err != nil {
+		return nil, err
+	}
+	reason = strings.TrimSpace(reason)
+	if reason == "" || (expiresAt != nil && !expiresAt.After(time.Now())) {
+		return nil, ErrInvalidSuspension
+	}
+
+	user, err := s.GetUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if user.ID == admin.ID {
+		return nil, ErrPermissionDenied
+	}
+
+	suspension := &model.Suspension{
+		UserID:    user.ID,
+		Reason:    reason,
+		ExpiresAt: expiresAt,
+		CreatedBy: admin.ID,
+	}
+	action := model.AuditUserSuspended
+	if suspension.IsBan() {
+		action = model.AuditUserBanned
+	}
+	audit := newAuditEntry(admin.ID, action, user.ID, map[string]string{"reason": reason})
+	if expiresAt != nil {
+		audit.Details["expires_at"] = expiresAt.UTC().Format(time.RFC3339)
+	}
+
+	eventPayload := map[string]interface{}{
+		"user_id":      fmt.Sprintf("%d", user.ID),
+		"reason":       reason,
+		"banned":       suspension.IsBan(),
+		"suspended_at": time.Now().Unix(),
+	}
+	if expiresAt != nil {
+		eventPayload["expires_at"] = expiresAt.Unix()
+	}
+	if err := s.moderationRepo.Suspend(ctx, suspension, audit, "user_suspended", moderationEvent(user.ID, eventPayload)); err != nil {
+		return nil, err
+	}
+
+	if _, err := s.RevokeAllSessions(ctx, fmt.Sprintf("%d", user.ID)); err != nil {
+		return nil, err
+	}
+	slog.InfoContext(ctx, "user suspended", "user_id", user.ID, "by", admin.ID, "banned", suspension.IsBan())
+	return suspension, nil
+}
+
+// ReinstateUser lifts the active suspension or ban of a user
+func (s *authService) ReinstateUser(ctx context.Context, accessToken, userID, reason string) error {
+	admin, err := s.requirePermission(ctx, accessToken, rbac.PermUsersManage)
+	if err != nil {
+		return err
+	}
+
+	user, err := s.GetUser(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	audit := newAuditEntry(admin.ID, model.AuditUserReinstated, user.ID, map[string]string{"reason": reason})
+	eventPayload := map[string]interface{}{
+		"user_id":       fmt.Sprintf("%d", user.ID),
+		"reason":        reason,
+		"reinstated_at": time.Now().Unix(),
+	}
+	err = s.moderationRepo.Lift(ctx, user.ID, admin.ID, audit, "user_reinstated", moderationEvent(user.ID, eventPayload))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrNotSuspended
+		}
+		return err
+	}
+
+	slog.InfoContext(ctx, "user reinstated", "user_id", user.ID, "by", admin.ID)
+	return nil
+}
+
+// ForcePasswordReset replaces the password with a random one, revokes the sessions
+// and sends a reset email, the user can only log in again after choosing a new password
+func (s *authService) ForcePasswordReset(ctx context.Context, accessToken, userID string) error {
+	admin, err := s.requirePermission(ctx, accessToken, rbac.PermUsersManage)
+	if err != nil {
+		return err
+	}
+
+	user, err := s.GetUser(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	hashedPassword, err := s.passwords.Hash(generateOpaqueToken())
+	if err != nil {
+		return err
+	}
+	if err := s.userRepo.UpdatePassword(ctx, user.ID, hashedPassword); err != nil {
+		return err
+	}
+	if _, err := s.RevokeAllSessions(ctx, fmt.Sprintf("%d", user.ID)); err != nil {
+		return err
+	}
+	if err := s.moderationRepo.Record(ctx, newAuditEntry(admin.ID, model.AuditPasswordResetForced, user.ID, nil)); err != nil {
+		return err
+	}
+
+	slog.InfoContext(ctx, "password reset forced", "user_id", user.ID, "by", admin.ID)
+	return s.sendPasswordReset(ctx, user)
+}
+
+// checkSuspension returns an AccountSuspendedError if the user has an active suspension
+func (s *authService) checkSuspension(ctx context.Context, userID uint) error {
+	suspension, err := s.moderationRepo.FindActive(ctx, userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		return err
+	}
+	return &AccountSuspendedError{Reason: suspension.Reason, ExpiresAt: suspension.ExpiresAt}
+}
+
+func newAuditEntry(actorID uint, action string, targetUserID uint, details map[string]string) *model.AuditEntry {
+	if details == nil {
+		details = make(map[string]string)
+	}
+	return &model.AuditEntry{
+		ActorID:      actorID,
+		Action:       action,
+		TargetUserID: targetUserID,
+		Details:      details,
+	}
+}
+
+func moderationEvent(userID uint, payload map[string]interface{}) *message.Message {
+	payloadBytes, _ := json.Marshal(payload)
+	msg := message.NewMessage(watermill.NewUUID(), payloadBytes)
+	msg.Metadata.Set("user_id", fmt.Sprintf("%d", userID))
+	return msg
+}
@@ -7,6 +7,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"log/slog"
 	"strconv"
 	"time"
 
@@ -14,51 +15,152 @@ import (
 	"github.com/ThreeDotsLabs/watermill/message"
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/lithammer/shortuuid/v3"
+	"github.com/username/progetto/auth/internal/encryption"
 	"github.com/username/progetto/auth/internal/model"
+	"github.com/username/progetto/auth/internal/oidc"
+	"github.com/username/progetto/auth/internal/password"
 	"github.com/username/progetto/auth/internal/repository"
-	"golang.org/x/crypto/bcrypt"
+	"github.com/username/progetto/shared/pkg/grpcutil"
+	"github.com/username/progetto/shared/pkg/jwtutil"
+	"gorm.io/gorm"
+)
+
+const (
+	// At most passwordResetLimit reset emails per address within passwordResetWindow
+	passwordResetLimit  = 3
+	passwordResetWindow = time.Hour
+	// Unverified users are purged in batches of this size
+	purgeBatchSize = 100
 )
 
 var (
 	ErrInvalidCredentials = errors.New("invalid credentials")
 	ErrInvalidToken       = errors.New("invalid token")
+	ErrTokenReused        = errors.New("refresh token reused")
+	ErrTooManyRequests    = errors.New("too many requests")
+	ErrAlreadyVerified    = errors.New("email already verified")
 )
 
 type AuthService interface {
 	Register(ctx context.Context, email, password, username string) (string, string, string, int64, error)
-	Login(ctx context.Context, email, password string) (string, string, int64, error)
+	Login(ctx context.Context, email, password string) (string, string, int64, string, error)
 	Refresh(ctx context.Context, refreshToken string) (string, string, int64, error)
+	Logout(ctx context.Context, refreshToken string) error
+	LogoutAll(ctx context.Context, accessToken string) (int64, error)
+	// RevokeAllSessions kills every session of a user, e.g. after a password change or a ban
+	RevokeAllSessions(ctx context.Context, userID string) (int64, error)
 	CompensateUserCreation(ctx context.Context, userID string) error
+	JWKS(ctx context.Context) (*jwtutil.JWKS, error)
+	RequestEmailVerification(ctx context.Context, accessToken string) error
+	ConfirmEmailVerification(ctx context.Context, token string) error
+	// PurgeUnverifiedUsers compensates the creation of users that never verified their email
+	PurgeUnverifiedUsers(ctx context.Context, maxAge time.Duration) (int, error)
+	EnrollTwoFactor(ctx context.Context, accessToken string) (string, string, error)
+	ConfirmTwoFactor(ctx context.Context, accessToken, code string) ([]string, error)
+	VerifyTwoFactor(ctx context.Context, challengeToken, code string) (string, string, int64, error)
+	RequestPasswordReset(ctx context.Context, email string) error
+	ConfirmPasswordReset(ctx context.Context, token, newPassword string) error
+	OIDCAuthURL(ctx context.Context, provider string) (string, string, error)
+	OIDCCallback(ctx context.Context, provider, code, state string) (string, string, string, int64, string, error)
+	GetUser(ctx context.Context, userID string) (*model.User, error)
+	UpdateProfile(ctx context.Context, accessToken string, update ProfileUpdate) (*model.User, error)
+	ChangeUsername(ctx context.Context, accessToken, username string) (*model.User, error)
+	ChangeEmail(ctx context.Context, accessToken, newEmail, password string) (*model.User, error)
+	DeleteAccount(ctx context.Context, accessToken, password string) (string, error)
+	GetAccountDeletion(ctx context.Context, accessToken, deletionID string) (*model.AccountDeletion, error)
+	ConfirmUserDeletion(ctx context.Context, deletionID, service string) error
+	// RetryAccountDeletions republishes deletions not confirmed by every service within retryAfter
+	RetryAccountDeletions(ctx context.Context, retryAfter time.Duration) (int, error)
+	ListRoles(ctx context.Context, accessToken string) ([]model.Role, error)
+	AssignRole(ctx context.Context, accessToken, userID, role string) (*model.User, error)
+	ListUsers(ctx context.Context, accessToken string, filter repository.UserFilter, pageSize int, pageToken string) ([]model.User, string, error)
+	AdminGetUser(ctx context.Context, accessToken, userID string) (*AdminUserView, error)
+	SuspendUser(ctx context.Context, accessToken, userID, reason string, expiresAt *time.Time) (*model.Suspension, error)
+	ReinstateUser(ctx context.Context, accessToken, userID, reason string) error
+	ForcePasswordReset(ctx context.Context, accessToken, userID string) error
+	ListSessions(ctx context.Context, accessToken string) ([]repository.Family, string, error)
+	RevokeSession(ctx context.Context, accessToken, sessionID string) error
 }
 
 type authService struct {
 	userRepo        repository.UserRepository
 	tokenRepo       repository.TokenRepository
+	resetRepo       repository.PasswordResetRepository
+	verifyRepo      repository.EmailVerificationRepository
+	twoFactorRepo   repository.TwoFactorRepository
+	challengeRepo   repository.TwoFactorChallengeRepository
+	attemptRepo     repository.LoginAttemptRepository
+	identityRepo    repository.IdentityRepository
+	oidcStateRepo   repository.OIDCStateRepository
+	oidcProviders   map[string]*oidc.Provider
+	deletionRepo    repository.AccountDeletionRepository
+	roleRepo        repository.RoleRepository
+	moderationRepo  repository.ModerationRepository
+	denylist        jwtutil.Denylist
 	publisher       message.Publisher
-	jwtSecret       []byte
+	keys            *KeyManager
+	verifier        jwtutil.Verifier
+	secretCipher    *encryption.Cipher
+	totpIssuer      string
+	passwords       *password.Hasher
 	accessTokenTTL  time.Duration
 	refreshTokenTTL time.Duration
+	resetTokenTTL   time.Duration
+	verifyTokenTTL  time.Duration
 }
 
 func NewAuthService(
 	userRepo repository.UserRepository,
 	tokenRepo repository.TokenRepository,
+	resetRepo repository.PasswordResetRepository,
+	verifyRepo repository.EmailVerificationRepository,
+	twoFactorRepo repository.TwoFactorRepository,
+	challengeRepo repository.TwoFactorChallengeRepository,
+	attemptRepo repository.LoginAttemptRepository,
+	identityRepo repository.IdentityRepository,
+	oidcStateRepo repository.OIDCStateRepository,
+	oidcProviders map[string]*oidc.Provider,
+	deletionRepo repository.AccountDeletionRepository,
+	roleRepo repository.RoleRepository,
+	moderationRepo repository.ModerationRepository,
+	denylist jwtutil.Denylist,
 	publisher message.Publisher,
-	jwtSecret string,
+	keys *KeyManager,
+	secretCipher *encryption.Cipher,
+	totpIssuer string,
+	passwords *password.Hasher,
 ) AuthService {
 	return &authService{
 		userRepo:        userRepo,
 		tokenRepo:       tokenRepo,
+		resetRepo:       resetRepo,
+		verifyRepo:      verifyRepo,
+		twoFactorRepo:   twoFactorRepo,
+		challengeRepo:   challengeRepo,
+		attemptRepo:     attemptRepo,
+		identityRepo:    identityRepo,
+		oidcStateRepo:   oidcStateRepo,
+		oidcProviders:   oidcProviders,
+		deletionRepo:    deletionRepo,
+		roleRepo:        roleRepo,
+		moderationRepo:  moderationRepo,
+		denylist:        denylist,
 		publisher:       publisher,
-		jwtSecret:       []byte(jwtSecret),
+		keys:            keys,
+		verifier:        jwtutil.NewJWKSVerifier(keys.JWKS, keyCheckInterval),
+		secretCipher:    secretCipher,
+		totpIssuer:      totpIssuer,
+		passwords:       passwords,
 		accessTokenTTL:  15 * time.Minute,
 		refreshTokenTTL: 6 * 30 * 24 * time.Hour, // ~6 months
+		resetTokenTTL:   30 * time.Minute,
+		verifyTokenTTL:  24 * time.Hour,
 	}
 }
 
 // Register creates a user, publishes an event, and returns tokens
 func (s *authService) Register(ctx context.Context, email, password, username string) (string, string, string, int64, error) {
-	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	hashedPassword, err := s.passwords.Hash(password)
 	if err != nil {
 		return "", "", "", 0, err
 	}
@@ -70,108 +172,479 @@ func (s *authService) Register(ctx context.Context, email, password, username st
 
 	user := &model.User{
 		Email:    email,
-		Password: string(hashedPassword),
+		Password: hashedPassword,
 		Username: username,
 	}
 
-	if err := s.userRepo.Create(ctx, user); err != nil {
+	// The user and the user_created event are saved together, the saga starts even if Kafka is down
+	if err := s.userRepo.Create(ctx, user, userCreatedEvent); err != nil {
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			return "", "", "", 0, s.registrationConflict(ctx, email)
+		}
 		return "", "", "", 0, err
 	}
 
-	// Publish Event
+	// The user can ask for a new verification email, so this doesn't fail the registration
+	if err := s.sendVerificationEmail(ctx, user); err != nil {
+		slog.WarnContext(ctx, "failed to send verification email", "error", err, "user_id", user.ID)
+	}
+
+	accessToken, refreshToken, expiresIn, err := s.generateTokens(ctx, user.ID)
+	if err != nil {
+		return "", "", "", 0, err
+	}
+	return fmt.Sprintf("%d", user.ID), accessToken, refreshToken, expiresIn, nil
+}
+
+// registrationConflict tells which of the unique fields of a new user was taken
+func (s *authService) registrationConflict(ctx context.Context, email string) error {
+	exists, err := s.userRepo.EmailExists(ctx, email)
+	if err != nil {
+		return err
+	}
+	if exists {
+		return ErrEmailTaken
+	}
+	return ErrUsernameTaken
+}
+
+// userCreatedEvent starts the user creation saga, the other services create their copy of the user
+func userCreatedEvent(user *model.User) (string, *message.Message) {
 	eventPayload := map[string]interface{}{
 		"user_id":  fmt.Sprintf("%d", user.ID),
 		"email":    user.Email,
 		"username": user.Username,
+		"version":  user.Version,
 	}
 	payloadBytes, _ := json.Marshal(eventPayload)
 	msg := message.NewMessage(watermill.NewUUID(), payloadBytes)
 	msg.Metadata.Set("user_id", fmt.Sprintf("%d", user.ID))
-	msg.SetContext(ctx)
+	return "user_created", msg
+}
 
-	if err := s.publisher.Publish("user_created", msg); err != nil {
-		// Log error but don't fail registration? Ideally we should use outbox pattern, but for now simple publish
-		// We still return tokens because user is created
-		s.generateTokens(ctx, user.ID) // ignoring error for brevity in error path? No, let's just error out or return user without tokens?
-		// Better to just return error if event fails if we want consistency, or ignore event failure.
-		// Given the code before was returning user ID even on publish error, we should probably stick to that or improve.
-		// Original code: return fmt.Sprintf("%d", user.ID), fmt.Errorf("failed to publish event: %w", err)
-		// Let's keep failure behavior
-		return fmt.Sprintf("%d", user.ID), "", "", 0, fmt.Errorf("failed to publish event: %w", err)
+// verifyPassword checks the password of the user. When the stored hash uses another algorithm
+// or older parameters than the configured ones, it is replaced with a new hash of the password.
+func (s *authService) verifyPassword(ctx context.Context, user *model.User, password string) (bool, error) {
+	ok, needsRehash, err := s.passwords.Verify(password, user.Password)
+	if err != nil {
+		return false, err
+	}
+	if !ok || !needsRehash {
+		return ok, nil
 	}
 
-	accessToken, refreshToken, expiresIn, err := s.generateTokens(ctx, user.ID)
+	// The login goes on with the old hash, the upgrade is tried again next time
+	hashedPassword, err := s.passwords.Hash(password)
 	if err != nil {
-		return "", "", "", 0, err
+		slog.WarnContext(ctx, "failed to rehash password", "error", err, "user_id", user.ID)
+		return true, nil
 	}
-	return fmt.Sprintf("%d", user.ID), accessToken, refreshToken, expiresIn, nil
+	if err := s.userRepo.UpdatePassword(ctx, user.ID, hashedPassword); err != nil {
+		slog.WarnContext(ctx, "failed to store rehashed password", "error", err, "user_id", user.ID)
+		return true, nil
+	}
+	user.Password = hashedPassword
+	return true, nil
 }
 
-func (s *authService) Login(ctx context.Context, email, password string) (string, string, int64, error) {
+// Login returns a token pair, or only a challenge token when the user has 2FA enabled
+func (s *authService) Login(ctx context.Context, email, password string) (string, string, int64, string, error) {
+	if err := s.checkLoginLock(ctx, email); err != nil {
+		return "", "", 0, "", err
+	}
+
 	user, err := s.userRepo.FindByEmail(ctx, email)
 	if err != nil {
-		return "", "", 0, ErrInvalidCredentials
+		if err := s.recordLoginFailure(ctx, email, ""); err != nil {
+			return "", "", 0, "", err
+		}
+		return "", "", 0, "", ErrInvalidCredentials
 	}
 
-	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(password)); err != nil {
-		return "", "", 0, ErrInvalidCredentials
+	ok, err := s.verifyPassword(ctx, user, password)
+	if err != nil {
+		return "", "", 0, "", err
+	}
+	if !ok {
+		if err := s.recordLoginFailure(ctx, email, fmt.Sprintf("%d", user.ID)); err != nil {
+			return "", "", 0, "", err
+		}
+		return "", "", 0, "", ErrInvalidCredentials
 	}
 
-	return s.generateTokens(ctx, user.ID)
+	if err := s.checkSuspension(ctx, user.ID); err != nil {
+		return "", "", 0, "", err
+	}
+
+	// With 2FA enabled the failures are cleared only once the second factor succeeds
+	challengeToken, err := s.startTwoFactorChallenge(ctx, user.ID)
+	if err != nil {
+		return "", "", 0, "", err
+	}
+	if challengeToken != "" {
+		return "", "", 0, challengeToken, nil
+	}
+
+	if err := s.attemptRepo.ClearFailures(ctx, emailSubject(email)); err != nil {
+		return "", "", 0, "", err
+	}
+	accessToken, refreshToken, expiresIn, err := s.generateTokens(ctx, user.ID)
+	return accessToken, refreshToken, expiresIn, "", err
 }
 
 func (s *authService) Refresh(ctx context.Context, refreshToken string) (string, string, int64, error) {
-	userIDStr, err := s.tokenRepo.GetUserIDByRefreshToken(ctx, refreshToken)
+	family, current, err := s.tokenRepo.LookupRefreshToken(ctx, refreshToken)
 	if err != nil {
 		return "", "", 0, ErrInvalidToken
 	}
 
-	userID, _ := strconv.Atoi(userIDStr)
+	// An already rotated token is being replayed: the family is considered stolen
+	if !current {
+		return "", "", 0, s.handleTokenReuse(ctx, family)
+	}
 
-	// Rotate: Delete old
-	if err := s.tokenRepo.DeleteRefreshToken(ctx, refreshToken); err != nil {
-		// If fail to delete, might be race condition or redis down.
-		// Proceeding might be risky if we want strict rotation.
+	userID, _ := strconv.Atoi(family.UserID)
+	if err := s.checkSuspension(ctx, uint(userID)); err != nil {
 		return "", "", 0, err
 	}
 
-	return s.generateTokens(ctx, uint(userID))
-}
+	newRefreshToken := generateOpaqueToken()
+	rotated, err := s.tokenRepo.RotateRefreshToken(ctx, family, refreshToken, newRefreshToken, grpcutil.ClientInfoFromContext(ctx).IP, s.refreshTokenTTL)
+	if err != nil {
+		return "", "", 0, err
+	}
+	if !rotated {
+		// Another request rotated the same token first
+		return "", "", 0, s.handleTokenReuse(ctx, family)
+	}
 
-func (s *authService) generateTokens(ctx context.Context, userID uint) (string, string, int64, error) {
-	// Fetch User to get Role
-	user, err := s.userRepo.FindByID(ctx, userID)
+	// Keep the family ID as session ID so the new access token can still be revoked with the session
+	accessToken, err := s.signAccessToken(ctx, uint(userID), family.ID)
 	if err != nil {
 		return "", "", 0, err
 	}
+	return accessToken, newRefreshToken, int64(s.accessTokenTTL.Seconds()), nil
+}
 
-	// Access Token (JWT)
-	claims := jwt.MapClaims{
-		"sub":  fmt.Sprintf("%d", userID),
-		"role": user.Role,
-		"exp":  time.Now().Add(s.accessTokenTTL).Unix(),
-		"iat":  time.Now().Unix(),
+// handleTokenReuse revokes the whole family and notifies the user
+func (s *authService) handleTokenReuse(ctx context.Context, family *repository.Family) error {
+	if err := s.tokenRepo.DeleteFamily(ctx, family); err != nil {
+		return err
+	}
+	if err := s.denylist.Revoke(ctx, family.ID, s.accessTokenTTL); err != nil {
+		return err
+	}
+
+	client := grpcutil.ClientInfoFromContext(ctx)
+	eventPayload := map[string]interface{}{
+		"user_id":     family.UserID,
+		"family_id":   family.ID,
+		"device":      family.Device,
+		"ip":          client.IP,
+		"user_agent":  client.UserAgent,
+		"detected_at": time.Now().Unix(),
 	}
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	accessToken, err := token.SignedString(s.jwtSecret)
+	payloadBytes, _ := json.Marshal(eventPayload)
+	msg := message.NewMessage(watermill.NewUUID(), payloadBytes)
+	msg.Metadata.Set("user_id", family.UserID)
+	msg.SetContext(ctx)
+
+	if err := s.publisher.Publish("session_compromised", msg); err != nil {
+		return fmt.Errorf("failed to publish event: %w", err)
+	}
+
+	return ErrTokenReused
+}
+
+// Logout revokes the family of the refresh token and denylists the access tokens of its session
+func (s *authService) Logout(ctx context.Context, refreshToken string) error {
+	family, _, err := s.tokenRepo.LookupRefreshToken(ctx, refreshToken)
 	if err != nil {
-		return "", "", 0, err
+		return ErrInvalidToken
 	}
 
-	// Refresh Token (Opaque)
-	b := make([]byte, 32)
-	rand.Read(b)
-	refreshToken := base64.URLEncoding.EncodeToString(b)
+	if err := s.tokenRepo.DeleteFamily(ctx, family); err != nil {
+		return err
+	}
+
+	return s.denylist.Revoke(ctx, family.ID, s.accessTokenTTL)
+}
+
+// LogoutAll revokes every session of the user owning the access token
+func (s *authService) LogoutAll(ctx context.Context, accessToken string) (int64, error) {
+	claims, err := s.authenticate(ctx, accessToken)
+	if err != nil {
+		return 0, err
+	}
+
+	return s.RevokeAllSessions(ctx, claims.UserID)
+}
+
+// authenticate verifies an access token and rejects it if its session was revoked
+func (s *authService) authenticate(ctx context.Context, accessToken string) (*jwtutil.Claims, error) {
+	claims, err := s.verifier.ParseToken(ctx, accessToken)
+	if err != nil {
+		return nil, ErrInvalidToken
+	}
+
+	revoked, err := s.denylist.IsRevoked(ctx, claims.SessionID)
+	if err != nil {
+		return nil, err
+	}
+	if revoked {
+		return nil, ErrInvalidToken
+	}
+
+	return claims, nil
+}
+
+// authenticatedUser loads the owner of an access token
+func (s *authService) authenticatedUser(ctx context.Context, accessToken string) (*model.User, error) {
+	claims, err := s.authenticate(ctx, accessToken)
+	if err != nil {
+		return nil, err
+	}
+
+	userID, err := strconv.Atoi(claims.UserID)
+	if err != nil {
+		return nil, ErrInvalidToken
+	}
+
+	return s.userRepo.FindByID(ctx, uint(userID))
+}
+
+func (s *authService) RevokeAllSessions(ctx context.Context, userID string) (int64, error) {
+	families, err := s.tokenRepo.DeleteAllFamilies(ctx, userID)
+	if err != nil {
+		return 0, err
+	}
+
+	// Access tokens stay valid until they expire, so their sessions are denylisted for the access token TTL
+	for _, family := range families {
+		if err := s.denylist.Revoke(ctx, family.ID, s.accessTokenTTL); err != nil {
+			return 0, fmt.Errorf("failed to denylist session %s: %w", family.ID, err)
+		}
+	}
+
+	return int64(len(families)), nil
+}
+
+// sendVerificationEmail issues a verification token and hands it to the mail sender
+func (s *authService) sendVerificationEmail(ctx context.Context, user *model.User) error {
+	token := generateOpaqueToken()
+	if err := s.verifyRepo.SaveVerificationToken(ctx, token, fmt.Sprintf("%d", user.ID), s.verifyTokenTTL); err != nil {
+		return err
+	}
+	return s.publishVerificationEmail(ctx, user, user.Email, token)
+}
+
+// publishVerificationEmail asks the mail sender to deliver the token to the given address
+func (s *authService) publishVerificationEmail(ctx context.Context, user *model.User, email, token string) error {
+	userID := fmt.Sprintf("%d", user.ID)
+	eventPayload := map[string]interface{}{
+		"user_id":    userID,
+		"email":      email,
+		"username":   user.Username,
+		"token":      token,
+		"expires_at": time.Now().Add(s.verifyTokenTTL).Unix(),
+	}
+	payloadBytes, _ := json.Marshal(eventPayload)
+	msg := message.NewMessage(watermill.NewUUID(), payloadBytes)
+	msg.Metadata.Set("user_id", userID)
+	msg.SetContext(ctx)
+
+	if err := s.publisher.Publish("email_verification_requested", msg); err != nil {
+		return fmt.Errorf("failed to publish event: %w", err)
+	}
+	return nil
+}
+
+func (s *authService) RequestEmailVerification(ctx context.Context, accessToken string) error {
+	user, err := s.authenticatedUser(ctx, accessToken)
+	if err != nil {
+		return err
+	}
+	if user.EmailVerified {
+		return ErrAlreadyVerified
+	}
+
+	return s.sendVerificationEmail(ctx, user)
+}
+
+// ConfirmEmailVerification accepts both registration tokens and email change tokens
+func (s *authService) ConfirmEmailVerification(ctx context.Context, token string) error {
+	userID, err := s.verifyRepo.ConsumeVerificationToken(ctx, token)
+	if err != nil {
+		return s.confirmEmailChange(ctx, token)
+	}
+
+	id, err := strconv.Atoi(userID)
+	if err != nil {
+		return err
+	}
+
+	return s.userRepo.MarkEmailVerified(ctx, uint(id), time.Now())
+}
+
+// PurgeUnverifiedUsers publishes user_creation_failed for stale unverified users,
+// so the saga deletes them here and notifies the other services.
+func (s *authService) PurgeUnverifiedUsers(ctx context.Context, maxAge time.Duration) (int, error) {
+	users, err := s.userRepo.FindUnverifiedBefore(ctx, time.Now().Add(-maxAge), purgeBatchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	for _, user := range users {
+		userID := fmt.Sprintf("%d", user.ID)
+		failurePayload := map[string]interface{}{
+			"user_id": userID,
+			"reason":  "email not verified",
+			"source":  "auth-service",
+		}
+		payloadBytes, _ := json.Marshal(failurePayload)
+		msg := message.NewMessage(watermill.NewUUID(), payloadBytes)
+		msg.Metadata.Set("user_id", userID)
+		msg.SetContext(ctx)
+
+		if err := s.publisher.Publish("user_creation_failed", msg); err != nil {
+			return 0, fmt.Errorf("failed to publish event: %w", err)
+		}
+	}
+
+	return len(users), nil
+}
+
+// RequestPasswordReset issues a reset token and hands it to the mail sender.
+// Unknown emails succeed silently so the endpoint cannot be used to enumerate accounts.
+func (s *authService) RequestPasswordReset(ctx context.Context, email string) error {
+	allowed, err := s.resetRepo.AllowResetRequest(ctx, email, passwordResetLimit, passwordResetWindow)
+	if err != nil {
+		return err
+	}
+	if !allowed {
+		return ErrTooManyRequests
+	}
+
+	user, err := s.userRepo.FindByEmail(ctx, email)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		return err
+	}
+
+	return s.sendPasswordReset(ctx, user)
+}
+
+// sendPasswordReset saves a reset token and asks the notification service to email it
+func (s *authService) sendPasswordReset(ctx context.Context, user *model.User) error {
+	token := generateOpaqueToken()
+	userID := fmt.Sprintf("%d", user.ID)
+	if err := s.resetRepo.SaveResetToken(ctx, token, userID, s.resetTokenTTL); err != nil {
+		return err
+	}
+
+	eventPayload := map[string]interface{}{
+		"user_id":    userID,
+		"email":      user.Email,
+		"username":   user.Username,
+		"token":      token,
+		"expires_at": time.Now().Add(s.resetTokenTTL).Unix(),
+	}
+	payloadBytes, _ := json.Marshal(eventPayload)
+	msg := message.NewMessage(watermill.NewUUID(), payloadBytes)
+	msg.Metadata.Set("user_id", userID)
+	msg.SetContext(ctx)
+
+	if err := s.publisher.Publish("password_reset_requested", msg); err != nil {
+		return fmt.Errorf("failed to publish event: %w", err)
+	}
+	return nil
+}
+
+// ConfirmPasswordReset consumes the reset token, stores the new password and logs the user out everywhere
+func (s *authService) ConfirmPasswordReset(ctx context.Context, token, newPassword string) error {
+	userID, err := s.resetRepo.ConsumeResetToken(ctx, token)
+	if err != nil {
+		return ErrInvalidToken
+	}
+
+	id, err := strconv.Atoi(userID)
+	if err != nil {
+		return err
+	}
+
+	hashedPassword, err := s.passwords.Hash(newPassword)
+	if err != nil {
+		return err
+	}
+
+	if err := s.userRepo.UpdatePassword(ctx, uint(id), hashedPassword); err != nil {
+		return err
+	}
 
-	// Save Refresh Token
-	err = s.tokenRepo.SetRefreshToken(ctx, refreshToken, fmt.Sprintf("%d", userID), s.refreshTokenTTL)
+	_, err = s.RevokeAllSessions(ctx, userID)
+	return err
+}
+
+// generateTokens starts a new refresh token family for the client of the request
+func (s *authService) generateTokens(ctx context.Context, userID uint) (string, string, int64, error) {
+	client := grpcutil.ClientInfoFromContext(ctx)
+	now := time.Now()
+	family := &repository.Family{
+		ID:         shortuuid.New(),
+		UserID:     fmt.Sprintf("%d", userID),
+		Device:     client.Device,
+		IP:         client.IP,
+		UserAgent:  client.UserAgent,
+		CreatedAt:  now,
+		LastUsedAt: now,
+	}
+
+	accessToken, err := s.signAccessToken(ctx, userID, family.ID)
 	if err != nil {
 		return "", "", 0, err
 	}
 
+	refreshToken := generateOpaqueToken()
+	if err := s.tokenRepo.CreateFamily(ctx, family, refreshToken, s.refreshTokenTTL); err != nil {
+		return "", "", 0, err
+	}
+	s.notifyNewDevice(ctx, family)
+
 	return accessToken, refreshToken, int64(s.accessTokenTTL.Seconds()), nil
 }
 
+func (s *authService) signAccessToken(ctx context.Context, userID uint, sessionID string) (string, error) {
+	// Fetch User to get Role
+	user, err := s.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		return "", err
+	}
+	permissions, err := s.rolePermissions(ctx, user.Role)
+	if err != nil {
+		return "", err
+	}
+
+	claims := jwt.MapClaims{
+		"sub":            fmt.Sprintf("%d", userID),
+		"role":           user.Role,
+		"perms":          permissions,
+		"sid":            sessionID,
+		"email_verified": user.EmailVerified,
+		"exp":            time.Now().Add(s.accessTokenTTL).Unix(),
+		"iat":            time.Now().Unix(),
+	}
+	return s.keys.Sign(claims)
+}
+
+// generateOpaqueToken returns a random URL-safe token
+func generateOpaqueToken() string {
+	b := make([]byte, 32)
+	rand.Read(b)
+	return base64.URLEncoding.EncodeToString(b)
+}
+
 func (s *authService) CompensateUserCreation(ctx context.Context, userID string) error {
 	id, err := strconv.Atoi(userID)
 	if err != nil {
@@ -179,3 +652,7 @@ func (s *authService) CompensateUserCreation(ctx context.Context, userID string)
 	}
 	return s.userRepo.Delete(ctx, uint(id))
 }
+
+func (s *authService) JWKS(ctx context.Context) (*jwtutil.JWKS, error) {
+	return s.keys.JWKS(ctx)
+}
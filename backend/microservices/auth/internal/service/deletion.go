@@ -0,0 +1,198 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"time"
+
+	"github.com/ThreeDotsLabs/watermill"
+	"github.com/ThreeDotsLabs/watermill/message"
+	"github.com/username/progetto/auth/internal/model"
+	"gorm.io/gorm"
+)
+
+// deletionServices hold a replica of the user, each confirms the erasure on user_deleted_<service>
+var deletionServices = []string{"post", "social", "search", "messaging"}
+
+// Stale deletions are republished in batches of this size
+const deletionRetryBatchSize = 100
+
+var ErrDeletionNotFound = errors.New("account deletion not found")
+
+// DeleteAccount starts the erasure of the owner of the access token and returns the deletion ID.
+// The user is soft-deleted right away, so it can't log in while the other services catch up.
+func (s *authService) DeleteAccount(ctx context.Context, accessToken, password string) (string, error) {
+	user, err := s.authenticatedUser(ctx, accessToken)
+	if err != nil {
+		return "", err
+	}
+	ok, err := s.verifyPassword(ctx, user, password)
+	if err != nil {
+		return "", err
+	}
+	if !ok {
+		return "", ErrInvalidCredentials
+	}
+
+	now := time.Now()
+	deletion := &model.AccountDeletion{
+		ID:              watermill.NewUUID(),
+		UserID:          user.ID,
+		Status:          model.DeletionPending,
+		PendingServices: slices.Clone(deletionServices),
+		RequestedAt:     now,
+		LastAttemptAt:   now,
+	}
+	if err := s.deletionRepo.Start(ctx, deletion); err != nil {
+		return "", err
+	}
+	if _, err := s.RevokeAllSessions(ctx, fmt.Sprintf("%d", user.ID)); err != nil {
+		return "", err
+	}
+
+	// The retrier publishes again if this fails
+	if err := s.publishDeletionRequested(ctx, deletion); err != nil {
+		slog.WarnContext(ctx, "failed to publish deletion request, it will be retried", "error", err, "deletion_id", deletion.ID)
+	}
+	return deletion.ID, nil
+}
+
+// GetAccountDeletion returns a deletion of the owner of the access token.
+// The deletion revokes the sessions of the user, so the token is verified without the denylist:
+// the deletion can be followed with the token that started it until the token expires.
+func (s *authService) GetAccountDeletion(ctx context.Context, accessToken, deletionID string) (*model.AccountDeletion, error) {
+	claims, err := s.verifier.ParseToken(ctx, accessToken)
+	if err != nil {
+		return nil, ErrInvalidToken
+	}
+
+	deletion, err := s.deletionRepo.FindByID(ctx, deletionID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrDeletionNotFound
+		}
+		return nil, err
+	}
+	// Deletions of other users look missing
+	if fmt.Sprintf("%d", deletion.UserID) != claims.UserID {
+		return nil, ErrDeletionNotFound
+	}
+	return deletion, nil
+}
+
+// ConfirmUserDeletion records that a service erased its replica.
+// The last confirmation hard-deletes the user.
+func (s *authService) ConfirmUserDeletion(ctx context.Context, deletionID, service string) error {
+	deletion, err := s.deletionRepo.Confirm(ctx, deletionID, service)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrDeletionNotFound
+		}
+		return err
+	}
+
+	if deletion.Status != model.DeletionPending || len(deletion.PendingServices) > 0 {
+		return nil
+	}
+
+	if err := s.deletionRepo.Complete(ctx, deletion.ID); err != nil {
+		return err
+	}
+	slog.InfoContext(ctx, "account deletion completed", "deletion_id", deletion.ID, "user_id", deletion.UserID)
+
+	eventPayload := map[string]interface{}{
+		"deletion_id":  deletion.ID,
+		"user_id":      fmt.Sprintf("%d", deletion.UserID),
+		"completed_at": time.Now().Unix(),
+	}
+	payloadBytes, _ := json.Marshal(eventPayload)
+	msg := message.NewMessage(watermill.NewUUID(), payloadBytes)
+	msg.Metadata.Set("user_id", fmt.Sprintf("%d", deletion.UserID))
+	msg.SetContext(ctx)
+
+	if err := s.publisher.Publish("user_deletion_completed", msg); err != nil {
+		return fmt.Errorf("failed to publish event: %w", err)
+	}
+	return nil
+}
+
+// RetryAccountDeletions publishes again the deletions still waiting for a service after retryAfter
+func (s *authService) RetryAccountDeletions(ctx context.Context, retryAfter time.Duration) (int, error) {
+	deletions, err := s.deletionRepo.FindStale(ctx, time.Now().Add(-retryAfter), deletionRetryBatchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	retried := 0
+	for i := range deletions {
+		deletion := &deletions[i]
+		if err := s.publishDeletionRequested(ctx, deletion); err != nil {
+			slog.ErrorContext(ctx, "failed to retry account deletion", "error", err, "deletion_id", deletion.ID)
+			continue
+		}
+		slog.WarnContext(ctx, "retrying account deletion", "deletion_id", deletion.ID, "pending", deletion.PendingServices, "attempts", deletion.Attempts)
+		retried++
+	}
+	return retried, nil
+}
+
+// publishDeletionRequested asks every service to erase the user; services that already did must confirm again
+func (s *authService) publishDeletionRequested(ctx context.Context, deletion *model.AccountDeletion) error {
+	if err := s.deletionRepo.RecordAttempt(ctx, deletion.ID); err != nil {
+		return err
+	}
+
+	userID := fmt.Sprintf("%d", deletion.UserID)
+	eventPayload := map[string]interface{}{
+		"deletion_id":  deletion.ID,
+		"user_id":      userID,
+		"requested_at": deletion.RequestedAt.Unix(),
+	}
+	payloadBytes, _ := json.Marshal(eventPayload)
+	msg := message.NewMessage(watermill.NewUUID(), payloadBytes)
+	msg.Metadata.Set("user_id", userID)
+	msg.SetContext(ctx)
+
+	if err := s.publisher.Publish("user_deletion_requested", msg); err != nil {
+		return fmt.Errorf("failed to publish event: %w", err)
+	}
+	return nil
+}
+
+// AccountDeletionRetrier periodically republishes deletions that some service did not confirm
+type AccountDeletionRetrier struct {
+	svc        AuthService
+	interval   time.Duration
+	retryAfter time.Duration
+	logger     *slog.Logger
+}
+
+func NewAccountDeletionRetrier(svc AuthService, interval, retryAfter time.Duration) *AccountDeletionRetrier {
+	return &AccountDeletionRetrier{
+		svc:        svc,
+		interval:   interval,
+		retryAfter: retryAfter,
+		logger:     slog.Default().With("component", "account_deletion_retrier"),
+	}
+}
+
+// Run retries stale deletions until the context is cancelled
+func (r *AccountDeletionRetrier) Run(ctx context.Context) {
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := r.svc.RetryAccountDeletions(ctx, r.retryAfter); err != nil {
+				r.logger.ErrorContext(ctx, "failed to retry account deletions", "error", err)
+			}
+		}
+	}
+}
@@ -0,0 +1,249 @@
+package service
+
+import (
+	"context"
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/lithammer/shortuuid/v3"
+	"github.com/username/progetto/auth/internal/encryption"
+	"github.com/username/progetto/auth/internal/model"
+	"github.com/username/progetto/auth/internal/repository"
+	"github.com/username/progetto/shared/pkg/jwtutil"
+)
+
+const (
+	// New keys are published this long before they sign anything,
+	// so every JWKS cache has picked them up by the time tokens carry their kid.
+	keyPublishDelay = 10 * time.Minute
+	// Retired keys stay published well beyond the lifetime of the tokens they signed
+	keyRetention = 24 * time.Hour
+	// How often the key set is reloaded, which also picks up keys rotated by other replicas
+	keyCheckInterval = time.Minute
+)
+
+var ErrNoSigningKey = errors.New("no signing key available")
+
+type signingKey struct {
+	id         string
+	method     jwt.SigningMethod
+	privateKey crypto.Signer
+	createdAt  time.Time
+}
+
+// KeyManager owns the access token signing keys and rotates them on a schedule.
+type KeyManager struct {
+	repo             repository.SigningKeyRepository
+	cipher           *encryption.Cipher
+	algorithm        string
+	rotationInterval time.Duration
+	logger           *slog.Logger
+
+	mu   sync.RWMutex
+	keys []signingKey // newest first
+}
+
+// NewKeyManager creates a manager signing with RS256 or EdDSA keys.
+// Private keys are encrypted with cipher before they are stored.
+func NewKeyManager(repo repository.SigningKeyRepository, cipher *encryption.Cipher, algorithm string, rotationInterval time.Duration) (*KeyManager, error) {
+	if algorithm != "RS256" && algorithm != "EdDSA" {
+		return nil, fmt.Errorf("unsupported signing algorithm %q", algorithm)
+	}
+	return &KeyManager{
+		repo:             repo,
+		cipher:           cipher,
+		algorithm:        algorithm,
+		rotationInterval: rotationInterval,
+		logger:           slog.Default().With("component", "key_manager"),
+	}, nil
+}
+
+// Rotate generates a new key when the newest one is older than the rotation interval
+// and drops keys nobody can still hold tokens for.
+func (m *KeyManager) Rotate(ctx context.Context) error {
+	if err := m.load(ctx); err != nil {
+		return err
+	}
+
+	m.mu.RLock()
+	due := len(m.keys) == 0 || time.Since(m.keys[0].createdAt) >= m.rotationInterval
+	m.mu.RUnlock()
+
+	if due {
+		key, err := m.generate()
+		if err != nil {
+			return fmt.Errorf("failed to generate signing key: %w", err)
+		}
+		if err := m.repo.Create(ctx, key); err != nil {
+			return fmt.Errorf("failed to store signing key: %w", err)
+		}
+		m.logger.InfoContext(ctx, "generated new signing key", "kid", key.ID, "alg", key.Algorithm)
+	}
+
+	expiredBefore := time.Now().Add(-(m.rotationInterval + keyPublishDelay + keyRetention))
+	if err := m.repo.DeleteCreatedBefore(ctx, expiredBefore); err != nil {
+		return fmt.Errorf("failed to delete expired signing keys: %w", err)
+	}
+
+	return m.load(ctx)
+}
+
+// Run rotates keys until the context is cancelled
+func (m *KeyManager) Run(ctx context.Context) {
+	ticker := time.NewTicker(keyCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := m.Rotate(ctx); err != nil {
+				m.logger.ErrorContext(ctx, "failed to rotate signing keys", "error", err)
+			}
+		}
+	}
+}
+
+// Sign signs the claims with the active key and sets its kid header
+func (m *KeyManager) Sign(claims jwt.Claims) (string, error) {
+	key, err := m.activeKey()
+	if err != nil {
+		return "", err
+	}
+
+	token := jwt.NewWithClaims(key.method, claims)
+	token.Header["kid"] = key.id
+	return token.SignedString(key.privateKey)
+}
+
+// JWKS returns the public part of every published key
+func (m *KeyManager) JWKS(ctx context.Context) (*jwtutil.JWKS, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	jwks := &jwtutil.JWKS{Keys: make([]jwtutil.JWK, 0, len(m.keys))}
+	for _, key := range m.keys {
+		jwk, err := jwtutil.NewJWK(key.id, key.privateKey.Public())
+		if err != nil {
+			return nil, err
+		}
+		jwks.Keys = append(jwks.Keys, jwk)
+	}
+	return jwks, nil
+}
+
+// activeKey returns the newest key that has been published long enough.
+// Right after the first start only unpublished keys exist, so the newest one is used.
+func (m *KeyManager) activeKey() (signingKey, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if len(m.keys) == 0 {
+		return signingKey{}, ErrNoSigningKey
+	}
+	for _, key := range m.keys {
+		if time.Since(key.createdAt) >= keyPublishDelay {
+			return key, nil
+		}
+	}
+	return m.keys[0], nil
+}
+
+func (m *KeyManager) load(ctx context.Context) error {
+	stored, err := m.repo.List(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to list signing keys: %w", err)
+	}
+
+	keys := make([]signingKey, 0, len(stored))
+	for _, k := range stored {
+		privateKey, err := m.decodePrivateKey(k)
+		if err != nil {
+			m.logger.ErrorContext(ctx, "skipping unreadable signing key", "kid", k.ID, "error", err)
+			continue
+		}
+		method := jwt.GetSigningMethod(k.Algorithm)
+		if method == nil {
+			m.logger.ErrorContext(ctx, "skipping signing key with unknown algorithm", "kid", k.ID, "alg", k.Algorithm)
+			continue
+		}
+		keys = append(keys, signingKey{
+			id:         k.ID,
+			method:     method,
+			privateKey: privateKey,
+			createdAt:  k.CreatedAt,
+		})
+	}
+
+	m.mu.Lock()
+	m.keys = keys
+	m.mu.Unlock()
+	return nil
+}
+
+func (m *KeyManager) generate() (*model.SigningKey, error) {
+	var privateKey crypto.Signer
+	var err error
+	switch m.algorithm {
+	case "RS256":
+		privateKey, err = rsa.GenerateKey(rand.Reader, 2048)
+	case "EdDSA":
+		_, privateKey, err = ed25519.GenerateKey(rand.Reader)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	der, err := x509.MarshalPKCS8PrivateKey(privateKey)
+	if err != nil {
+		return nil, err
+	}
+
+	encrypted, err := m.cipher.Encrypt(string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})))
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.SigningKey{
+		ID:         shortuuid.New(),
+		Algorithm:  m.algorithm,
+		PrivateKey: encrypted,
+		CreatedAt:  time.Now(),
+	}, nil
+}
+
+// decodePrivateKey decrypts and parses a stored key
+func (m *KeyManager) decodePrivateKey(k model.SigningKey) (crypto.Signer, error) {
+	decrypted, err := m.cipher.Decrypt(k.PrivateKey)
+	if err != nil {
+		return nil, err
+	}
+	return parsePrivateKey(decrypted)
+}
+
+func parsePrivateKey(encoded string) (crypto.Signer, error) {
+	block, _ := pem.Decode([]byte(encoded))
+	if block == nil {
+		return nil, errors.New("invalid PEM block")
+	}
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+	signer, ok := key.(crypto.Signer)
+	if !ok {
+		return nil, fmt.Errorf("unsupported private key type %T", key)
+	}
+	return signer, nil
+}
@@ -0,0 +1,131 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/ThreeDotsLabs/watermill"
+	"github.com/ThreeDotsLabs/watermill/message"
+	"github.com/username/progetto/shared/pkg/grpcutil"
+)
+
+const (
+	// Failures are counted over a sliding window
+	loginFailureWindow = 15 * time.Minute
+	// Failures allowed per email before the account is locked
+	maxEmailFailures = 5
+	// Failures allowed per client IP, higher because of NATs and shared networks
+	maxIPFailures = 20
+	// Lock duration doubles from loginLockBase up to loginLockMax
+	loginLockBase = time.Minute
+	loginLockMax  = time.Hour
+	// Lock levels are forgotten after a day without locks
+	loginLockLevelTTL = 24 * time.Hour
+)
+
+// AccountLockedError is returned by Login while the email or the client IP is locked
+type AccountLockedError struct {
+	RetryAfter time.Duration
+}
+
+func (e *AccountLockedError) Error() string {
+	return fmt.Sprintf("account temporarily locked, retry in %s", e.RetryAfter.Round(time.Second))
+}
+
+// lockBackoff returns loginLockBase * 2^(level-1), capped to loginLockMax
+func lockBackoff(level int64) time.Duration {
+	duration := loginLockBase
+	for i := int64(1); i < level && duration < loginLockMax; i++ {
+		duration *= 2
+	}
+	if duration > loginLockMax {
+		duration = loginLockMax
+	}
+	return duration
+}
+
+func emailSubject(email string) string {
+	return "email:" + strings.ToLower(email)
+}
+
+func ipSubject(ip string) string {
+	return "ip:" + ip
+}
+
+// checkLoginLock returns an AccountLockedError if the email or the client IP is locked
+func (s *authService) checkLoginLock(ctx context.Context, email string) error {
+	subjects := []string{emailSubject(email)}
+	if ip := grpcutil.ClientInfoFromContext(ctx).IP; ip != "" {
+		subjects = append(subjects, ipSubject(ip))
+	}
+
+	for _, subject := range subjects {
+		retryAfter, err := s.attemptRepo.LockedFor(ctx, subject)
+		if err != nil {
+			return err
+		}
+		if retryAfter > 0 {
+			return &AccountLockedError{RetryAfter: retryAfter}
+		}
+	}
+	return nil
+}
+
+// recordLoginFailure counts the failure and locks the email or the IP once over the limit.
+// userID is empty when the email does not belong to any account.
+func (s *authService) recordLoginFailure(ctx context.Context, email, userID string) error {
+	ip := grpcutil.ClientInfoFromContext(ctx).IP
+	if ip != "" {
+		failures, err := s.attemptRepo.RecordFailure(ctx, ipSubject(ip), loginFailureWindow)
+		if err != nil {
+			return err
+		}
+		if failures >= maxIPFailures {
+			duration, _, err := s.attemptRepo.Lock(ctx, ipSubject(ip), lockBackoff, loginLockLevelTTL)
+			if err != nil {
+				return err
+			}
+			slog.WarnContext(ctx, "client ip locked after repeated login failures", "ip", ip, "duration", duration)
+		}
+	}
+
+	failures, err := s.attemptRepo.RecordFailure(ctx, emailSubject(email), loginFailureWindow)
+	if err != nil {
+		return err
+	}
+	if failures < maxEmailFailures {
+		return nil
+	}
+
+	duration, level, err := s.attemptRepo.Lock(ctx, emailSubject(email), lockBackoff, loginLockLevelTTL)
+	if err != nil {
+		return err
+	}
+	slog.WarnContext(ctx, "account locked after repeated login failures", "email", email, "duration", duration, "level", level)
+
+	// Only existing accounts are notified
+	if userID == "" {
+		return nil
+	}
+
+	eventPayload := map[string]interface{}{
+		"user_id":      userID,
+		"email":        email,
+		"ip":           ip,
+		"failures":     failures,
+		"locked_until": time.Now().Add(duration).Unix(),
+	}
+	payloadBytes, _ := json.Marshal(eventPayload)
+	msg := message.NewMessage(watermill.NewUUID(), payloadBytes)
+	msg.Metadata.Set("user_id", userID)
+	msg.SetContext(ctx)
+
+	if err := s.publisher.Publish("account_locked", msg); err != nil {
+		return fmt.Errorf("failed to publish event: %w", err)
+	}
+	return nil
+}
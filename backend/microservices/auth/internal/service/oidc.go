@@ -0,0 +1,200 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/lithammer/shortuuid/v3"
+	"github.com/username/progetto/auth/internal/model"
+	"github.com/username/progetto/auth/internal/oidc"
+	"github.com/username/progetto/auth/internal/repository"
+	"gorm.io/gorm"
+)
+
+// The user has this long to complete the login at the provider
+const oidcStateTTL = 10 * time.Minute
+
+var (
+	ErrUnknownProvider = errors.New("unknown identity provider")
+	// ErrEmailNotVerified is returned when the provider email matches an account but is not verified,
+	// linking on an unverified email would let anyone take over the account.
+	ErrEmailNotVerified = errors.New("identity provider email not verified")
+	// ErrAccountNotVerified is returned when the provider email matches an account that never verified it:
+	// whoever registered it may not own the address, and would keep its password after the link.
+	ErrAccountNotVerified = errors.New("account email not verified")
+)
+
+var usernameInvalidChars = regexp.MustCompile(`[^a-z0-9_]+`)
+
+// OIDCAuthURL starts a login at the provider, returning the URL to redirect the user to and the state
+func (s *authService) OIDCAuthURL(ctx context.Context, providerName string) (string, string, error) {
+	provider, ok := s.oidcProviders[providerName]
+	if !ok {
+		return "", "", ErrUnknownProvider
+	}
+
+	codeVerifier, err := oidc.GenerateCodeVerifier()
+	if err != nil {
+		return "", "", err
+	}
+	state := generateOpaqueToken()
+	nonce := generateOpaqueToken()
+
+	authURL, err := provider.AuthCodeURL(ctx, state, nonce, codeVerifier)
+	if err != nil {
+		return "", "", err
+	}
+
+	data := repository.OIDCState{Provider: providerName, CodeVerifier: codeVerifier, Nonce: nonce}
+	if err := s.oidcStateRepo.SaveState(ctx, state, data, oidcStateTTL); err != nil {
+		return "", "", err
+	}
+	return authURL, state, nil
+}
+
+// OIDCCallback completes a provider login. The identity is linked to an existing user
+// with the same verified email, or a new user is provisioned through the user_created saga.
+// Like Login it only returns a challenge token when the user has 2FA enabled.
+func (s *authService) OIDCCallback(ctx context.Context, providerName, code, state string) (string, string, string, int64, string, error) {
+	data, err := s.oidcStateRepo.ConsumeState(ctx, state)
+	if err != nil || data.Provider != providerName {
+		return "", "", "", 0, "", ErrInvalidToken
+	}
+
+	provider, ok := s.oidcProviders[providerName]
+	if !ok {
+		return "", "", "", 0, "", ErrUnknownProvider
+	}
+
+	rawIDToken, err := provider.Exchange(ctx, code, data.CodeVerifier)
+	if err != nil {
+		slog.WarnContext(ctx, "oidc code exchange failed", "provider", providerName, "error", err)
+		return "", "", "", 0, "", ErrInvalidToken
+	}
+
+	claims, err := provider.VerifyIDToken(ctx, rawIDToken, data.Nonce)
+	if err != nil {
+		slog.WarnContext(ctx, "oidc id token rejected", "provider", providerName, "error", err)
+		return "", "", "", 0, "", ErrInvalidToken
+	}
+
+	user, err := s.resolveIdentity(ctx, providerName, claims)
+	if err != nil {
+		return "", "", "", 0, "", err
+	}
+	if err := s.checkSuspension(ctx, user.ID); err != nil {
+		return "", "", "", 0, "", err
+	}
+	userID := fmt.Sprintf("%d", user.ID)
+
+	challengeToken, err := s.startTwoFactorChallenge(ctx, user.ID)
+	if err != nil {
+		return "", "", "", 0, "", err
+	}
+	if challengeToken != "" {
+		return userID, "", "", 0, challengeToken, nil
+	}
+
+	accessToken, refreshToken, expiresIn, err := s.generateTokens(ctx, user.ID)
+	if err != nil {
+		return "", "", "", 0, "", err
+	}
+	return userID, accessToken, refreshToken, expiresIn, "", nil
+}
+
+// resolveIdentity finds the user of a provider identity, linking or provisioning one on first login
+func (s *authService) resolveIdentity(ctx context.Context, providerName string, claims *oidc.IDTokenClaims) (*model.User, error) {
+	identity, err := s.identityRepo.FindByProviderSubject(ctx, providerName, claims.Subject)
+	if err == nil {
+		return s.userRepo.FindByID(ctx, identity.UserID)
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	if claims.Email == "" {
+		return nil, ErrEmailNotVerified
+	}
+
+	user, err := s.userRepo.FindByEmail(ctx, claims.Email)
+	switch {
+	case err == nil:
+		if !claims.EmailVerified {
+			return nil, ErrEmailNotVerified
+		}
+		if !user.EmailVerified {
+			return nil, ErrAccountNotVerified
+		}
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		user, err = s.provisionUser(ctx, claims)
+		if err != nil {
+			return nil, err
+		}
+	default:
+		return nil, err
+	}
+
+	identity = &model.Identity{
+		UserID:   user.ID,
+		Provider: providerName,
+		Subject:  claims.Subject,
+		Email:    claims.Email,
+	}
+	if err := s.identityRepo.Create(ctx, identity); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// provisionUser creates the user of a first provider login.
+// It has a random password, so it can only log in through the provider until it resets it.
+func (s *authService) provisionUser(ctx context.Context, claims *oidc.IDTokenClaims) (*model.User, error) {
+	hashedPassword, err := s.passwords.Hash(generateOpaqueToken())
+	if err != nil {
+		return nil, err
+	}
+
+	user := &model.User{
+		Email:         claims.Email,
+		Password:      hashedPassword,
+		Username:      provisionedUsername(claims),
+		EmailVerified: claims.EmailVerified,
+	}
+	if claims.EmailVerified {
+		now := time.Now()
+		user.EmailVerifiedAt = &now
+	}
+
+	if err := s.userRepo.Create(ctx, user, userCreatedEvent); err != nil {
+		return nil, err
+	}
+
+	if !user.EmailVerified {
+		if err := s.sendVerificationEmail(ctx, user); err != nil {
+			slog.WarnContext(ctx, "failed to send verification email", "error", err, "user_id", user.ID)
+		}
+	}
+	return user, nil
+}
+
+// provisionedUsername derives a username from the provider claims with a random suffix, usernames are unique
+func provisionedUsername(claims *oidc.IDTokenClaims) string {
+	base := claims.PreferredUsername
+	if base == "" {
+		base, _, _ = strings.Cut(claims.Email, "@")
+	}
+	base = usernameInvalidChars.ReplaceAllString(strings.ToLower(base), "_")
+	base = strings.Trim(base, "_")
+	if len(base) > 20 {
+		base = base[:20]
+	}
+	if base == "" {
+		base = "user"
+	}
+	return base + "_" + shortuuid.New()[:8]
+}
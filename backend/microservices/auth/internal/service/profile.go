@@ -0,0 +1,178 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/ThreeDotsLabs/watermill"
+	"github.com/ThreeDotsLabs/watermill/message"
+	"github.com/username/progetto/auth/internal/model"
+	sharedmodel "github.com/username/progetto/shared/pkg/model"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrUserNotFound  = errors.New("user not found")
+	ErrUsernameTaken = errors.New("username already taken")
+	ErrEmailTaken    = errors.New("email already taken")
+)
+
+// ProfileUpdate holds the profile fields to change, nil fields are left untouched
+type ProfileUpdate struct {
+	DisplayName *string
+	Bio         *string
+	AvatarURL   *string
+	BookGenres  *[]string
+	FilmGenres  *[]string
+	MusicGenres *[]string
+}
+
+func (u ProfileUpdate) changes() map[string]interface{} {
+	changes := make(map[string]interface{})
+	if u.DisplayName != nil {
+		changes["display_name"] = *u.DisplayName
+	}
+	if u.Bio != nil {
+		changes["bio"] = *u.Bio
+	}
+	if u.AvatarURL != nil {
+		changes["avatar_url"] = *u.AvatarURL
+	}
+	if u.BookGenres != nil {
+		changes["book_genres"] = *u.BookGenres
+	}
+	if u.FilmGenres != nil {
+		changes["film_genres"] = *u.FilmGenres
+	}
+	if u.MusicGenres != nil {
+		changes["music_genres"] = *u.MusicGenres
+	}
+	return changes
+}
+
+func (s *authService) GetUser(ctx context.Context, userID string) (*model.User, error) {
+	id, err := strconv.Atoi(userID)
+	if err != nil {
+		return nil, ErrUserNotFound
+	}
+
+	user, err := s.userRepo.FindByID(ctx, uint(id))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrUserNotFound
+		}
+		return nil, err
+	}
+	return user, nil
+}
+
+// UpdateProfile changes the profile of the owner of the access token
+func (s *authService) UpdateProfile(ctx context.Context, accessToken string, update ProfileUpdate) (*model.User, error) {
+	user, err := s.authenticatedUser(ctx, accessToken)
+	if err != nil {
+		return nil, err
+	}
+
+	changes := update.changes()
+	if len(changes) == 0 {
+		return user, nil
+	}
+	return s.updateUser(ctx, user.ID, changes)
+}
+
+func (s *authService) ChangeUsername(ctx context.Context, accessToken, username string) (*model.User, error) {
+	user, err := s.authenticatedUser(ctx, accessToken)
+	if err != nil {
+		return nil, err
+	}
+	if user.Username == username {
+		return user, nil
+	}
+	return s.updateUser(ctx, user.ID, map[string]interface{}{"username": username})
+}
+
+// ChangeEmail starts an email change after checking the current password.
+// The new address gets a confirmation email, the current one stays in place until it is confirmed.
+func (s *authService) ChangeEmail(ctx context.Context, accessToken, newEmail, password string) (*model.User, error) {
+	user, err := s.authenticatedUser(ctx, accessToken)
+	if err != nil {
+		return nil, err
+	}
+
+	ok, err := s.verifyPassword(ctx, user, password)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, ErrInvalidCredentials
+	}
+	if user.Email == newEmail {
+		return user, nil
+	}
+
+	if _, err := s.userRepo.FindByEmail(ctx, newEmail); err == nil {
+		return nil, ErrEmailTaken
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	token := generateOpaqueToken()
+	if err := s.verifyRepo.SaveEmailChangeToken(ctx, token, fmt.Sprintf("%d", user.ID), newEmail, s.verifyTokenTTL); err != nil {
+		return nil, err
+	}
+	if err := s.publishVerificationEmail(ctx, user, newEmail, token); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// confirmEmailChange moves the user to the address confirmed by an email change token
+func (s *authService) confirmEmailChange(ctx context.Context, token string) error {
+	userID, email, err := s.verifyRepo.ConsumeEmailChangeToken(ctx, token)
+	if err != nil {
+		return ErrInvalidToken
+	}
+
+	id, err := strconv.Atoi(userID)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.updateUser(ctx, uint(id), map[string]interface{}{
+		"email":             email,
+		"email_verified":    true,
+		"email_verified_at": time.Now(),
+	})
+	return err
+}
+
+// updateUser saves the changes with the event carrying the new version of the user
+func (s *authService) updateUser(ctx context.Context, userID uint, changes map[string]interface{}) (*model.User, error) {
+	user, err := s.userRepo.Update(ctx, userID, changes, userUpdatedEvent)
+	if err != nil {
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			if _, ok := changes["email"]; ok {
+				return nil, ErrEmailTaken
+			}
+			return nil, ErrUsernameTaken
+		}
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrUserNotFound
+		}
+		return nil, err
+	}
+	return user, nil
+}
+
+// userUpdatedEvent carries the full state of the user, so replicas can replace theirs
+func userUpdatedEvent(user *model.User) (string, *message.Message) {
+	payloadBytes, _ := json.Marshal(sharedmodel.NewUserUpdatedEvent(user))
+	msg := message.NewMessage(watermill.NewUUID(), payloadBytes)
+	msg.Metadata.Set("user_id", fmt.Sprintf("%d", user.ID))
+	msg.Metadata.Set("version", strconv.FormatInt(user.Version, 10))
+	return "user_updated", msg
+}
@@ -0,0 +1,46 @@
+package service
+
+import (
+	"context"
+	"log/slog"
+	"time"
+)
+
+// UnverifiedUserPurger periodically removes accounts that never verified their email
+type UnverifiedUserPurger struct {
+	svc      AuthService
+	interval time.Duration
+	maxAge   time.Duration
+	logger   *slog.Logger
+}
+
+func NewUnverifiedUserPurger(svc AuthService, interval, maxAge time.Duration) *UnverifiedUserPurger {
+	return &UnverifiedUserPurger{
+		svc:      svc,
+		interval: interval,
+		maxAge:   maxAge,
+		logger:   slog.Default().With("component", "unverified_user_purger"),
+	}
+}
+
+// Run purges unverified users until the context is cancelled
+func (p *UnverifiedUserPurger) Run(ctx context.Context) {
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			purged, err := p.svc.PurgeUnverifiedUsers(ctx, p.maxAge)
+			if err != nil {
+				p.logger.ErrorContext(ctx, "failed to purge unverified users", "error", err)
+				continue
+			}
+			if purged > 0 {
+				p.logger.InfoContext(ctx, "purging unverified users", "count", purged)
+			}
+		}
+	}
+}
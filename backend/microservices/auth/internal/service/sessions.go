@@ -0,0 +1,100 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"sort"
+	"time"
+
+	"github.com/ThreeDotsLabs/watermill"
+	"github.com/ThreeDotsLabs/watermill/message"
+	"github.com/redis/go-redis/v9"
+	"github.com/username/progetto/auth/internal/repository"
+)
+
+// A device not used to log in for this long is new again
+const knownDeviceTTL = 365 * 24 * time.Hour
+
+var ErrSessionNotFound = errors.New("session not found")
+
+// ListSessions returns the sessions of the owner of the access token, most recently used first,
+// and the ID of the session of the access token itself
+func (s *authService) ListSessions(ctx context.Context, accessToken string) ([]repository.Family, string, error) {
+	claims, err := s.authenticate(ctx, accessToken)
+	if err != nil {
+		return nil, "", err
+	}
+
+	families, err := s.tokenRepo.ListFamilies(ctx, claims.UserID)
+	if err != nil {
+		return nil, "", err
+	}
+	sort.Slice(families, func(i, j int) bool {
+		return families[i].LastUsedAt.After(families[j].LastUsedAt)
+	})
+	return families, claims.SessionID, nil
+}
+
+// RevokeSession logs out one session of the owner of the access token
+func (s *authService) RevokeSession(ctx context.Context, accessToken, sessionID string) error {
+	claims, err := s.authenticate(ctx, accessToken)
+	if err != nil {
+		return err
+	}
+
+	family, err := s.tokenRepo.GetFamily(ctx, sessionID)
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return ErrSessionNotFound
+		}
+		return err
+	}
+	// Sessions of other users look missing
+	if family.UserID != claims.UserID {
+		return ErrSessionNotFound
+	}
+
+	if err := s.tokenRepo.DeleteFamily(ctx, family); err != nil {
+		return err
+	}
+	return s.denylist.Revoke(ctx, family.ID, s.accessTokenTTL)
+}
+
+// notifyNewDevice publishes new_device_login when the session comes from a device the user never logged in from.
+// It only warns on failure, the login already succeeded.
+func (s *authService) notifyNewDevice(ctx context.Context, family *repository.Family) {
+	if family.Device == "" && family.UserAgent == "" {
+		return
+	}
+
+	sum := sha256.Sum256([]byte(family.Device + "|" + family.UserAgent))
+	isNew, err := s.tokenRepo.RememberDevice(ctx, family.UserID, hex.EncodeToString(sum[:]), knownDeviceTTL)
+	if err != nil {
+		slog.WarnContext(ctx, "failed to check known devices", "error", err, "user_id", family.UserID)
+		return
+	}
+	if !isNew {
+		return
+	}
+
+	eventPayload := map[string]interface{}{
+		"user_id":      family.UserID,
+		"session_id":   family.ID,
+		"device":       family.Device,
+		"ip":           family.IP,
+		"user_agent":   family.UserAgent,
+		"logged_in_at": family.CreatedAt.Unix(),
+	}
+	payloadBytes, _ := json.Marshal(eventPayload)
+	msg := message.NewMessage(watermill.NewUUID(), payloadBytes)
+	msg.Metadata.Set("user_id", family.UserID)
+	msg.SetContext(ctx)
+
+	if err := s.publisher.Publish("new_device_login", msg); err != nil {
+		slog.WarnContext(ctx, "failed to publish new device login", "error", err, "user_id", family.UserID)
+	}
+}
@@ -0,0 +1,211 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base32"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/username/progetto/auth/internal/model"
+	"github.com/username/progetto/auth/internal/totp"
+	"gorm.io/gorm"
+)
+
+const (
+	challengeTokenTTL = 5 * time.Minute
+	// A challenge is dropped after this many wrong codes, forcing a new password login
+	maxTwoFactorAttempts = 5
+	recoveryCodeCount    = 10
+)
+
+var (
+	ErrTwoFactorAlreadyEnabled = errors.New("two-factor authentication already enabled")
+	ErrTwoFactorNotEnrolled    = errors.New("two-factor authentication not enrolled")
+	ErrInvalidTwoFactorCode    = errors.New("invalid two-factor code")
+)
+
+// EnrollTwoFactor stores a new pending secret and returns it with its provisioning URI
+func (s *authService) EnrollTwoFactor(ctx context.Context, accessToken string) (string, string, error) {
+	user, err := s.authenticatedUser(ctx, accessToken)
+	if err != nil {
+		return "", "", err
+	}
+
+	existing, err := s.twoFactorRepo.FindByUserID(ctx, user.ID)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return "", "", err
+	}
+	if existing != nil && existing.Enabled {
+		return "", "", ErrTwoFactorAlreadyEnabled
+	}
+
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		return "", "", err
+	}
+	encryptedSecret, err := s.secretCipher.Encrypt(secret)
+	if err != nil {
+		return "", "", err
+	}
+
+	if err := s.twoFactorRepo.SavePending(ctx, &model.TwoFactor{
+		UserID:          user.ID,
+		EncryptedSecret: encryptedSecret,
+	}); err != nil {
+		return "", "", err
+	}
+
+	return secret, totp.ProvisioningURI(s.totpIssuer, user.Email, secret), nil
+}
+
+// ConfirmTwoFactor enables 2FA with a first valid code and returns the recovery codes in clear, once
+func (s *authService) ConfirmTwoFactor(ctx context.Context, accessToken, code string) ([]string, error) {
+	user, err := s.authenticatedUser(ctx, accessToken)
+	if err != nil {
+		return nil, err
+	}
+
+	twoFactor, err := s.twoFactorRepo.FindByUserID(ctx, user.ID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrTwoFactorNotEnrolled
+		}
+		return nil, err
+	}
+	if twoFactor.Enabled {
+		return nil, ErrTwoFactorAlreadyEnabled
+	}
+
+	secret, err := s.secretCipher.Decrypt(twoFactor.EncryptedSecret)
+	if err != nil {
+		return nil, err
+	}
+	step, ok := totp.Validate(secret, code, time.Now())
+	if !ok {
+		return nil, ErrInvalidTwoFactorCode
+	}
+
+	codes := make([]string, recoveryCodeCount)
+	records := make([]model.RecoveryCode, recoveryCodeCount)
+	for i := range codes {
+		codes[i], err = generateRecoveryCode()
+		if err != nil {
+			return nil, err
+		}
+		records[i] = model.RecoveryCode{UserID: user.ID, CodeHash: hashRecoveryCode(codes[i])}
+	}
+
+	if err := s.twoFactorRepo.Enable(ctx, user.ID, step, records); err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+// VerifyTwoFactor completes a login started with a challenge token.
+// Wrong codes count as login failures, so they lock the account like wrong passwords.
+func (s *authService) VerifyTwoFactor(ctx context.Context, challengeToken, code string) (string, string, int64, error) {
+	userID, attempts, err := s.challengeRepo.RecordAttempt(ctx, challengeToken)
+	if err != nil {
+		return "", "", 0, ErrInvalidToken
+	}
+	if attempts > maxTwoFactorAttempts {
+		if err := s.challengeRepo.DeleteChallenge(ctx, challengeToken); err != nil {
+			return "", "", 0, err
+		}
+		return "", "", 0, ErrInvalidToken
+	}
+
+	id, err := strconv.Atoi(userID)
+	if err != nil {
+		return "", "", 0, err
+	}
+
+	user, err := s.userRepo.FindByID(ctx, uint(id))
+	if err != nil {
+		return "", "", 0, err
+	}
+	if err := s.checkLoginLock(ctx, user.Email); err != nil {
+		return "", "", 0, err
+	}
+
+	twoFactor, err := s.twoFactorRepo.FindByUserID(ctx, user.ID)
+	if err != nil {
+		return "", "", 0, err
+	}
+
+	valid, err := s.checkTwoFactorCode(ctx, twoFactor, code)
+	if err != nil {
+		return "", "", 0, err
+	}
+	if !valid {
+		if err := s.recordLoginFailure(ctx, user.Email, userID); err != nil {
+			return "", "", 0, err
+		}
+		return "", "", 0, ErrInvalidTwoFactorCode
+	}
+
+	if err := s.challengeRepo.DeleteChallenge(ctx, challengeToken); err != nil {
+		return "", "", 0, err
+	}
+	if err := s.attemptRepo.ClearFailures(ctx, emailSubject(user.Email)); err != nil {
+		return "", "", 0, err
+	}
+	return s.generateTokens(ctx, uint(id))
+}
+
+// startTwoFactorChallenge returns a challenge token if the user has 2FA enabled, an empty string otherwise
+func (s *authService) startTwoFactorChallenge(ctx context.Context, userID uint) (string, error) {
+	twoFactor, err := s.twoFactorRepo.FindByUserID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return "", nil
+		}
+		return "", err
+	}
+	if !twoFactor.Enabled {
+		return "", nil
+	}
+
+	challengeToken := generateOpaqueToken()
+	if err := s.challengeRepo.SaveChallenge(ctx, challengeToken, fmt.Sprintf("%d", userID), challengeTokenTTL); err != nil {
+		return "", err
+	}
+	return challengeToken, nil
+}
+
+// checkTwoFactorCode accepts a TOTP code not used before or an unused recovery code
+func (s *authService) checkTwoFactorCode(ctx context.Context, twoFactor *model.TwoFactor, code string) (bool, error) {
+	secret, err := s.secretCipher.Decrypt(twoFactor.EncryptedSecret)
+	if err != nil {
+		return false, err
+	}
+
+	if step, ok := totp.Validate(secret, code, time.Now()); ok {
+		return s.twoFactorRepo.UseStep(ctx, twoFactor.UserID, step)
+	}
+
+	return s.twoFactorRepo.UseRecoveryCode(ctx, twoFactor.UserID, hashRecoveryCode(code))
+}
+
+// generateRecoveryCode returns a code like ABCDE-FGHIJ
+func generateRecoveryCode() (string, error) {
+	b := make([]byte, 10)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	code := base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString(b)[:10]
+	return code[:5] + "-" + code[5:], nil
+}
+
+// Recovery codes are random, so a fast hash is enough to keep them unusable if the table leaks
+func hashRecoveryCode(code string) string {
+	normalized := strings.ToUpper(strings.ReplaceAll(strings.TrimSpace(code), "-", ""))
+	sum := sha256.Sum256([]byte(normalized))
+	return hex.EncodeToString(sum[:])
+}
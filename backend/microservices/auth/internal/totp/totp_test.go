@@ -0,0 +1,35 @@
+package totp
+
+import (
+	"testing"
+	"time"
+)
+
+// RFC 6238 appendix B test vectors (SHA1), truncated to 6 digits
+func TestValidate(t *testing.T) {
+	secret := encoding.EncodeToString([]byte("12345678901234567890"))
+
+	tests := []struct {
+		name string
+		unix int64
+		code string
+		want bool
+	}{
+		{name: "t=59", unix: 59, code: "287082", want: true},
+		{name: "t=1111111109", unix: 1111111109, code: "081804", want: true},
+		{name: "t=1234567890", unix: 1234567890, code: "005924", want: true},
+		{name: "t=2000000000", unix: 2000000000, code: "279037", want: true},
+		{name: "previous step accepted", unix: 59 + period, code: "287082", want: true},
+		{name: "old code rejected", unix: 59 + 3*period, code: "287082", want: false},
+		{name: "wrong code", unix: 59, code: "123456", want: false},
+		{name: "wrong length", unix: 59, code: "28708", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, got := Validate(secret, tt.code, time.Unix(tt.unix, 0)); got != tt.want {
+				t.Errorf("Validate() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -2,6 +2,9 @@ package validator
 
 import (
 	"errors"
+	"fmt"
+	"reflect"
+	"strings"
 	"unicode"
 
 	"github.com/go-playground/validator/v10"
@@ -9,17 +12,47 @@ import (
 
 var (
 	validate *validator.Validate
+
+	ErrWeakPassword = errors.New("password must contain at least one uppercase letter, one number, and one special character")
 )
 
 func init() {
 	validate = validator.New()
+	// Violations are reported with the field names of the API, e.g. new_password
+	validate.RegisterTagNameFunc(func(field reflect.StructField) string {
+		name, _, _ := strings.Cut(field.Tag.Get("field"), ",")
+		if name == "" {
+			return field.Name
+		}
+		return name
+	})
+}
+
+// FieldViolation is a rule a request field breaks. Field is the API name of the field,
+// with the index for list items, e.g. book_genres[2].
+type FieldViolation struct {
+	Field       string
+	Description string
+}
+
+// ValidationError lists every violation of a request, so clients can report them all at once
+type ValidationError struct {
+	Violations []FieldViolation
+}
+
+func (e *ValidationError) Error() string {
+	messages := make([]string, 0, len(e.Violations))
+	for _, v := range e.Violations {
+		messages = append(messages, v.Field+": "+v.Description)
+	}
+	return "invalid request: " + strings.Join(messages, "; ")
 }
 
 // RegistrationRequest helper for validation tags
 type RegistrationRequest struct {
-	Email    string `validate:"required,email"`
-	Password string `validate:"required,min=8"`
-	Username string `validate:"required,min=3,max=32"`
+	Email    string `field:"email" validate:"required,email"`
+	Password string `field:"password" validate:"required,min=8"`
+	Username string `field:"username" validate:"required,min=3,max=32"`
 }
 
 // ValidateRegister checks email format and password complexity
@@ -31,16 +64,122 @@ func ValidateRegister(email, password, username string) error {
 		Username: username,
 	}
 
-	if err := validate.Struct(req); err != nil {
-		return err
-	}
+	violations := violationsOf(validate.Struct(req))
 
 	// 2. Custom Password Complexity Check
+	violations = checkPasswordComplexity(violations, "password", password)
+
+	return toError(violations)
+}
+
+// PasswordResetRequest helper for validation tags
+type PasswordResetRequest struct {
+	Token    string `field:"token" validate:"required"`
+	Password string `field:"new_password" validate:"required,min=8"`
+}
+
+// ValidatePasswordReset checks the reset token presence and the new password complexity
+func ValidatePasswordReset(token, password string) error {
+	req := PasswordResetRequest{
+		Token:    token,
+		Password: password,
+	}
+
+	violations := violationsOf(validate.Struct(req))
+	violations = checkPasswordComplexity(violations, "new_password", password)
+
+	return toError(violations)
+}
+
+// ProfileRequest helper for validation tags
+type ProfileRequest struct {
+	DisplayName string   `field:"display_name" validate:"max=50"`
+	Bio         string   `field:"bio" validate:"max=300"`
+	AvatarURL   string   `field:"avatar_url" validate:"omitempty,url,max=2048"`
+	BookGenres  []string `field:"book_genres" validate:"max=10,dive,required,max=32"`
+	FilmGenres  []string `field:"film_genres" validate:"max=10,dive,required,max=32"`
+	MusicGenres []string `field:"music_genres" validate:"max=10,dive,required,max=32"`
+}
+
+// ValidateProfile checks the profile fields, empty values clear the field
+func ValidateProfile(req ProfileRequest) error {
+	return toError(violationsOf(validate.Struct(req)))
+}
+
+// ValidateUsername applies the registration rules to a new username
+func ValidateUsername(username string) error {
+	return toError(varViolations("username", validate.Var(username, "required,min=3,max=32")))
+}
+
+// ValidateEmail checks the format of a new email
+func ValidateEmail(email string) error {
+	return toError(varViolations("new_email", validate.Var(email, "required,email")))
+}
+
+// violationsOf converts the errors of validate.Struct, named after the field tag
+func violationsOf(err error) []FieldViolation {
+	var errs validator.ValidationErrors
+	if !errors.As(err, &errs) {
+		return nil
+	}
+	violations := make([]FieldViolation, 0, len(errs))
+	for _, fe := range errs {
+		// The namespace starts with the struct name, e.g. ProfileRequest.book_genres[2]
+		_, field, _ := strings.Cut(fe.Namespace(), ".")
+		violations = append(violations, FieldViolation{Field: field, Description: describe(fe)})
+	}
+	return violations
+}
+
+// varViolations converts the errors of validate.Var, which don't know the field name
+func varViolations(field string, err error) []FieldViolation {
+	violations := violationsOf(err)
+	for i := range violations {
+		violations[i].Field = field
+	}
+	return violations
+}
+
+// checkPasswordComplexity adds the complexity violation, unless the password already breaks a rule
+func checkPasswordComplexity(violations []FieldViolation, field, password string) []FieldViolation {
+	for _, v := range violations {
+		if v.Field == field {
+			return violations
+		}
+	}
 	if !isComplexPassword(password) {
-		return errors.New("password must contain at least one uppercase letter, one number, and one special character")
+		violations = append(violations, FieldViolation{Field: field, Description: ErrWeakPassword.Error()})
 	}
+	return violations
+}
 
-	return nil
+func toError(violations []FieldViolation) error {
+	if len(violations) == 0 {
+		return nil
+	}
+	return &ValidationError{Violations: violations}
+}
+
+// describe turns a failed rule into a message for the client
+func describe(fe validator.FieldError) string {
+	unit := "characters"
+	if fe.Kind() == reflect.Slice {
+		unit = "items"
+	}
+	switch fe.Tag() {
+	case "required":
+		return "is required"
+	case "email":
+		return "must be a valid email address"
+	case "url":
+		return "must be a valid URL"
+	case "min":
+		return fmt.Sprintf("must be at least %s %s", fe.Param(), unit)
+	case "max":
+		return fmt.Sprintf("must be at most %s %s", fe.Param(), unit)
+	default:
+		return fmt.Sprintf("must satisfy %s", fe.Tag())
+	}
 }
 
 func isComplexPassword(pass string) bool {
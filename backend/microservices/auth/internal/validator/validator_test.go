@@ -1,6 +1,8 @@
 package validator
 
 import (
+	"errors"
+	"slices"
 	"testing"
 )
 
@@ -71,3 +73,138 @@ func TestValidateRegister(t *testing.T) {
 		})
 	}
 }
+
+func TestValidatePasswordReset(t *testing.T) {
+	tests := []struct {
+		name     string
+		token    string
+		password string
+		wantErr  bool
+	}{
+		{
+			name:     "valid request",
+			token:    "reset-token",
+			password: "Password123!",
+			wantErr:  false,
+		},
+		{
+			name:     "missing token",
+			token:    "",
+			password: "Password123!",
+			wantErr:  true,
+		},
+		{
+			name:     "short password",
+			token:    "reset-token",
+			password: "Pass1!",
+			wantErr:  true,
+		},
+		{
+			name:     "no special char in password",
+			token:    "reset-token",
+			password: "Password123",
+			wantErr:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := ValidatePasswordReset(tt.token, tt.password); (err != nil) != tt.wantErr {
+				t.Errorf("ValidatePasswordReset() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateProfile(t *testing.T) {
+	tests := []struct {
+		name    string
+		req     ProfileRequest
+		wantErr bool
+	}{
+		{
+			name: "valid profile",
+			req: ProfileRequest{
+				DisplayName: "Jane",
+				AvatarURL:   "https://cdn.example.com/jane.png",
+				BookGenres:  []string{"fantasy", "noir"},
+			},
+			wantErr: false,
+		},
+		{
+			name:    "empty profile clears fields",
+			req:     ProfileRequest{},
+			wantErr: false,
+		},
+		{
+			name:    "invalid avatar url",
+			req:     ProfileRequest{AvatarURL: "not a url"},
+			wantErr: true,
+		},
+		{
+			name:    "empty genre",
+			req:     ProfileRequest{MusicGenres: []string{"jazz", ""}},
+			wantErr: true,
+		},
+		{
+			name:    "too many genres",
+			req:     ProfileRequest{FilmGenres: []string{"a", "b", "c", "d", "e", "f", "g", "h", "i", "j", "k"}},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := ValidateProfile(tt.req); (err != nil) != tt.wantErr {
+				t.Errorf("ValidateProfile() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidationErrorFields(t *testing.T) {
+	tests := []struct {
+		name   string
+		err    error
+		fields []string
+	}{
+		{
+			name:   "register reports every field",
+			err:    ValidateRegister("invalid-email", "password", "tu"),
+			fields: []string{"email", "username", "password"},
+		},
+		{
+			name:   "password reset uses the API name",
+			err:    ValidatePasswordReset("", "Pass1!"),
+			fields: []string{"token", "new_password"},
+		},
+		{
+			name:   "genre items are indexed",
+			err:    ValidateProfile(ProfileRequest{MusicGenres: []string{"jazz", ""}}),
+			fields: []string{"music_genres[1]"},
+		},
+		{
+			name:   "single value",
+			err:    ValidateEmail("invalid-email"),
+			fields: []string{"new_email"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var validationErr *ValidationError
+			if !errors.As(tt.err, &validationErr) {
+				t.Fatalf("error = %v, want a *ValidationError", tt.err)
+			}
+			fields := make([]string, 0, len(validationErr.Violations))
+			for _, v := range validationErr.Violations {
+				fields = append(fields, v.Field)
+			}
+			slices.Sort(fields)
+			want := slices.Sorted(slices.Values(tt.fields))
+			if !slices.Equal(fields, want) {
+				t.Errorf("violation fields = %v, want %v", fields, want)
+			}
+		})
+	}
+}
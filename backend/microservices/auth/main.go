@@ -9,16 +9,22 @@ import (
 	"syscall"
 
 	"github.com/username/progetto/auth/internal/config"
+	"github.com/username/progetto/auth/internal/encryption"
 	"github.com/username/progetto/auth/internal/events"
 	"github.com/username/progetto/auth/internal/handler"
-	"github.com/username/progetto/auth/internal/model"
+	"github.com/username/progetto/auth/internal/migrations"
+	"github.com/username/progetto/auth/internal/oidc"
+	"github.com/username/progetto/auth/internal/password"
 	"github.com/username/progetto/auth/internal/repository"
 	"github.com/username/progetto/auth/internal/service"
 	authv1 "github.com/username/progetto/proto/gen/go/auth/v1"
 	"github.com/username/progetto/shared/pkg/database/postgres"
 	"github.com/username/progetto/shared/pkg/database/redis"
 	"github.com/username/progetto/shared/pkg/grpcutil"
+	"github.com/username/progetto/shared/pkg/jwtutil"
 	"github.com/username/progetto/shared/pkg/observability"
+	"github.com/username/progetto/shared/pkg/outbox"
+	"github.com/username/progetto/shared/pkg/rbac"
 	"github.com/username/progetto/shared/pkg/watermillutil"
 	"google.golang.org/grpc/reflection"
 )
@@ -54,7 +60,13 @@ func main() {
 		os.Exit(1)
 	}
 
-	if err := postgres.AutoMigrate(db, &model.User{}); err != nil {
+	// Versioned migrations, replicas starting together wait on the migration lock
+	migrator, err := postgres.NewMigrator(db, migrations.FS, migrations.Shared...)
+	if err != nil {
+		slog.Error("failed to load migrations", "error", err)
+		os.Exit(1)
+	}
+	if _, err := migrator.Up(context.Background()); err != nil {
 		slog.Error("failed to migrate db", "error", err)
 		os.Exit(1)
 	}
@@ -77,7 +89,75 @@ func main() {
 	// 4. Wiring
 	userRepo := repository.NewPostgresRepository(db)
 	tokenRepo := repository.NewRedisRepository(rdb)
-	authSvc := service.NewAuthService(userRepo, tokenRepo, publisher, cfg.JwtSecret)
+	resetRepo := repository.NewPasswordResetRepository(rdb)
+	verifyRepo := repository.NewEmailVerificationRepository(rdb)
+	twoFactorRepo := repository.NewTwoFactorRepository(db)
+	challengeRepo := repository.NewTwoFactorChallengeRepository(rdb)
+	attemptRepo := repository.NewLoginAttemptRepository(rdb)
+	identityRepo := repository.NewIdentityRepository(db)
+	oidcStateRepo := repository.NewOIDCStateRepository(rdb)
+	deletionRepo := repository.NewAccountDeletionRepository(db)
+	roleRepo := repository.NewRoleRepository(db)
+	moderationRepo := repository.NewModerationRepository(db)
+	denylist := jwtutil.NewRedisDenylist(rdb)
+
+	// Default roles, changes made in the database are kept
+	if err := roleRepo.Seed(context.Background(), rbac.DefaultPermissions, rbac.DefaultRoles); err != nil {
+		slog.Error("failed to seed roles", "error", err)
+		os.Exit(1)
+	}
+
+	// TOTP secrets and signing keys are encrypted at rest, each with its own key
+	secretCipher, err := encryption.NewCipher(cfg.TotpEncryptionKey)
+	if err != nil {
+		slog.Error("failed to create secret cipher", "error", err)
+		os.Exit(1)
+	}
+	signingKeyCipher, err := encryption.NewCipher(cfg.SigningKeyEncryptionKey)
+	if err != nil {
+		slog.Error("failed to create signing key cipher", "error", err)
+		os.Exit(1)
+	}
+
+	keyManager, err := service.NewKeyManager(repository.NewSigningKeyRepository(db), signingKeyCipher, cfg.JwtAlgorithm, cfg.JwtRotationInterval)
+	if err != nil {
+		slog.Error("failed to create key manager", "error", err)
+		os.Exit(1)
+	}
+	// Make sure a signing key exists before serving
+	if err := keyManager.Rotate(context.Background()); err != nil {
+		slog.Error("failed to load signing keys", "error", err)
+		os.Exit(1)
+	}
+
+	// New passwords use the configured algorithm, hashes of the other one are upgraded at login
+	argon2Params := password.DefaultArgon2idParams()
+	argon2Params.Memory = uint32(cfg.Argon2MemoryKiB)
+	argon2Params.Iterations = uint32(cfg.Argon2Iterations)
+	argon2Params.Parallelism = uint8(cfg.Argon2Parallelism)
+	if err := argon2Params.Validate(); err != nil {
+		slog.Error("invalid argon2id parameters", "error", err)
+		os.Exit(1)
+	}
+	passwords, err := password.NewHasher(cfg.PasswordAlgorithm,
+		password.NewArgon2id(argon2Params),
+		password.NewBcrypt(cfg.BcryptCost),
+	)
+	if err != nil {
+		slog.Error("failed to create password hasher", "error", err, "algorithm", cfg.PasswordAlgorithm)
+		os.Exit(1)
+	}
+
+	// Social login providers, discovery happens on first use
+	oidcProviders := make(map[string]*oidc.Provider, len(cfg.OIDCProviders))
+	for _, providerCfg := range cfg.OIDCProviders {
+		oidcProviders[providerCfg.Name] = oidc.NewProvider(providerCfg)
+	}
+
+	authSvc := service.NewAuthService(
+		userRepo, tokenRepo, resetRepo, verifyRepo, twoFactorRepo, challengeRepo, attemptRepo,
+		identityRepo, oidcStateRepo, oidcProviders, deletionRepo, roleRepo, moderationRepo, denylist, publisher, keyManager, secretCipher, cfg.TotpIssuer, passwords,
+	)
 
 	// 5. Watermill Event Router
 	eventRouter, err := events.NewEventRouter(logger, cfg.KafkaBrokers, authSvc)
@@ -95,7 +175,13 @@ func main() {
 		os.Exit(1)
 	}
 
-	srv := grpcutil.NewServer()
+	// Only the gateway calls auth-service, end users are authenticated by the access token of each request
+	trustedServices, err := grpcutil.ParseTrustedServices(cfg.TrustedServices)
+	if err != nil {
+		slog.Error("failed to parse trusted services", "error", err)
+		os.Exit(1)
+	}
+	srv := grpcutil.NewServer(grpcutil.WithServiceAuth(trustedServices, grpcutil.AllowService(&authv1.AuthService_ServiceDesc, "gateway-service"))...)
 	authv1.RegisterAuthServiceServer(srv, authHandler)
 	reflection.Register(srv)
 
@@ -111,6 +197,24 @@ func main() {
 		}
 	}()
 
+	// Start Key Rotation
+	go keyManager.Run(ctx)
+
+	// Start Unverified User Purge
+	purger := service.NewUnverifiedUserPurger(authSvc, cfg.UnverifiedPurgeInterval, cfg.UnverifiedUserMaxAge)
+	go purger.Run(ctx)
+
+	// Start Outbox Relay
+	relay := outbox.NewRelay(db, publisher, outbox.RelayConfig{
+		Interval:  cfg.OutboxRelayInterval,
+		Retention: cfg.OutboxRetention,
+	})
+	go relay.Run(ctx)
+
+	// Start Account Deletion Retries
+	deletionRetrier := service.NewAccountDeletionRetrier(authSvc, cfg.DeletionRetryInterval, cfg.DeletionRetryAfter)
+	go deletionRetrier.Run(ctx)
+
 	// Run Server
 	go func() {
 		slog.Info("Auth Service gRPC server listening on :50051")
@@ -0,0 +1,330 @@
+package api
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/danielgtaylor/huma/v2"
+	authv1 "github.com/username/progetto/proto/gen/go/auth/v1"
+	"github.com/username/progetto/shared/pkg/rbac"
+)
+
+type Role struct {
+	Name        string   `json:"name"`
+	Description string   `json:"description"`
+	Permissions []string `json:"permissions"`
+}
+
+type ListRolesInput struct {
+	Authorization string `header:"Authorization" doc:"Bearer access token" required:"true"`
+}
+
+type ListRolesOutput struct {
+	Body struct {
+		Roles []Role `json:"roles"`
+	}
+}
+
+type AssignRoleInput struct {
+	Authorization string `header:"Authorization" doc:"Bearer access token" required:"true"`
+	ID            string `path:"id" doc:"User ID"`
+	Body          struct {
+		Role string `json:"role" example:"moderator"`
+	}
+}
+
+// Suspension is the active suspension or ban of a user
+type Suspension struct {
+	Reason    string `json:"reason"`
+	Banned    bool   `json:"banned"`
+	ExpiresAt int64  `json:"expires_at,omitempty" doc:"Unix seconds, unset for a ban"`
+	CreatedBy string `json:"created_by" doc:"ID of the admin"`
+	CreatedAt int64  `json:"created_at" doc:"Unix seconds"`
+}
+
+type AuditEntry struct {
+	ActorID   string            `json:"actor_id"`
+	Action    string            `json:"action"`
+	Details   map[string]string `json:"details,omitempty"`
+	CreatedAt int64             `json:"created_at" doc:"Unix seconds"`
+}
+
+type AdminListUsersInput struct {
+	Authorization string `header:"Authorization" doc:"Bearer access token" required:"true"`
+	Query         string `query:"q" doc:"Part of the username or email"`
+	Role          string `query:"role"`
+	Status        string `query:"status" enum:"active,suspended" doc:"Omit for every user"`
+	PageSize      int32  `query:"page_size" minimum:"1" maximum:"100" default:"20"`
+	PageToken     string `query:"page_token" doc:"next_page_token of the previous page"`
+}
+
+type AdminListUsersOutput struct {
+	Body struct {
+		Users         []Account `json:"users"`
+		NextPageToken string    `json:"next_page_token,omitempty" doc:"Unset on the last page"`
+	}
+}
+
+type AdminUserInput struct {
+	Authorization string `header:"Authorization" doc:"Bearer access token" required:"true"`
+	ID            string `path:"id" doc:"User ID"`
+}
+
+type AdminGetUserOutput struct {
+	Body struct {
+		User       Account      `json:"user"`
+		Suspension *Suspension  `json:"suspension,omitempty" doc:"Unset when the user is not suspended"`
+		Audit      []AuditEntry `json:"audit" doc:"Latest first"`
+	}
+}
+
+type SuspendUserInput struct {
+	Authorization string `header:"Authorization" doc:"Bearer access token" required:"true"`
+	ID            string `path:"id" doc:"User ID"`
+	Body          struct {
+		Reason    string    `json:"reason" minLength:"1" maxLength:"500"`
+		ExpiresAt time.Time `json:"expires_at" doc:"End of the suspension"`
+	}
+}
+
+type BanUserInput struct {
+	Authorization string `header:"Authorization" doc:"Bearer access token" required:"true"`
+	ID            string `path:"id" doc:"User ID"`
+	Body          struct {
+		Reason string `json:"reason" minLength:"1" maxLength:"500"`
+	}
+}
+
+type ReinstateUserInput struct {
+	Authorization string `header:"Authorization" doc:"Bearer access token" required:"true"`
+	ID            string `path:"id" doc:"User ID"`
+	Body          struct {
+		Reason string `json:"reason,omitempty" maxLength:"500"`
+	}
+}
+
+type SuspensionOutput struct {
+	Body Suspension
+}
+
+func suspensionFromProto(suspension *authv1.Suspension) Suspension {
+	return Suspension{
+		Reason:    suspension.Reason,
+		Banned:    suspension.Banned,
+		ExpiresAt: suspension.ExpiresAt,
+		CreatedBy: suspension.CreatedBy,
+		CreatedAt: suspension.CreatedAt,
+	}
+}
+
+func RegisterAdminRoutes(api huma.API, client authv1.AuthServiceClient, logger *slog.Logger) {
+	huma.Register(api, huma.Operation{
+		OperationID: "admin-list-roles",
+		Method:      http.MethodGet,
+		Path:        "/admin/roles",
+		Summary:     "List the roles and their permissions",
+		Tags:        []string{"Admin"},
+		Security:    BearerSecurity,
+		Metadata:    map[string]any{RequirePermission: rbac.PermRolesManage},
+	}, func(ctx context.Context, input *ListRolesInput) (*ListRolesOutput, error) {
+		resp, err := client.ListRoles(ctx, &authv1.ListRolesRequest{
+			AccessToken: strings.TrimPrefix(input.Authorization, "Bearer "),
+		})
+		if err != nil {
+			logger.ErrorContext(ctx, "list roles failed", "error", err)
+			return nil, MapGRPCError(err)
+		}
+
+		out := &ListRolesOutput{}
+		out.Body.Roles = make([]Role, len(resp.Roles))
+		for i, role := range resp.Roles {
+			out.Body.Roles[i] = Role{Name: role.Name, Description: role.Description, Permissions: role.Permissions}
+		}
+		return out, nil
+	})
+
+	huma.Register(api, huma.Operation{
+		OperationID: "admin-assign-role",
+		Method:      http.MethodPut,
+		Path:        "/admin/users/{id}/role",
+		Summary:     "Assign a role to a user",
+		Description: "The sessions of the user are revoked, it gets the new permissions at the next login.",
+		Tags:        []string{"Admin"},
+		Security:    BearerSecurity,
+		Metadata:    map[string]any{RequirePermission: rbac.PermRolesManage},
+	}, func(ctx context.Context, input *AssignRoleInput) (*AccountOutput, error) {
+		resp, err := client.AssignRole(ctx, &authv1.AssignRoleRequest{
+			AccessToken: strings.TrimPrefix(input.Authorization, "Bearer "),
+			UserId:      input.ID,
+			Role:        input.Body.Role,
+		})
+		if err != nil {
+			logger.ErrorContext(ctx, "assign role failed", "error", err, "user_id", input.ID)
+			return nil, MapGRPCError(err)
+		}
+		return accountOutput(resp.User), nil
+	})
+	huma.Register(api, huma.Operation{
+		OperationID: "admin-list-users",
+		Method:      http.MethodGet,
+		Path:        "/admin/users",
+		Summary:     "List and filter users",
+		Tags:        []string{"Admin"},
+		Security:    BearerSecurity,
+		Metadata:    map[string]any{RequirePermission: rbac.PermUsersRead},
+	}, func(ctx context.Context, input *AdminListUsersInput) (*AdminListUsersOutput, error) {
+		req := &authv1.ListUsersRequest{
+			AccessToken: strings.TrimPrefix(input.Authorization, "Bearer "),
+			Query:       input.Query,
+			Role:        input.Role,
+			PageSize:    input.PageSize,
+			PageToken:   input.PageToken,
+		}
+		if input.Status != "" {
+			suspended := input.Status == "suspended"
+			req.Suspended = &suspended
+		}
+
+		resp, err := client.ListUsers(ctx, req)
+		if err != nil {
+			logger.ErrorContext(ctx, "list users failed", "error", err)
+			return nil, MapGRPCError(err)
+		}
+
+		out := &AdminListUsersOutput{}
+		out.Body.Users = make([]Account, len(resp.Users))
+		for i, user := range resp.Users {
+			out.Body.Users[i] = accountOutput(user).Body
+		}
+		out.Body.NextPageToken = resp.NextPageToken
+		return out, nil
+	})
+
+	huma.Register(api, huma.Operation{
+		OperationID: "admin-get-user",
+		Method:      http.MethodGet,
+		Path:        "/admin/users/{id}",
+		Summary:     "Get a user with its suspension and audit log",
+		Tags:        []string{"Admin"},
+		Security:    BearerSecurity,
+		Metadata:    map[string]any{RequirePermission: rbac.PermUsersRead},
+	}, func(ctx context.Context, input *AdminUserInput) (*AdminGetUserOutput, error) {
+		resp, err := client.AdminGetUser(ctx, &authv1.AdminGetUserRequest{
+			AccessToken: strings.TrimPrefix(input.Authorization, "Bearer "),
+			UserId:      input.ID,
+		})
+		if err != nil {
+			logger.ErrorContext(ctx, "admin get user failed", "error", err, "user_id", input.ID)
+			return nil, MapGRPCError(err)
+		}
+
+		out := &AdminGetUserOutput{}
+		out.Body.User = accountOutput(resp.User).Body
+		if resp.Suspension != nil {
+			suspension := suspensionFromProto(resp.Suspension)
+			out.Body.Suspension = &suspension
+		}
+		out.Body.Audit = make([]AuditEntry, len(resp.Audit))
+		for i, entry := range resp.Audit {
+			out.Body.Audit[i] = AuditEntry{
+				ActorID:   entry.ActorId,
+				Action:    entry.Action,
+				Details:   entry.Details,
+				CreatedAt: entry.CreatedAt,
+			}
+		}
+		return out, nil
+	})
+
+	huma.Register(api, huma.Operation{
+		OperationID: "admin-suspend-user",
+		Method:      http.MethodPost,
+		Path:        "/admin/users/{id}/suspend",
+		Summary:     "Suspend a user until a date",
+		Description: "Replaces the active suspension or ban. The sessions of the user are revoked.",
+		Tags:        []string{"Admin"},
+		Security:    BearerSecurity,
+		Metadata:    map[string]any{RequirePermission: rbac.PermUsersManage},
+	}, func(ctx context.Context, input *SuspendUserInput) (*SuspensionOutput, error) {
+		resp, err := client.SuspendUser(ctx, &authv1.SuspendUserRequest{
+			AccessToken: strings.TrimPrefix(input.Authorization, "Bearer "),
+			UserId:      input.ID,
+			Reason:      input.Body.Reason,
+			ExpiresAt:   input.Body.ExpiresAt.Unix(),
+		})
+		if err != nil {
+			logger.ErrorContext(ctx, "suspend user failed", "error", err, "user_id", input.ID)
+			return nil, MapGRPCError(err)
+		}
+		return &SuspensionOutput{Body: suspensionFromProto(resp.Suspension)}, nil
+	})
+
+	huma.Register(api, huma.Operation{
+		OperationID: "admin-ban-user",
+		Method:      http.MethodPost,
+		Path:        "/admin/users/{id}/ban",
+		Summary:     "Ban a user",
+		Description: "A ban never expires, it is lifted with unban. The sessions of the user are revoked.",
+		Tags:        []string{"Admin"},
+		Security:    BearerSecurity,
+		Metadata:    map[string]any{RequirePermission: rbac.PermUsersManage},
+	}, func(ctx context.Context, input *BanUserInput) (*SuspensionOutput, error) {
+		resp, err := client.SuspendUser(ctx, &authv1.SuspendUserRequest{
+			AccessToken: strings.TrimPrefix(input.Authorization, "Bearer "),
+			UserId:      input.ID,
+			Reason:      input.Body.Reason,
+		})
+		if err != nil {
+			logger.ErrorContext(ctx, "ban user failed", "error", err, "user_id", input.ID)
+			return nil, MapGRPCError(err)
+		}
+		return &SuspensionOutput{Body: suspensionFromProto(resp.Suspension)}, nil
+	})
+
+	huma.Register(api, huma.Operation{
+		OperationID:   "admin-unban-user",
+		Method:        http.MethodPost,
+		Path:          "/admin/users/{id}/unban",
+		Summary:       "Lift the suspension or ban of a user",
+		Tags:          []string{"Admin"},
+		Security:      BearerSecurity,
+		Metadata:      map[string]any{RequirePermission: rbac.PermUsersManage},
+		DefaultStatus: http.StatusNoContent,
+	}, func(ctx context.Context, input *ReinstateUserInput) (*struct{}, error) {
+		_, err := client.ReinstateUser(ctx, &authv1.ReinstateUserRequest{
+			AccessToken: strings.TrimPrefix(input.Authorization, "Bearer "),
+			UserId:      input.ID,
+			Reason:      input.Body.Reason,
+		})
+		if err != nil {
+			logger.ErrorContext(ctx, "unban user failed", "error", err, "user_id", input.ID)
+			return nil, MapGRPCError(err)
+		}
+		return nil, nil
+	})
+
+	huma.Register(api, huma.Operation{
+		OperationID:   "admin-force-password-reset",
+		Method:        http.MethodPost,
+		Path:          "/admin/users/{id}/force-password-reset",
+		Summary:       "Force a user to choose a new password",
+		Description:   "The current password stops working, the sessions are revoked and a reset link is emailed.",
+		Tags:          []string{"Admin"},
+		Security:      BearerSecurity,
+		Metadata:      map[string]any{RequirePermission: rbac.PermUsersManage},
+		DefaultStatus: http.StatusNoContent,
+	}, func(ctx context.Context, input *AdminUserInput) (*struct{}, error) {
+		_, err := client.ForcePasswordReset(ctx, &authv1.ForcePasswordResetRequest{
+			AccessToken: strings.TrimPrefix(input.Authorization, "Bearer "),
+			UserId:      input.ID,
+		})
+		if err != nil {
+			logger.ErrorContext(ctx, "force password reset failed", "error", err, "user_id", input.ID)
+			return nil, MapGRPCError(err)
+		}
+		return nil, nil
+	})
+}
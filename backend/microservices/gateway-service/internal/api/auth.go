@@ -4,9 +4,13 @@ import (
 	"context"
 	"log/slog"
 	"net/http"
+	"strings"
+	"time"
 
 	"github.com/danielgtaylor/huma/v2"
 	authv1 "github.com/username/progetto/proto/gen/go/auth/v1"
+	"github.com/username/progetto/shared/pkg/jwtutil"
+	"github.com/username/progetto/shared/pkg/ratelimit"
 )
 
 type RegisterInput struct {
@@ -35,9 +39,11 @@ type LoginInput struct {
 
 type LoginOutput struct {
 	Body struct {
-		AccessToken  string `json:"access_token"`
-		RefreshToken string `json:"refresh_token"`
-		ExpiresIn    int64  `json:"expires_in"`
+		AccessToken       string `json:"access_token,omitempty"`
+		RefreshToken      string `json:"refresh_token,omitempty"`
+		ExpiresIn         int64  `json:"expires_in,omitempty"`
+		TwoFactorRequired bool   `json:"two_factor_required,omitempty" doc:"Call /auth/2fa/verify with the challenge token"`
+		ChallengeToken    string `json:"challenge_token,omitempty"`
 	}
 }
 
@@ -55,6 +61,162 @@ type RefreshOutput struct {
 	}
 }
 
+type LogoutInput struct {
+	Body struct {
+		RefreshToken string `json:"refresh_token"`
+	}
+}
+
+type LogoutAllInput struct {
+	Authorization string `header:"Authorization" doc:"Bearer access token" required:"true"`
+}
+
+type LogoutAllOutput struct {
+	Body struct {
+		RevokedSessions int64 `json:"revoked_sessions"`
+	}
+}
+
+type Session struct {
+	ID         string `json:"id"`
+	Device     string `json:"device,omitempty" doc:"X-Device-Name sent at login"`
+	IP         string `json:"ip" doc:"IP of the login"`
+	LastIP     string `json:"last_ip" doc:"IP of the last refresh"`
+	UserAgent  string `json:"user_agent"`
+	CreatedAt  int64  `json:"created_at" doc:"Unix seconds"`
+	LastUsedAt int64  `json:"last_used_at" doc:"Unix seconds"`
+	Current    bool   `json:"current" doc:"The session of this request"`
+}
+
+type ListSessionsInput struct {
+	Authorization string `header:"Authorization" doc:"Bearer access token" required:"true"`
+}
+
+type ListSessionsOutput struct {
+	Body struct {
+		Sessions []Session `json:"sessions" doc:"Most recently used first"`
+	}
+}
+
+type RevokeSessionInput struct {
+	Authorization string `header:"Authorization" doc:"Bearer access token" required:"true"`
+	ID            string `path:"id" doc:"Session ID"`
+}
+
+type RequestPasswordResetInput struct {
+	Body struct {
+		Email string `json:"email" doc:"Email address of the account"`
+	}
+}
+
+type ConfirmPasswordResetInput struct {
+	Body struct {
+		Token       string `json:"token" doc:"Reset token received by email"`
+		NewPassword string `json:"new_password" doc:"New password"`
+	}
+}
+
+type RequestEmailVerificationInput struct {
+	Authorization string `header:"Authorization" doc:"Bearer access token" required:"true"`
+}
+
+type ConfirmEmailVerificationInput struct {
+	Body struct {
+		Token string `json:"token" doc:"Verification token received by email"`
+	}
+}
+
+type EnrollTwoFactorInput struct {
+	Authorization string `header:"Authorization" doc:"Bearer access token" required:"true"`
+}
+
+type EnrollTwoFactorOutput struct {
+	Body struct {
+		Secret          string `json:"secret" doc:"Base32 secret for manual entry"`
+		ProvisioningURI string `json:"provisioning_uri" doc:"otpauth URI to render as QR code"`
+	}
+}
+
+type ConfirmTwoFactorInput struct {
+	Authorization string `header:"Authorization" doc:"Bearer access token" required:"true"`
+	Body          struct {
+		Code string `json:"code" doc:"Current code from the authenticator app"`
+	}
+}
+
+type ConfirmTwoFactorOutput struct {
+	Body struct {
+		RecoveryCodes []string `json:"recovery_codes" doc:"Single-use codes, shown only once"`
+	}
+}
+
+type VerifyTwoFactorInput struct {
+	Body struct {
+		ChallengeToken string `json:"challenge_token" doc:"Challenge returned by login"`
+		Code           string `json:"code" doc:"Authenticator or recovery code"`
+	}
+}
+
+type OIDCAuthorizeInput struct {
+	Provider string `path:"provider" doc:"Configured identity provider, e.g. google"`
+}
+
+type OIDCAuthorizeOutput struct {
+	Body struct {
+		AuthURL string `json:"auth_url" doc:"Provider URL to redirect the user to"`
+		State   string `json:"state" doc:"Returned by the provider to the redirect URL"`
+	}
+}
+
+type OIDCCallbackInput struct {
+	Provider string `path:"provider" doc:"Configured identity provider, e.g. google"`
+	Body     struct {
+		Code  string `json:"code" doc:"Authorization code from the provider redirect"`
+		State string `json:"state" doc:"State from the provider redirect"`
+	}
+}
+
+type OIDCCallbackOutput struct {
+	Body struct {
+		UserID            string `json:"user_id"`
+		AccessToken       string `json:"access_token,omitempty"`
+		RefreshToken      string `json:"refresh_token,omitempty"`
+		ExpiresIn         int64  `json:"expires_in,omitempty"`
+		TwoFactorRequired bool   `json:"two_factor_required,omitempty" doc:"Call /auth/2fa/verify with the challenge token"`
+		ChallengeToken    string `json:"challenge_token,omitempty"`
+	}
+}
+
+type JWKSOutput struct {
+	CacheControl string `header:"Cache-Control"`
+	Body         jwtutil.JWKS
+}
+
+// NewJWKSFetcher loads the public signing keys from auth-service
+func NewJWKSFetcher(client authv1.AuthServiceClient) jwtutil.JWKSFetcher {
+	return func(ctx context.Context) (*jwtutil.JWKS, error) {
+		resp, err := client.GetJWKS(ctx, &authv1.GetJWKSRequest{})
+		if err != nil {
+			return nil, err
+		}
+
+		jwks := &jwtutil.JWKS{Keys: make([]jwtutil.JWK, 0, len(resp.Keys))}
+		for _, k := range resp.Keys {
+			jwks.Keys = append(jwks.Keys, jwtutil.JWK{
+				Kty: k.Kty,
+				Kid: k.Kid,
+				Use: k.Use,
+				Alg: k.Alg,
+				N:   k.N,
+				E:   k.E,
+				Crv: k.Crv,
+				X:   k.X,
+			})
+		}
+		return jwks, nil
+	}
+}
+
 func RegisterAuthRoutes(api huma.API, client authv1.AuthServiceClient, logger *slog.Logger) {
 	huma.Register(api, huma.Operation{
 		OperationID: "register",
@@ -62,6 +224,7 @@ func RegisterAuthRoutes(api huma.API, client authv1.AuthServiceClient, logger *s
 		Path:        "/auth/register",
 		Summary:     "Register a new user",
 		Tags:        []string{"Auth"},
+		Metadata:    map[string]any{RateLimit: ratelimit.Limit{Algorithm: ratelimit.SlidingWindow, Requests: 10, Period: time.Hour}},
 	}, func(ctx context.Context, input *RegisterInput) (*RegisterOutput, error) {
 		resp, err := client.Register(ctx, &authv1.RegisterRequest{
 			Username: input.Body.Username,
@@ -86,6 +249,7 @@ func RegisterAuthRoutes(api huma.API, client authv1.AuthServiceClient, logger *s
 		Path:        "/auth/login",
 		Summary:     "Login user",
 		Tags:        []string{"Auth"},
+		Metadata:    map[string]any{RateLimit: ratelimit.Limit{Algorithm: ratelimit.SlidingWindow, Requests: 10, Period: time.Minute}},
 	}, func(ctx context.Context, input *LoginInput) (*LoginOutput, error) {
 		resp, err := client.Login(ctx, &authv1.LoginRequest{
 			Email:    input.Body.Email,
@@ -99,6 +263,8 @@ func RegisterAuthRoutes(api huma.API, client authv1.AuthServiceClient, logger *s
 		out.Body.AccessToken = resp.AccessToken
 		out.Body.RefreshToken = resp.RefreshToken
 		out.Body.ExpiresIn = resp.ExpiresIn
+		out.Body.TwoFactorRequired = resp.TwoFactorRequired
+		out.Body.ChallengeToken = resp.ChallengeToken
 		return out, nil
 	})
 
@@ -108,6 +274,7 @@ func RegisterAuthRoutes(api huma.API, client authv1.AuthServiceClient, logger *s
 		Path:        "/auth/refresh",
 		Summary:     "Refresh access token",
 		Tags:        []string{"Auth"},
+		Metadata:    map[string]any{RateLimit: ratelimit.Limit{Algorithm: ratelimit.SlidingWindow, Requests: 30, Period: time.Minute}},
 	}, func(ctx context.Context, input *RefreshInput) (*RefreshOutput, error) {
 		resp, err := client.Refresh(ctx, &authv1.RefreshRequest{
 			RefreshToken: input.Body.RefreshToken,
@@ -122,4 +289,304 @@ func RegisterAuthRoutes(api huma.API, client authv1.AuthServiceClient, logger *s
 		out.Body.ExpiresIn = resp.ExpiresIn
 		return out, nil
 	})
+
+	huma.Register(api, huma.Operation{
+		OperationID:   "logout",
+		Method:        http.MethodPost,
+		Path:          "/auth/logout",
+		Summary:       "Logout the current session",
+		Tags:          []string{"Auth"},
+		DefaultStatus: http.StatusNoContent,
+	}, func(ctx context.Context, input *LogoutInput) (*struct{}, error) {
+		_, err := client.Logout(ctx, &authv1.LogoutRequest{
+			RefreshToken: input.Body.RefreshToken,
+		})
+		if err != nil {
+			logger.ErrorContext(ctx, "logout failed", "error", err)
+			return nil, MapGRPCError(err)
+		}
+		return nil, nil
+	})
+
+	huma.Register(api, huma.Operation{
+		OperationID: "logout-all",
+		Method:      http.MethodPost,
+		Path:        "/auth/logout-all",
+		Summary:     "Logout every session of the current user",
+		Tags:        []string{"Auth"},
+		Security:    BearerSecurity,
+	}, func(ctx context.Context, input *LogoutAllInput) (*LogoutAllOutput, error) {
+		resp, err := client.LogoutAll(ctx, &authv1.LogoutAllRequest{
+			AccessToken: strings.TrimPrefix(input.Authorization, "Bearer "),
+		})
+		if err != nil {
+			logger.ErrorContext(ctx, "logout all failed", "error", err)
+			return nil, MapGRPCError(err)
+		}
+		out := &LogoutAllOutput{}
+		out.Body.RevokedSessions = resp.RevokedSessions
+		return out, nil
+	})
+
+	huma.Register(api, huma.Operation{
+		OperationID: "list-sessions",
+		Method:      http.MethodGet,
+		Path:        "/auth/sessions",
+		Summary:     "List the sessions of the current user",
+		Tags:        []string{"Auth"},
+		Security:    BearerSecurity,
+	}, func(ctx context.Context, input *ListSessionsInput) (*ListSessionsOutput, error) {
+		resp, err := client.ListSessions(ctx, &authv1.ListSessionsRequest{
+			AccessToken: strings.TrimPrefix(input.Authorization, "Bearer "),
+		})
+		if err != nil {
+			logger.ErrorContext(ctx, "list sessions failed", "error", err)
+			return nil, MapGRPCError(err)
+		}
+
+		out := &ListSessionsOutput{}
+		out.Body.Sessions = make([]Session, len(resp.Sessions))
+		for i, session := range resp.Sessions {
+			out.Body.Sessions[i] = Session{
+				ID:         session.SessionId,
+				Device:     session.Device,
+				IP:         session.Ip,
+				LastIP:     session.LastIp,
+				UserAgent:  session.UserAgent,
+				CreatedAt:  session.CreatedAt,
+				LastUsedAt: session.LastUsedAt,
+				Current:    session.Current,
+			}
+		}
+		return out, nil
+	})
+
+	huma.Register(api, huma.Operation{
+		OperationID:   "revoke-session",
+		Method:        http.MethodDelete,
+		Path:          "/auth/sessions/{id}",
+		Summary:       "Logout one session of the current user",
+		Tags:          []string{"Auth"},
+		Security:      BearerSecurity,
+		DefaultStatus: http.StatusNoContent,
+	}, func(ctx context.Context, input *RevokeSessionInput) (*struct{}, error) {
+		_, err := client.RevokeSession(ctx, &authv1.RevokeSessionRequest{
+			AccessToken: strings.TrimPrefix(input.Authorization, "Bearer "),
+			SessionId:   input.ID,
+		})
+		if err != nil {
+			logger.ErrorContext(ctx, "revoke session failed", "error", err, "session_id", input.ID)
+			return nil, MapGRPCError(err)
+		}
+		return nil, nil
+	})
+
+	huma.Register(api, huma.Operation{
+		OperationID:   "request-password-reset",
+		Method:        http.MethodPost,
+		Path:          "/auth/password-reset",
+		Summary:       "Send a password reset email",
+		Tags:          []string{"Auth"},
+		Metadata:      map[string]any{RateLimit: ratelimit.Limit{Algorithm: ratelimit.SlidingWindow, Requests: 5, Period: 15 * time.Minute}},
+		DefaultStatus: http.StatusAccepted,
+	}, func(ctx context.Context, input *RequestPasswordResetInput) (*struct{}, error) {
+		_, err := client.RequestPasswordReset(ctx, &authv1.RequestPasswordResetRequest{
+			Email: input.Body.Email,
+		})
+		if err != nil {
+			logger.ErrorContext(ctx, "request password reset failed", "error", err)
+			return nil, MapGRPCError(err)
+		}
+		return nil, nil
+	})
+
+	huma.Register(api, huma.Operation{
+		OperationID:   "confirm-password-reset",
+		Method:        http.MethodPost,
+		Path:          "/auth/password-reset/confirm",
+		Summary:       "Set a new password with a reset token",
+		Tags:          []string{"Auth"},
+		Metadata:      map[string]any{RateLimit: ratelimit.Limit{Algorithm: ratelimit.SlidingWindow, Requests: 10, Period: 15 * time.Minute}},
+		DefaultStatus: http.StatusNoContent,
+	}, func(ctx context.Context, input *ConfirmPasswordResetInput) (*struct{}, error) {
+		_, err := client.ConfirmPasswordReset(ctx, &authv1.ConfirmPasswordResetRequest{
+			Token:       input.Body.Token,
+			NewPassword: input.Body.NewPassword,
+		})
+		if err != nil {
+			logger.ErrorContext(ctx, "confirm password reset failed", "error", err)
+			return nil, MapGRPCError(err)
+		}
+		return nil, nil
+	})
+
+	huma.Register(api, huma.Operation{
+		OperationID:   "request-email-verification",
+		Method:        http.MethodPost,
+		Path:          "/auth/verify-email",
+		Summary:       "Send a new verification email",
+		Tags:          []string{"Auth"},
+		Metadata:      map[string]any{RateLimit: ratelimit.Limit{Algorithm: ratelimit.SlidingWindow, Requests: 5, Period: 15 * time.Minute}},
+		Security:      BearerSecurity,
+		DefaultStatus: http.StatusAccepted,
+	}, func(ctx context.Context, input *RequestEmailVerificationInput) (*struct{}, error) {
+		_, err := client.RequestEmailVerification(ctx, &authv1.RequestEmailVerificationRequest{
+			AccessToken: strings.TrimPrefix(input.Authorization, "Bearer "),
+		})
+		if err != nil {
+			logger.ErrorContext(ctx, "request email verification failed", "error", err)
+			return nil, MapGRPCError(err)
+		}
+		return nil, nil
+	})
+
+	huma.Register(api, huma.Operation{
+		OperationID:   "confirm-email-verification",
+		Method:        http.MethodPost,
+		Path:          "/auth/verify-email/confirm",
+		Summary:       "Verify the email with the emailed token",
+		Tags:          []string{"Auth"},
+		DefaultStatus: http.StatusNoContent,
+	}, func(ctx context.Context, input *ConfirmEmailVerificationInput) (*struct{}, error) {
+		_, err := client.ConfirmEmailVerification(ctx, &authv1.ConfirmEmailVerificationRequest{
+			Token: input.Body.Token,
+		})
+		if err != nil {
+			logger.ErrorContext(ctx, "confirm email verification failed", "error", err)
+			return nil, MapGRPCError(err)
+		}
+		return nil, nil
+	})
+
+	huma.Register(api, huma.Operation{
+		OperationID: "enroll-two-factor",
+		Method:      http.MethodPost,
+		Path:        "/auth/2fa/enroll",
+		Summary:     "Start two-factor enrollment",
+		Tags:        []string{"Auth"},
+		Security:    BearerSecurity,
+	}, func(ctx context.Context, input *EnrollTwoFactorInput) (*EnrollTwoFactorOutput, error) {
+		resp, err := client.EnrollTwoFactor(ctx, &authv1.EnrollTwoFactorRequest{
+			AccessToken: strings.TrimPrefix(input.Authorization, "Bearer "),
+		})
+		if err != nil {
+			logger.ErrorContext(ctx, "enroll two-factor failed", "error", err)
+			return nil, MapGRPCError(err)
+		}
+		out := &EnrollTwoFactorOutput{}
+		out.Body.Secret = resp.Secret
+		out.Body.ProvisioningURI = resp.ProvisioningUri
+		return out, nil
+	})
+
+	huma.Register(api, huma.Operation{
+		OperationID: "confirm-two-factor",
+		Method:      http.MethodPost,
+		Path:        "/auth/2fa/confirm",
+		Summary:     "Enable two-factor authentication",
+		Tags:        []string{"Auth"},
+		Metadata:    map[string]any{RateLimit: ratelimit.Limit{Algorithm: ratelimit.SlidingWindow, Requests: 10, Period: time.Minute}},
+		Security:    BearerSecurity,
+	}, func(ctx context.Context, input *ConfirmTwoFactorInput) (*ConfirmTwoFactorOutput, error) {
+		resp, err := client.ConfirmTwoFactor(ctx, &authv1.ConfirmTwoFactorRequest{
+			AccessToken: strings.TrimPrefix(input.Authorization, "Bearer "),
+			Code:        input.Body.Code,
+		})
+		if err != nil {
+			logger.ErrorContext(ctx, "confirm two-factor failed", "error", err)
+			return nil, MapGRPCError(err)
+		}
+		out := &ConfirmTwoFactorOutput{}
+		out.Body.RecoveryCodes = resp.RecoveryCodes
+		return out, nil
+	})
+
+	huma.Register(api, huma.Operation{
+		OperationID: "verify-two-factor",
+		Method:      http.MethodPost,
+		Path:        "/auth/2fa/verify",
+		Summary:     "Complete a login with a two-factor code",
+		Tags:        []string{"Auth"},
+		Metadata:    map[string]any{RateLimit: ratelimit.Limit{Algorithm: ratelimit.SlidingWindow, Requests: 10, Period: time.Minute}},
+	}, func(ctx context.Context, input *VerifyTwoFactorInput) (*LoginOutput, error) {
+		resp, err := client.VerifyTwoFactor(ctx, &authv1.VerifyTwoFactorRequest{
+			ChallengeToken: input.Body.ChallengeToken,
+			Code:           input.Body.Code,
+		})
+		if err != nil {
+			logger.ErrorContext(ctx, "verify two-factor failed", "error", err)
+			return nil, MapGRPCError(err)
+		}
+		out := &LoginOutput{}
+		out.Body.AccessToken = resp.AccessToken
+		out.Body.RefreshToken = resp.RefreshToken
+		out.Body.ExpiresIn = resp.ExpiresIn
+		return out, nil
+	})
+
+	huma.Register(api, huma.Operation{
+		OperationID: "oidc-authorize",
+		Method:      http.MethodGet,
+		Path:        "/auth/oidc/{provider}/authorize",
+		Summary:     "Start a social login",
+		Description: "Returns the provider URL to redirect the user to. The provider redirects back with code and state for the callback.",
+		Tags:        []string{"Auth"},
+	}, func(ctx context.Context, input *OIDCAuthorizeInput) (*OIDCAuthorizeOutput, error) {
+		resp, err := client.GetOIDCAuthURL(ctx, &authv1.GetOIDCAuthURLRequest{
+			Provider: input.Provider,
+		})
+		if err != nil {
+			logger.ErrorContext(ctx, "oidc authorize failed", "error", err, "provider", input.Provider)
+			return nil, MapGRPCError(err)
+		}
+		out := &OIDCAuthorizeOutput{}
+		out.Body.AuthURL = resp.AuthUrl
+		out.Body.State = resp.State
+		return out, nil
+	})
+
+	huma.Register(api, huma.Operation{
+		OperationID: "oidc-callback",
+		Method:      http.MethodPost,
+		Path:        "/auth/oidc/{provider}/callback",
+		Summary:     "Complete a social login",
+		Description: "Links the provider identity to the account with the same verified email, or creates a new account.",
+		Tags:        []string{"Auth"},
+	}, func(ctx context.Context, input *OIDCCallbackInput) (*OIDCCallbackOutput, error) {
+		resp, err := client.OIDCCallback(ctx, &authv1.OIDCCallbackRequest{
+			Provider: input.Provider,
+			Code:     input.Body.Code,
+			State:    input.Body.State,
+		})
+		if err != nil {
+			logger.ErrorContext(ctx, "oidc callback failed", "error", err, "provider", input.Provider)
+			return nil, MapGRPCError(err)
+		}
+		out := &OIDCCallbackOutput{}
+		out.Body.UserID = resp.UserId
+		out.Body.AccessToken = resp.AccessToken
+		out.Body.RefreshToken = resp.RefreshToken
+		out.Body.ExpiresIn = resp.ExpiresIn
+		out.Body.TwoFactorRequired = resp.TwoFactorRequired
+		out.Body.ChallengeToken = resp.ChallengeToken
+		return out, nil
+	})
+
+	fetchJWKS := NewJWKSFetcher(client)
+	huma.Register(api, huma.Operation{
+		OperationID: "jwks",
+		Method:      http.MethodGet,
+		Path:        "/.well-known/jwks.json",
+		Summary:     "Public keys used to verify access tokens",
+		Tags:        []string{"Auth"},
+	}, func(ctx context.Context, input *struct{}) (*JWKSOutput, error) {
+		jwks, err := fetchJWKS(ctx)
+		if err != nil {
+			logger.ErrorContext(ctx, "get jwks failed", "error", err)
+			return nil, MapGRPCError(err)
+		}
+		out := &JWKSOutput{CacheControl: "public, max-age=300"}
+		out.Body = *jwks
+		return out, nil
+	})
 }
@@ -0,0 +1,156 @@
+package api
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/danielgtaylor/huma/v2"
+	postv1 "github.com/username/progetto/proto/gen/go/post/v1"
+	"github.com/username/progetto/shared/pkg/ratelimit"
+)
+
+type CreateCommentInput struct {
+	PostID string `path:"id"`
+	Body   struct {
+		Content  string `json:"content" minLength:"1" maxLength:"2000" doc:"Content of the comment"`
+		ParentID string `json:"parent_id,omitempty" doc:"Comment replied to, replies to a reply join the same thread"`
+	}
+}
+
+type CommentOutput struct {
+	Body struct {
+		Comment *postv1.Comment `json:"comment"`
+	}
+}
+
+type ListCommentsInput struct {
+	PostID        string `path:"id"`
+	ParentID      string `query:"parent_id" doc:"List the replies of this comment instead of the top level comments"`
+	Limit         int32  `query:"limit" doc:"Maximum number of comments to return" default:"20" maximum:"100"`
+	NextPageToken string `query:"anchorPage" doc:"Token for the next page of results"`
+}
+
+type ListCommentsOutput struct {
+	Body struct {
+		Comments      []*postv1.Comment `json:"comments"`
+		NextPageToken string            `json:"anchorPage,omitempty" doc:"Empty on the last page"`
+	}
+}
+
+type UpdateCommentInput struct {
+	ID   string `path:"id"`
+	Body struct {
+		Content string `json:"content" minLength:"1" maxLength:"2000" doc:"New content of the comment"`
+	}
+}
+
+type DeleteCommentOutput struct {
+	Body struct {
+		DeletedCount int32 `json:"deleted_count" doc:"The comment and its replies"`
+	}
+}
+
+func RegisterCommentRoutes(api huma.API, client postv1.PostServiceClient, logger *slog.Logger) {
+	huma.Register(api, huma.Operation{
+		OperationID: "create-comment",
+		Method:      http.MethodPost,
+		Path:        "/posts/{id}/comments",
+		Summary:     "Comment a post or reply to a comment",
+		Tags:        []string{"Comments"},
+		Security:    BearerSecurity,
+		Metadata:    map[string]any{RequireVerifiedEmail: true, RateLimit: ratelimit.Limit{Algorithm: ratelimit.TokenBucket, Requests: 120, Period: time.Hour, Burst: 10}},
+	}, func(ctx context.Context, input *CreateCommentInput) (*CommentOutput, error) {
+		claims, _ := ClaimsFromContext(ctx)
+		resp, err := client.CreateComment(ctx, &postv1.CreateCommentRequest{
+			PostId:   input.PostID,
+			AuthorId: claims.UserID,
+			ParentId: input.Body.ParentID,
+			Content:  input.Body.Content,
+		})
+		if err != nil {
+			logger.ErrorContext(ctx, "create comment failed", "error", err, "post_id", input.PostID)
+			return nil, MapGRPCError(err)
+		}
+
+		output := &CommentOutput{}
+		output.Body.Comment = resp.Comment
+		return output, nil
+	})
+
+	huma.Register(api, huma.Operation{
+		OperationID: "list-comments",
+		Method:      http.MethodGet,
+		Path:        "/posts/{id}/comments",
+		Summary:     "List the comments of a post, oldest first",
+		Tags:        []string{"Comments"},
+	}, func(ctx context.Context, input *ListCommentsInput) (*ListCommentsOutput, error) {
+		resp, err := client.ListComments(ctx, &postv1.ListCommentsRequest{
+			PostId:        input.PostID,
+			ParentId:      input.ParentID,
+			Limit:         input.Limit,
+			NextPageToken: input.NextPageToken,
+		})
+		if err != nil {
+			logger.WarnContext(ctx, "list comments failed", "error", err, "post_id", input.PostID)
+			return nil, MapGRPCError(err)
+		}
+
+		output := &ListCommentsOutput{}
+		output.Body.Comments = resp.Comments
+		output.Body.NextPageToken = resp.NextPageToken
+		return output, nil
+	})
+
+	huma.Register(api, huma.Operation{
+		OperationID: "update-comment",
+		Method:      http.MethodPatch,
+		Path:        "/comments/{id}",
+		Summary:     "Edit a comment",
+		Tags:        []string{"Comments"},
+		Security:    BearerSecurity,
+		Metadata:    map[string]any{RateLimit: ratelimit.Limit{Algorithm: ratelimit.TokenBucket, Requests: 120, Period: time.Hour, Burst: 10}},
+	}, func(ctx context.Context, input *UpdateCommentInput) (*CommentOutput, error) {
+		claims, _ := ClaimsFromContext(ctx)
+		resp, err := client.UpdateComment(ctx, &postv1.UpdateCommentRequest{
+			CommentId: input.ID,
+			UserId:    claims.UserID,
+			Content:   input.Body.Content,
+		})
+		if err != nil {
+			logger.WarnContext(ctx, "update comment failed", "error", err, "comment_id", input.ID)
+			return nil, MapGRPCError(err)
+		}
+
+		output := &CommentOutput{}
+		output.Body.Comment = resp.Comment
+		return output, nil
+	})
+
+	huma.Register(api, huma.Operation{
+		OperationID: "delete-comment",
+		Method:      http.MethodDelete,
+		Path:        "/comments/{id}",
+		Summary:     "Delete a comment",
+		Description: "The author of the comment or of the post can delete it. Deleting a comment deletes its replies.",
+		Tags:        []string{"Comments"},
+		Security:    BearerSecurity,
+	}, func(ctx context.Context, input *struct {
+		ID string `path:"id"`
+	}) (*DeleteCommentOutput, error) {
+		claims, _ := ClaimsFromContext(ctx)
+		resp, err := client.DeleteComment(ctx, &postv1.DeleteCommentRequest{
+			CommentId: input.ID,
+			UserId:    claims.UserID,
+		})
+		if err != nil {
+			logger.WarnContext(ctx, "delete comment failed", "error", err, "comment_id", input.ID)
+			return nil, MapGRPCError(err)
+		}
+
+		output := &DeleteCommentOutput{}
+		output.Body.DeletedCount = resp.DeletedCount
+		return output, nil
+	})
+}
@@ -0,0 +1,121 @@
+package api
+
+import (
+	"log/slog"
+	"math"
+	"net/http"
+	"strconv"
+
+	"github.com/danielgtaylor/huma/v2"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Problem is the RFC 9457 problem details body of every error response.
+// Besides the per-field errors of huma.ErrorModel it carries the ErrorInfo and RetryInfo
+// details of the gRPC status, so clients can branch on Reason instead of parsing messages.
+type Problem struct {
+	huma.ErrorModel
+	Reason     string            `json:"reason,omitempty" example:"ACCOUNT_SUSPENDED" doc:"Machine readable cause of the error"`
+	Domain     string            `json:"domain,omitempty" example:"auth" doc:"Service that defines the reason"`
+	Metadata   map[string]string `json:"metadata,omitempty" doc:"Context of the reason, e.g. when a suspension expires"`
+	RetryAfter int64             `json:"retry_after,omitempty" doc:"Seconds to wait before retrying, also sent as the Retry-After header"`
+}
+
+// NewProblem replaces huma.NewError, so the errors written by Huma itself
+// and by MapGRPCError share the Problem schema in the OpenAPI spec
+func NewProblem(status int, msg string, errs ...error) huma.StatusError {
+	problem := &Problem{ErrorModel: huma.ErrorModel{
+		Status: status,
+		Title:  http.StatusText(status),
+		Detail: msg,
+	}}
+	for _, err := range errs {
+		if err != nil {
+			problem.Add(err)
+		}
+	}
+	return problem
+}
+
+// writeProblem writes the problem as the error response of a middleware,
+// huma.WriteErr only takes a message and would drop the other fields
+func writeProblem(api huma.API, ctx huma.Context, problem *Problem) {
+	ct, err := api.Negotiate(ctx.Header("Accept"))
+	if err != nil {
+		ct = "application/json"
+	}
+	ctx.SetHeader("Content-Type", problem.ContentType(ct))
+	ctx.SetStatus(problem.Status)
+
+	body, err := api.Transform(ctx, strconv.Itoa(problem.Status), problem)
+	if err == nil {
+		err = api.Marshal(ctx.BodyWriter(), ct, body)
+	}
+	if err != nil {
+		slog.ErrorContext(ctx.Context(), "failed to write error response", "error", err, "status", problem.Status)
+	}
+}
+
+// grpcStatus maps the gRPC codes to HTTP, codes missing here are internal errors
+var grpcStatus = map[codes.Code]int{
+	codes.InvalidArgument:    http.StatusBadRequest,
+	codes.OutOfRange:         http.StatusBadRequest,
+	codes.Unauthenticated:    http.StatusUnauthorized,
+	codes.PermissionDenied:   http.StatusForbidden,
+	codes.NotFound:           http.StatusNotFound,
+	codes.AlreadyExists:      http.StatusConflict,
+	codes.Aborted:            http.StatusConflict,
+	codes.FailedPrecondition: http.StatusPreconditionFailed,
+	codes.ResourceExhausted:  http.StatusTooManyRequests,
+	codes.Unimplemented:      http.StatusNotImplemented,
+	codes.Unavailable:        http.StatusServiceUnavailable,
+	codes.DeadlineExceeded:   http.StatusGatewayTimeout,
+}
+
+// MapGRPCError converts the error of a backend call into a Problem.
+// The errdetails of the status are carried through: BadRequest field violations become
+// the per-field errors (located in the request body), ErrorInfo the reason, domain and metadata,
+// RetryInfo the Retry-After header.
+func MapGRPCError(err error) error {
+	st, ok := status.FromError(err)
+	if !ok {
+		return huma.Error500InternalServerError("Unexpected error", err)
+	}
+
+	code, ok := grpcStatus[st.Code()]
+	if !ok {
+		code = http.StatusInternalServerError
+	}
+
+	problem := &Problem{ErrorModel: huma.ErrorModel{
+		Status: code,
+		Title:  http.StatusText(code),
+		Detail: st.Message(),
+	}}
+	var headers http.Header
+	for _, detail := range st.Details() {
+		switch detail := detail.(type) {
+		case *errdetails.BadRequest:
+			for _, violation := range detail.FieldViolations {
+				problem.Add(&huma.ErrorDetail{
+					Message:  violation.Description,
+					Location: "body." + violation.Field,
+				})
+			}
+		case *errdetails.ErrorInfo:
+			problem.Reason = detail.Reason
+			problem.Domain = detail.Domain
+			problem.Metadata = detail.Metadata
+		case *errdetails.RetryInfo:
+			problem.RetryAfter = int64(math.Ceil(detail.RetryDelay.AsDuration().Seconds()))
+			headers = http.Header{"Retry-After": []string{strconv.FormatInt(problem.RetryAfter, 10)}}
+		}
+	}
+
+	if headers != nil {
+		return huma.ErrorWithHeaders(problem, headers)
+	}
+	return problem
+}
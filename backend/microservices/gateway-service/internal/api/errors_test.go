@@ -0,0 +1,156 @@
+package api
+
+import (
+	"errors"
+	"maps"
+	"net/http"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/danielgtaylor/huma/v2"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/protoadapt"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+func statusWithDetails(t *testing.T, code codes.Code, msg string, details ...protoadapt.MessageV1) error {
+	t.Helper()
+	st, err := status.New(code, msg).WithDetails(details...)
+	if err != nil {
+		t.Fatalf("failed to add the details: %v", err)
+	}
+	return st.Err()
+}
+
+func TestMapGRPCErrorStatus(t *testing.T) {
+	tests := []struct {
+		code codes.Code
+		want int
+	}{
+		{code: codes.InvalidArgument, want: http.StatusBadRequest},
+		{code: codes.OutOfRange, want: http.StatusBadRequest},
+		{code: codes.Unauthenticated, want: http.StatusUnauthorized},
+		{code: codes.PermissionDenied, want: http.StatusForbidden},
+		{code: codes.NotFound, want: http.StatusNotFound},
+		{code: codes.AlreadyExists, want: http.StatusConflict},
+		{code: codes.Aborted, want: http.StatusConflict},
+		{code: codes.FailedPrecondition, want: http.StatusPreconditionFailed},
+		{code: codes.ResourceExhausted, want: http.StatusTooManyRequests},
+		{code: codes.Unimplemented, want: http.StatusNotImplemented},
+		{code: codes.Unavailable, want: http.StatusServiceUnavailable},
+		{code: codes.DeadlineExceeded, want: http.StatusGatewayTimeout},
+		{code: codes.Internal, want: http.StatusInternalServerError},
+		{code: codes.DataLoss, want: http.StatusInternalServerError},
+		{code: codes.Unknown, want: http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.code.String(), func(t *testing.T) {
+			err := MapGRPCError(status.Error(tt.code, "backend message"))
+
+			var problem *Problem
+			if !errors.As(err, &problem) {
+				t.Fatalf("MapGRPCError() = %T, want a *Problem", err)
+			}
+			if problem.Status != tt.want || problem.Title != http.StatusText(tt.want) {
+				t.Errorf("MapGRPCError() status = %d %q, want %d %q", problem.Status, problem.Title, tt.want, http.StatusText(tt.want))
+			}
+			if problem.Detail != "backend message" {
+				t.Errorf("MapGRPCError() detail = %q, want the status message", problem.Detail)
+			}
+		})
+	}
+}
+
+func TestMapGRPCErrorNotStatus(t *testing.T) {
+	err := MapGRPCError(errors.New("connection reset"))
+
+	var statusErr huma.StatusError
+	if !errors.As(err, &statusErr) || statusErr.GetStatus() != http.StatusInternalServerError {
+		t.Errorf("MapGRPCError() = %v, want a 500", err)
+	}
+}
+
+func TestMapGRPCErrorDetails(t *testing.T) {
+	tests := []struct {
+		name        string
+		err         func(t *testing.T) error
+		want        Problem
+		wantErrors  []*huma.ErrorDetail
+		wantHeaders http.Header
+	}{
+		{
+			name: "field violations",
+			err: func(t *testing.T) error {
+				return statusWithDetails(t, codes.AlreadyExists, "email already taken", &errdetails.BadRequest{
+					FieldViolations: []*errdetails.BadRequest_FieldViolation{{Field: "email", Description: "email already taken"}},
+				})
+			},
+			wantErrors: []*huma.ErrorDetail{{Message: "email already taken", Location: "body.email"}},
+		},
+		{
+			name: "error info",
+			err: func(t *testing.T) error {
+				return statusWithDetails(t, codes.PermissionDenied, "account suspended", &errdetails.ErrorInfo{
+					Reason:   "ACCOUNT_SUSPENDED",
+					Domain:   "auth",
+					Metadata: map[string]string{"reason": "spam"},
+				})
+			},
+			want: Problem{Reason: "ACCOUNT_SUSPENDED", Domain: "auth", Metadata: map[string]string{"reason": "spam"}},
+		},
+		{
+			name: "retry info rounded up to seconds",
+			err: func(t *testing.T) error {
+				return statusWithDetails(t, codes.ResourceExhausted, "too many failed login attempts",
+					&errdetails.RetryInfo{RetryDelay: durationpb.New(90*time.Second + 200*time.Millisecond)})
+			},
+			want:        Problem{RetryAfter: 91},
+			wantHeaders: http.Header{"Retry-After": []string{"91"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := MapGRPCError(tt.err(t))
+
+			var problem *Problem
+			if !errors.As(err, &problem) {
+				t.Fatalf("MapGRPCError() = %T, want a *Problem", err)
+			}
+			if problem.Reason != tt.want.Reason || problem.Domain != tt.want.Domain ||
+				!maps.Equal(problem.Metadata, tt.want.Metadata) || problem.RetryAfter != tt.want.RetryAfter {
+				t.Errorf("MapGRPCError() = %+v, want %+v", problem, tt.want)
+			}
+
+			if len(problem.Errors) != len(tt.wantErrors) {
+				t.Fatalf("MapGRPCError() errors = %v, want %v", problem.Errors, tt.wantErrors)
+			}
+			for i, want := range tt.wantErrors {
+				if got := problem.Errors[i]; got.Message != want.Message || got.Location != want.Location {
+					t.Errorf("MapGRPCError() errors[%d] = %+v, want %+v", i, got, want)
+				}
+			}
+
+			var headersErr huma.HeadersError
+			if !errors.As(err, &headersErr) {
+				if tt.wantHeaders != nil {
+					t.Fatalf("MapGRPCError() has no headers, want %v", tt.wantHeaders)
+				}
+				return
+			}
+			got := headersErr.GetHeaders()
+			for name := range tt.wantHeaders {
+				if !slices.Equal(got.Values(name), tt.wantHeaders.Values(name)) {
+					t.Errorf("MapGRPCError() header %s = %v, want %v", name, got.Values(name), tt.wantHeaders.Values(name))
+				}
+			}
+			if len(got) != len(tt.wantHeaders) {
+				t.Errorf("MapGRPCError() headers = %v, want %v", got, tt.wantHeaders)
+			}
+		})
+	}
+}
@@ -2,85 +2,209 @@ package api
 
 import (
 	"context"
-	"errors"
-	"fmt"
 	"log/slog"
+	"net"
 	"net/http"
+	"net/netip"
 	"strings"
 
 	"time"
 
-	"github.com/golang-jwt/jwt/v5"
+	"github.com/danielgtaylor/huma/v2"
 	"github.com/username/progetto/shared/pkg/deduplication"
+	"github.com/username/progetto/shared/pkg/grpcutil"
+	"github.com/username/progetto/shared/pkg/jwtutil"
 )
 
-func NewAdminMiddleware(jwtSecret string) func(http.Handler) http.Handler {
-	return func(next http.Handler) http.Handler {
-		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			// Only protect /admin* routes
-			if !strings.HasPrefix(r.URL.Path, "/admin") {
-				next.ServeHTTP(w, r)
-				return
-			}
+// BearerAuth is the OpenAPI security scheme of the access tokens issued by auth-service
+const BearerAuth = "bearer"
 
-			authHeader := r.Header.Get("Authorization")
-			if authHeader == "" {
-				slog.ErrorContext(r.Context(), "missing authorization header", "path", r.URL.Path)
-				http.Error(w, "authorization header required", http.StatusUnauthorized)
-				return
-			}
+// BearerSecurity is the security requirement of operations reserved to authenticated users.
+// NewAuthMiddleware authenticates every operation declaring it.
+var BearerSecurity = []map[string][]string{{BearerAuth: {}}}
 
-			parts := strings.Split(authHeader, " ")
-			if len(parts) != 2 || parts[0] != "Bearer" {
-				slog.ErrorContext(r.Context(), "invalid authorization header format", "path", r.URL.Path)
-				http.Error(w, "invalid authorization header format", http.StatusUnauthorized)
-				return
-			}
+// RequireVerifiedEmail is the operation metadata key reserving a route to users with a verified email
+const RequireVerifiedEmail = "requireVerifiedEmail"
 
-			tokenString := parts[1]
+// RequirePermission is the operation metadata key holding the permission a route requires, e.g. rbac.PermUsersManage
+const RequirePermission = "requirePermission"
 
-			token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-				if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-					return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
-				}
-				return []byte(jwtSecret), nil
-			})
+type claimsKey struct{}
 
-			if err != nil {
-				if errors.Is(err, jwt.ErrTokenExpired) {
-					slog.WarnContext(r.Context(), "token expired", "path", r.URL.Path)
-					http.Error(w, "token expired", http.StatusUnauthorized)
-					return
-				}
-				slog.ErrorContext(r.Context(), "invalid token", "path", r.URL.Path, "error", err)
-				http.Error(w, "invalid token", http.StatusUnauthorized)
-				return
-			}
+// ClaimsFromContext returns the access token claims of the user authenticated by NewAuthMiddleware
+func ClaimsFromContext(ctx context.Context) (*jwtutil.Claims, bool) {
+	claims, ok := ctx.Value(claimsKey{}).(*jwtutil.Claims)
+	return claims, ok
+}
 
-			if !token.Valid {
-				slog.ErrorContext(r.Context(), "invalid token", "path", r.URL.Path)
-				http.Error(w, "invalid token", http.StatusUnauthorized)
+// NewAuthMiddleware verifies the bearer token of operations declaring a security requirement.
+// The claims are stored in the request context and the user is forwarded to the backend
+// services in the service token of every gRPC call, see grpcutil.WithUserID.
+func NewAuthMiddleware(api huma.API, verifier jwtutil.Verifier, denylist jwtutil.Denylist) func(ctx huma.Context, next func(huma.Context)) {
+	return func(ctx huma.Context, next func(huma.Context)) {
+		if len(ctx.Operation().Security) == 0 {
+			next(ctx)
+			return
+		}
+
+		claims, ok := authenticateRequest(api, ctx, verifier, denylist)
+		if !ok {
+			return
+		}
+
+		reqCtx := context.WithValue(ctx.Context(), claimsKey{}, claims)
+		reqCtx = grpcutil.WithUserID(reqCtx, claims.UserID)
+		next(huma.WithContext(ctx, reqCtx))
+	}
+}
+
+// NewVerifiedEmailMiddleware rejects requests to operations flagged with RequireVerifiedEmail
+// unless the authenticated user has the email_verified claim.
+func NewVerifiedEmailMiddleware(api huma.API) func(ctx huma.Context, next func(huma.Context)) {
+	return func(ctx huma.Context, next func(huma.Context)) {
+		if required, _ := ctx.Operation().Metadata[RequireVerifiedEmail].(bool); !required {
+			next(ctx)
+			return
+		}
+
+		claims, ok := requireClaims(api, ctx)
+		if !ok {
+			return
+		}
+
+		if !claims.EmailVerified {
+			slog.WarnContext(ctx.Context(), "forbidden: email not verified", "path", ctx.URL().Path, "user_id", claims.UserID)
+			huma.WriteErr(api, ctx, http.StatusForbidden, "email verification required")
+			return
+		}
+
+		next(ctx)
+	}
+}
+
+// NewPermissionMiddleware rejects requests to operations declaring RequirePermission
+// unless the access token grants it. /admin operations without a permission are always rejected.
+func NewPermissionMiddleware(api huma.API) func(ctx huma.Context, next func(huma.Context)) {
+	return func(ctx huma.Context, next func(huma.Context)) {
+		permission, _ := ctx.Operation().Metadata[RequirePermission].(string)
+		if permission == "" {
+			if strings.HasPrefix(ctx.Operation().Path, "/admin") {
+				slog.ErrorContext(ctx.Context(), "admin operation without a required permission", "operation", ctx.Operation().OperationID)
+				huma.WriteErr(api, ctx, http.StatusForbidden, "forbidden")
 				return
 			}
+			next(ctx)
+			return
+		}
 
-			// Check for "role" claim
-			if claims, ok := token.Claims.(jwt.MapClaims); ok {
-				if role, ok := claims["role"].(string); ok {
-					if role == "admin" {
-						// Authorized
-						ctx := context.WithValue(r.Context(), "user_token", token)
-						next.ServeHTTP(w, r.WithContext(ctx))
-						return
-					}
-				}
-			}
+		claims, ok := requireClaims(api, ctx)
+		if !ok {
+			return
+		}
+
+		if !claims.HasPermission(permission) {
+			slog.WarnContext(ctx.Context(), "forbidden: missing permission", "path", ctx.URL().Path, "user_id", claims.UserID, "permission", permission)
+			huma.WriteErr(api, ctx, http.StatusForbidden, "forbidden: "+permission+" permission required")
+			return
+		}
 
-			slog.ErrorContext(r.Context(), "forbidden: admin role required", "path", r.URL.Path)
-			http.Error(w, "forbidden: admin role required", http.StatusForbidden)
+		next(ctx)
+	}
+}
+
+// requireClaims returns the claims set by NewAuthMiddleware. Operations with authorization
+// rules must declare BearerSecurity, otherwise the request is rejected.
+func requireClaims(api huma.API, ctx huma.Context) (*jwtutil.Claims, bool) {
+	claims, ok := ClaimsFromContext(ctx.Context())
+	if !ok {
+		slog.ErrorContext(ctx.Context(), "operation with authorization rules but no security requirement", "operation", ctx.Operation().OperationID)
+		huma.WriteErr(api, ctx, http.StatusUnauthorized, "authorization header required")
+		return nil, false
+	}
+	return claims, true
+}
+
+// authenticateRequest verifies the bearer token of the request, writing the error response when it is not valid
+func authenticateRequest(api huma.API, ctx huma.Context, verifier jwtutil.Verifier, denylist jwtutil.Denylist) (*jwtutil.Claims, bool) {
+	tokenString := strings.TrimPrefix(ctx.Header("Authorization"), "Bearer ")
+	if tokenString == "" {
+		huma.WriteErr(api, ctx, http.StatusUnauthorized, "authorization header required")
+		return nil, false
+	}
+
+	claims, err := verifier.ParseToken(ctx.Context(), tokenString)
+	if err != nil {
+		slog.WarnContext(ctx.Context(), "invalid token", "path", ctx.URL().Path, "error", err)
+		huma.WriteErr(api, ctx, http.StatusUnauthorized, "invalid or expired token")
+		return nil, false
+	}
+
+	// Reject tokens of sessions revoked by logout or ban
+	revoked, err := denylist.IsRevoked(ctx.Context(), claims.SessionID)
+	if err != nil {
+		slog.ErrorContext(ctx.Context(), "denylist check failed", "path", ctx.URL().Path, "error", err)
+		huma.WriteErr(api, ctx, http.StatusInternalServerError, "internal server error")
+		return nil, false
+	}
+	if revoked {
+		huma.WriteErr(api, ctx, http.StatusUnauthorized, "token revoked")
+		return nil, false
+	}
+	return claims, true
+}
+
+// NewClientInfoMiddleware captures the caller IP, user agent and device name
+// so they are forwarded to backend services with every gRPC call.
+// X-Forwarded-For is only honoured on requests coming from trustedProxies.
+func NewClientInfoMiddleware(trustedProxies []netip.Prefix) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx := grpcutil.WithClientInfo(r.Context(), grpcutil.ClientInfo{
+				IP:        clientIP(r, trustedProxies),
+				UserAgent: r.UserAgent(),
+				Device:    r.Header.Get("X-Device-Name"),
+			})
+			next.ServeHTTP(w, r.WithContext(ctx))
 		})
 	}
 }
 
+// clientIP walks X-Forwarded-For from the right, starting at the peer, and returns
+// the first hop that is not a trusted proxy. Hops further left are set by the client and can be forged.
+func clientIP(r *http.Request, trustedProxies []netip.Prefix) string {
+	ip := r.RemoteAddr
+	if host, _, err := net.SplitHostPort(r.RemoteAddr); err == nil {
+		ip = host
+	}
+
+	var hops []string
+	for _, header := range r.Header.Values("X-Forwarded-For") {
+		hops = append(hops, strings.Split(header, ",")...)
+	}
+	for i := len(hops) - 1; i >= 0; i-- {
+		addr, err := netip.ParseAddr(ip)
+		if err != nil || !isTrustedProxy(addr.Unmap(), trustedProxies) {
+			return ip
+		}
+		// A malformed hop was not written by a trusted proxy, the proxy that forwarded it is the client
+		hop := strings.TrimSpace(hops[i])
+		if _, err := netip.ParseAddr(hop); err != nil {
+			return ip
+		}
+		ip = hop
+	}
+	return ip
+}
+
+func isTrustedProxy(addr netip.Addr, trustedProxies []netip.Prefix) bool {
+	for _, prefix := range trustedProxies {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
 // NewDeduplicationMiddleware creates a middleware that deduplicates requests based on X-Request-ID header.
 func NewDeduplicationMiddleware(deduplicator deduplication.Deduplicator, ttl time.Duration) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
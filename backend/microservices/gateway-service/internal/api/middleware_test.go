@@ -0,0 +1,47 @@
+package api
+
+import (
+	"net/http/httptest"
+	"net/netip"
+	"testing"
+)
+
+func TestClientIP(t *testing.T) {
+	trusted := []netip.Prefix{
+		netip.MustParsePrefix("10.0.0.0/8"),
+		netip.MustParsePrefix("fd00::/8"),
+	}
+
+	tests := []struct {
+		name       string
+		remoteAddr string
+		forwarded  []string
+		trusted    []netip.Prefix
+		want       string
+	}{
+		{name: "no proxy", remoteAddr: "203.0.113.7:5123", want: "203.0.113.7"},
+		{name: "forwarded header from untrusted peer", remoteAddr: "203.0.113.7:5123", forwarded: []string{"198.51.100.1"}, trusted: trusted, want: "203.0.113.7"},
+		{name: "no trusted proxies configured", remoteAddr: "10.0.0.2:5123", forwarded: []string{"198.51.100.1"}, want: "10.0.0.2"},
+		{name: "trusted proxy", remoteAddr: "10.0.0.2:5123", forwarded: []string{"198.51.100.1"}, trusted: trusted, want: "198.51.100.1"},
+		{name: "spoofed leftmost hop", remoteAddr: "10.0.0.2:5123", forwarded: []string{"1.2.3.4, 198.51.100.1"}, trusted: trusted, want: "198.51.100.1"},
+		{name: "chain of trusted proxies", remoteAddr: "10.0.0.2:5123", forwarded: []string{"1.2.3.4, 198.51.100.1, 10.0.0.3"}, trusted: trusted, want: "198.51.100.1"},
+		{name: "multiple headers", remoteAddr: "10.0.0.2:5123", forwarded: []string{"1.2.3.4", "198.51.100.1"}, trusted: trusted, want: "198.51.100.1"},
+		{name: "malformed hop", remoteAddr: "10.0.0.2:5123", forwarded: []string{"not-an-ip"}, trusted: trusted, want: "10.0.0.2"},
+		{name: "only trusted hops", remoteAddr: "10.0.0.2:5123", forwarded: []string{"10.0.0.4, 10.0.0.3"}, trusted: trusted, want: "10.0.0.4"},
+		{name: "ipv6 proxy", remoteAddr: "[fd00::2]:5123", forwarded: []string{"2001:db8::1"}, trusted: trusted, want: "2001:db8::1"},
+		{name: "ipv4 mapped peer", remoteAddr: "[::ffff:10.0.0.2]:5123", forwarded: []string{"198.51.100.1"}, trusted: trusted, want: "198.51.100.1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest("GET", "/", nil)
+			r.RemoteAddr = tt.remoteAddr
+			for _, value := range tt.forwarded {
+				r.Header.Add("X-Forwarded-For", value)
+			}
+			if got := clientIP(r, tt.trusted); got != tt.want {
+				t.Errorf("clientIP() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -4,16 +4,16 @@ import (
 	"context"
 	"log/slog"
 	"net/http"
+	"time"
 
 	"github.com/danielgtaylor/huma/v2"
 	postv1 "github.com/username/progetto/proto/gen/go/post/v1"
-	"google.golang.org/grpc/codes"
-	"google.golang.org/grpc/status"
+	"github.com/username/progetto/shared/pkg/ratelimit"
 )
 
 type PostInput struct {
 	Body struct {
-		AuthorID  string   `json:"author_id" doc:"Author of the post"`
+		AuthorID  string   `json:"author_id,omitempty" deprecated:"true" doc:"Ignored, the author is the authenticated user"`
 		Content   string   `json:"content" doc:"Content of the post"`
 		MediaURLs []string `json:"media_urls" doc:"List of media URLs"`
 	}
@@ -38,6 +38,43 @@ type ListPostsOutput struct {
 	}
 }
 
+type HomeFeedInput struct {
+	Limit         int32  `query:"limit" doc:"Maximum number of posts to return" default:"20" maximum:"100"`
+	NextPageToken string `query:"anchorPage" doc:"Token for the next page of results"`
+}
+
+type UpdatePostInput struct {
+	ID   string `path:"id"`
+	Body struct {
+		Content   string   `json:"content" minLength:"1" doc:"New content of the post"`
+		MediaURLs []string `json:"media_urls,omitempty" doc:"Replaces the media, omit to remove them"`
+	}
+}
+
+type PostIDInput struct {
+	ID string `path:"id"`
+}
+
+type LikeOutput struct {
+	Body struct {
+		Success    bool  `json:"success"`
+		LikesCount int32 `json:"likes_count" doc:"Likes of the post after the change"`
+	}
+}
+
+type ListLikersInput struct {
+	ID            string `path:"id"`
+	Limit         int32  `query:"limit" doc:"Maximum number of likers to return" default:"20" maximum:"100"`
+	NextPageToken string `query:"anchorPage" doc:"Token for the next page of results"`
+}
+
+type ListLikersOutput struct {
+	Body struct {
+		Likers        []*postv1.Liker `json:"likers"`
+		NextPageToken string          `json:"anchorPage,omitempty" doc:"Empty on the last page"`
+	}
+}
+
 func RegisterPostRoutes(api huma.API, client postv1.PostServiceClient, logger *slog.Logger) {
 	huma.Register(api, huma.Operation{
 		OperationID: "create-post",
@@ -45,9 +82,13 @@ func RegisterPostRoutes(api huma.API, client postv1.PostServiceClient, logger *s
 		Path:        "/posts",
 		Summary:     "Create a post",
 		Tags:        []string{"Posts"},
+		Security:    BearerSecurity,
+		Metadata:    map[string]any{RequireVerifiedEmail: true, RateLimit: ratelimit.Limit{Algorithm: ratelimit.TokenBucket, Requests: 60, Period: time.Hour, Burst: 10}},
 	}, func(ctx context.Context, input *PostInput) (*PostOutput, error) {
+		// The author comes from the access token, never from the body
+		claims, _ := ClaimsFromContext(ctx)
 		resp, err := client.CreatePost(ctx, &postv1.CreatePostRequest{
-			AuthorId:  input.Body.AuthorID,
+			AuthorId:  claims.UserID,
 			Content:   input.Body.Content,
 			MediaUrls: input.Body.MediaURLs,
 		})
@@ -83,6 +124,54 @@ func RegisterPostRoutes(api huma.API, client postv1.PostServiceClient, logger *s
 		return output, nil
 	})
 
+	huma.Register(api, huma.Operation{
+		OperationID: "update-post",
+		Method:      http.MethodPut,
+		Path:        "/posts/{id}",
+		Summary:     "Edit a post",
+		Description: "Replaces the content and media of the post, the previous version is kept in its edit history.",
+		Tags:        []string{"Posts"},
+		Security:    BearerSecurity,
+		Metadata:    map[string]any{RequireVerifiedEmail: true, RateLimit: ratelimit.Limit{Algorithm: ratelimit.TokenBucket, Requests: 60, Period: time.Hour, Burst: 10}},
+	}, func(ctx context.Context, input *UpdatePostInput) (*PostOutput, error) {
+		claims, _ := ClaimsFromContext(ctx)
+		resp, err := client.UpdatePost(ctx, &postv1.UpdatePostRequest{
+			PostId:    input.ID,
+			UserId:    claims.UserID,
+			Content:   input.Body.Content,
+			MediaUrls: input.Body.MediaURLs,
+		})
+		if err != nil {
+			logger.WarnContext(ctx, "update post failed", "error", err, "post_id", input.ID)
+			return nil, MapGRPCError(err)
+		}
+
+		output := &PostOutput{}
+		output.Body.Post = resp.Post
+		return output, nil
+	})
+
+	huma.Register(api, huma.Operation{
+		OperationID:   "delete-post",
+		Method:        http.MethodDelete,
+		Path:          "/posts/{id}",
+		Summary:       "Delete a post",
+		Tags:          []string{"Posts"},
+		Security:      BearerSecurity,
+		DefaultStatus: http.StatusNoContent,
+	}, func(ctx context.Context, input *PostIDInput) (*struct{}, error) {
+		claims, _ := ClaimsFromContext(ctx)
+		_, err := client.DeletePost(ctx, &postv1.DeletePostRequest{
+			PostId: input.ID,
+			UserId: claims.UserID,
+		})
+		if err != nil {
+			logger.WarnContext(ctx, "delete post failed", "error", err, "post_id", input.ID)
+			return nil, MapGRPCError(err)
+		}
+		return nil, nil
+	})
+
 	huma.Register(api, huma.Operation{
 		OperationID: "list-posts",
 		Method:      http.MethodGet,
@@ -106,55 +195,103 @@ func RegisterPostRoutes(api huma.API, client postv1.PostServiceClient, logger *s
 		return output, nil
 	})
 
+	huma.Register(api, huma.Operation{
+		OperationID: "get-home-feed",
+		Method:      http.MethodGet,
+		Path:        "/feed",
+		Summary:     "Get the home feed",
+		Description: "Posts of the followed users and of the authenticated user, newest first. A page can be shorter than the limit, only an empty anchorPage ends the feed.",
+		Tags:        []string{"Posts"},
+		Security:    BearerSecurity,
+		Metadata:    map[string]any{RateLimit: ratelimit.Limit{Algorithm: ratelimit.SlidingWindow, Requests: 120, Period: time.Minute}},
+	}, func(ctx context.Context, input *HomeFeedInput) (*ListPostsOutput, error) {
+		claims, _ := ClaimsFromContext(ctx)
+		resp, err := client.GetHomeFeed(ctx, &postv1.GetHomeFeedRequest{
+			UserId:        claims.UserID,
+			Limit:         input.Limit,
+			NextPageToken: input.NextPageToken,
+		})
+		if err != nil {
+			logger.ErrorContext(ctx, "get home feed failed", "error", err)
+			return nil, MapGRPCError(err)
+		}
+
+		output := &ListPostsOutput{}
+		output.Body.Posts = resp.Posts
+		output.Body.NextPageToken = resp.NextPageToken
+		return output, nil
+	})
+
 	huma.Register(api, huma.Operation{
 		OperationID: "like-post",
 		Method:      http.MethodPost,
 		Path:        "/posts/{id}/like",
 		Summary:     "Like a post",
 		Tags:        []string{"Posts"},
-	}, func(ctx context.Context, input *struct {
-		ID     string `path:"id"`
-		UserID string `json:"user_id"`
-	}) (*struct {
-		Body struct {
-			Success bool `json:"success"`
-		}
-	}, error) {
-		_, err := client.LikePost(ctx, &postv1.LikePostRequest{
+		Security:    BearerSecurity,
+		Metadata:    map[string]any{RequireVerifiedEmail: true, RateLimit: ratelimit.Limit{Algorithm: ratelimit.TokenBucket, Requests: 600, Period: time.Hour, Burst: 30}},
+	}, func(ctx context.Context, input *PostIDInput) (*LikeOutput, error) {
+		claims, _ := ClaimsFromContext(ctx)
+		resp, err := client.LikePost(ctx, &postv1.LikePostRequest{
 			PostId: input.ID,
-			UserId: input.UserID,
+			UserId: claims.UserID,
 		})
 		if err != nil {
 			logger.ErrorContext(ctx, "like post failed", "error", err)
 			return nil, MapGRPCError(err)
 		}
 
-		return &struct {
-			Body struct {
-				Success bool `json:"success"`
-			}
-		}{Body: struct {
-			Success bool `json:"success"`
-		}{Success: true}}, nil
+		output := &LikeOutput{}
+		output.Body.Success = resp.Success
+		output.Body.LikesCount = resp.NewLikesCount
+		return output, nil
 	})
-}
 
-func MapGRPCError(err error) error {
-	st, ok := status.FromError(err)
-	if !ok {
-		return huma.Error500InternalServerError("Unexpected error", err)
-	}
+	huma.Register(api, huma.Operation{
+		OperationID: "unlike-post",
+		Method:      http.MethodDelete,
+		Path:        "/posts/{id}/like",
+		Summary:     "Unlike a post",
+		Tags:        []string{"Posts"},
+		Security:    BearerSecurity,
+		Metadata:    map[string]any{RateLimit: ratelimit.Limit{Algorithm: ratelimit.TokenBucket, Requests: 600, Period: time.Hour, Burst: 30}},
+	}, func(ctx context.Context, input *PostIDInput) (*LikeOutput, error) {
+		claims, _ := ClaimsFromContext(ctx)
+		resp, err := client.UnlikePost(ctx, &postv1.UnlikePostRequest{
+			PostId: input.ID,
+			UserId: claims.UserID,
+		})
+		if err != nil {
+			logger.ErrorContext(ctx, "unlike post failed", "error", err)
+			return nil, MapGRPCError(err)
+		}
 
-	switch st.Code() {
-	case codes.NotFound:
-		return huma.Error404NotFound(st.Message())
-	case codes.InvalidArgument:
-		return huma.Error400BadRequest(st.Message())
-	case codes.Unauthenticated:
-		return huma.Error401Unauthorized(st.Message())
-	case codes.PermissionDenied:
-		return huma.Error403Forbidden(st.Message())
-	default:
-		return huma.Error500InternalServerError(st.Message())
-	}
+		output := &LikeOutput{}
+		output.Body.Success = resp.Success
+		output.Body.LikesCount = resp.NewLikesCount
+		return output, nil
+	})
+
+	huma.Register(api, huma.Operation{
+		OperationID: "list-post-likers",
+		Method:      http.MethodGet,
+		Path:        "/posts/{id}/likes",
+		Summary:     "List the users who like a post",
+		Tags:        []string{"Posts"},
+	}, func(ctx context.Context, input *ListLikersInput) (*ListLikersOutput, error) {
+		resp, err := client.ListPostLikers(ctx, &postv1.ListPostLikersRequest{
+			PostId:        input.ID,
+			Limit:         input.Limit,
+			NextPageToken: input.NextPageToken,
+		})
+		if err != nil {
+			logger.WarnContext(ctx, "list post likers failed", "error", err, "post_id", input.ID)
+			return nil, MapGRPCError(err)
+		}
+
+		output := &ListLikersOutput{}
+		output.Body.Likers = resp.Likers
+		output.Body.NextPageToken = resp.NextPageToken
+		return output, nil
+	})
 }
@@ -0,0 +1,109 @@
+package api
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+	"reflect"
+	"strconv"
+
+	"github.com/danielgtaylor/huma/v2"
+	"github.com/username/progetto/shared/pkg/grpcutil"
+	"github.com/username/progetto/shared/pkg/ratelimit"
+)
+
+// RateLimit is the operation metadata key holding the ratelimit.Limit of a route.
+// Authenticated operations are limited per user, the others per client IP.
+const RateLimit = "rateLimit"
+
+// NewRateLimitMiddleware enforces the RateLimit of each operation. It must run after
+// NewAuthMiddleware, which identifies the user. The IP limit of the whole API is set on the router.
+func NewRateLimitMiddleware(api huma.API, limiter ratelimit.Limiter) func(ctx huma.Context, next func(huma.Context)) {
+	return func(ctx huma.Context, next func(huma.Context)) {
+		limit, ok := ctx.Operation().Metadata[RateLimit].(ratelimit.Limit)
+		if !ok {
+			next(ctx)
+			return
+		}
+
+		key := "ip:" + grpcutil.ClientInfoFromContext(ctx.Context()).IP
+		if claims, ok := ClaimsFromContext(ctx.Context()); ok {
+			key = "user:" + claims.UserID
+		}
+		res, err := limiter.Allow(ctx.Context(), ctx.Operation().OperationID+":"+key, limit)
+		if err != nil {
+			// Fail open, a Redis outage must not take the API down
+			slog.ErrorContext(ctx.Context(), "rate limit check failed", "error", err, "operation", ctx.Operation().OperationID)
+			next(ctx)
+			return
+		}
+
+		headers := http.Header{}
+		ratelimit.SetHeaders(headers, limit, res)
+		for name := range headers {
+			ctx.SetHeader(name, headers.Get(name))
+		}
+		if !res.Allowed {
+			slog.WarnContext(ctx.Context(), "rate limit exceeded", "operation", ctx.Operation().OperationID, "key", key)
+			retryAfter, _ := strconv.ParseInt(headers.Get("Retry-After"), 10, 64)
+			writeProblem(api, ctx, &Problem{
+				ErrorModel: huma.ErrorModel{
+					Status: http.StatusTooManyRequests,
+					Title:  http.StatusText(http.StatusTooManyRequests),
+					Detail: "rate limit exceeded",
+				},
+				Reason:     "RATE_LIMITED",
+				Domain:     "gateway",
+				RetryAfter: retryAfter,
+			})
+			return
+		}
+
+		next(ctx)
+	}
+}
+
+// DocumentRateLimit is an OnAddOperation hook adding the RateLimit of an operation to the OpenAPI spec,
+// as the x-ratelimit extension and a 429 response with the RateLimit headers
+func DocumentRateLimit(oapi *huma.OpenAPI, op *huma.Operation) {
+	limit, ok := op.Metadata[RateLimit].(ratelimit.Limit)
+	if !ok {
+		return
+	}
+
+	scope := "ip"
+	if len(op.Security) > 0 {
+		scope = "user"
+	}
+	if op.Extensions == nil {
+		op.Extensions = map[string]any{}
+	}
+	op.Extensions["x-ratelimit"] = map[string]any{
+		"algorithm": limit.Algorithm,
+		"requests":  limit.Requests,
+		"period":    limit.Period.String(),
+		"burst":     limit.Quota(),
+		"scope":     scope,
+	}
+	op.Description += fmt.Sprintf("\n\nRate limited to %d requests per %s per %s.", limit.Requests, limit.Period, scope)
+
+	if op.Responses == nil {
+		op.Responses = map[string]*huma.Response{}
+	}
+	intHeader := func(doc string) *huma.Param {
+		return &huma.Param{Description: doc, Schema: &huma.Schema{Type: huma.TypeInteger}}
+	}
+	op.Responses["429"] = &huma.Response{
+		Description: "Rate limit exceeded",
+		Headers: map[string]*huma.Param{
+			"RateLimit-Policy":    {Description: "Quota and window in seconds, e.g. 10;w=60", Schema: &huma.Schema{Type: huma.TypeString}},
+			"RateLimit-Limit":     intHeader("Requests allowed in the window"),
+			"RateLimit-Remaining": intHeader("Requests left in the window"),
+			"RateLimit-Reset":     intHeader("Seconds until the quota is fully available"),
+			"Retry-After":         intHeader("Seconds to wait before retrying"),
+		},
+		Content: map[string]*huma.MediaType{
+			"application/problem+json": {Schema: oapi.Components.Schemas.Schema(reflect.TypeOf(&Problem{}), true, "")},
+		},
+	}
+}
@@ -0,0 +1,56 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/danielgtaylor/huma/v2"
+	"github.com/danielgtaylor/huma/v2/humatest"
+	"github.com/username/progetto/shared/pkg/ratelimit"
+)
+
+// fakeLimiter returns the same result for every request
+type fakeLimiter struct {
+	result ratelimit.Result
+}
+
+func (l *fakeLimiter) Allow(context.Context, string, ratelimit.Limit) (ratelimit.Result, error) {
+	return l.result, nil
+}
+
+func TestRateLimitMiddlewareRejected(t *testing.T) {
+	_, api := humatest.New(t)
+	limiter := &fakeLimiter{result: ratelimit.Result{Limit: 1, RetryAfter: 1500 * time.Millisecond, Reset: 2 * time.Second}}
+	api.UseMiddleware(NewRateLimitMiddleware(api, limiter))
+	huma.Register(api, huma.Operation{
+		OperationID: "ping",
+		Method:      http.MethodGet,
+		Path:        "/ping",
+		Metadata:    map[string]any{RateLimit: ratelimit.Limit{Algorithm: ratelimit.SlidingWindow, Requests: 1, Period: time.Minute}},
+	}, func(context.Context, *struct{}) (*struct{}, error) {
+		t.Errorf("rate limited operation called")
+		return nil, nil
+	})
+
+	resp := api.Get("/ping")
+	if resp.Code != http.StatusTooManyRequests {
+		t.Fatalf("status = %d, want %d", resp.Code, http.StatusTooManyRequests)
+	}
+	if got := resp.Header().Get("Retry-After"); got != "2" {
+		t.Errorf("Retry-After = %q, want %q", got, "2")
+	}
+	if got := resp.Header().Get("Content-Type"); got != "application/problem+json" {
+		t.Errorf("Content-Type = %q, want %q", got, "application/problem+json")
+	}
+
+	var problem Problem
+	if err := json.Unmarshal(resp.Body.Bytes(), &problem); err != nil {
+		t.Fatalf("failed to decode the problem: %v", err)
+	}
+	if problem.Status != http.StatusTooManyRequests || problem.Reason != "RATE_LIMITED" || problem.Domain != "gateway" || problem.RetryAfter != 2 {
+		t.Errorf("problem = %+v, want a 429 RATE_LIMITED problem retrying after 2 seconds", problem)
+	}
+}
@@ -4,9 +4,11 @@ import (
 	"context"
 	"log/slog"
 	"net/http"
+	"time"
 
 	"github.com/danielgtaylor/huma/v2"
 	searchv1 "github.com/username/progetto/proto/gen/go/search/v1"
+	"github.com/username/progetto/shared/pkg/ratelimit"
 )
 
 type SearchUsersInput struct {
@@ -35,6 +37,7 @@ func RegisterSearchRoutes(api huma.API, searchClient searchv1.SearchServiceClien
 		Path:        "/search-users",
 		Summary:     "Search users",
 		Tags:        []string{"Search"},
+		Metadata:    map[string]any{RateLimit: ratelimit.Limit{Algorithm: ratelimit.SlidingWindow, Requests: 60, Period: time.Minute}},
 	}, func(ctx context.Context, input *SearchUsersInput) (*SearchUsersOutput, error) {
 		resp, err := searchClient.SearchUsers(ctx, &searchv1.SearchUsersRequest{
 			Query:  input.Q,
@@ -42,8 +45,8 @@ func RegisterSearchRoutes(api huma.API, searchClient searchv1.SearchServiceClien
 			Offset: input.Offset,
 		})
 		if err != nil {
-			logger.Error("failed to search users", "error", err)
-			return nil, huma.Error500InternalServerError("failed to search users")
+			logger.ErrorContext(ctx, "failed to search users", "error", err)
+			return nil, MapGRPCError(err)
 		}
 
 		users := make([]UserResult, 0, len(resp.Users))
@@ -0,0 +1,267 @@
+package api
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/danielgtaylor/huma/v2"
+	authv1 "github.com/username/progetto/proto/gen/go/auth/v1"
+	"github.com/username/progetto/shared/pkg/ratelimit"
+)
+
+// Profile is the public view of a user
+type Profile struct {
+	ID          string   `json:"id"`
+	Username    string   `json:"username"`
+	DisplayName string   `json:"display_name"`
+	Bio         string   `json:"bio"`
+	AvatarURL   string   `json:"avatar_url"`
+	BookGenres  []string `json:"book_genres"`
+	FilmGenres  []string `json:"film_genres"`
+	MusicGenres []string `json:"music_genres"`
+	CreatedAt   int64    `json:"created_at" doc:"Unix seconds"`
+}
+
+// Account is the profile plus the private fields, only returned to its owner
+type Account struct {
+	Profile
+	Email         string `json:"email"`
+	EmailVerified bool   `json:"email_verified"`
+	Role          string `json:"role"`
+	Version       int64  `json:"version"`
+}
+
+type GetUserInput struct {
+	ID string `path:"id" doc:"User ID"`
+}
+
+type GetUserOutput struct {
+	Body Profile
+}
+
+type UpdateProfileInput struct {
+	Authorization string `header:"Authorization" doc:"Bearer access token" required:"true"`
+	Body          struct {
+		DisplayName *string  `json:"display_name,omitempty" maxLength:"50"`
+		Bio         *string  `json:"bio,omitempty" maxLength:"300"`
+		AvatarURL   *string  `json:"avatar_url,omitempty" doc:"Empty string removes the avatar"`
+		BookGenres  []string `json:"book_genres,omitempty" maxItems:"10" doc:"Omit to keep, empty list to clear"`
+		FilmGenres  []string `json:"film_genres,omitempty" maxItems:"10" doc:"Omit to keep, empty list to clear"`
+		MusicGenres []string `json:"music_genres,omitempty" maxItems:"10" doc:"Omit to keep, empty list to clear"`
+	}
+}
+
+type ChangeUsernameInput struct {
+	Authorization string `header:"Authorization" doc:"Bearer access token" required:"true"`
+	Body          struct {
+		Username string `json:"username" minLength:"3" maxLength:"32"`
+	}
+}
+
+type ChangeEmailInput struct {
+	Authorization string `header:"Authorization" doc:"Bearer access token" required:"true"`
+	Body          struct {
+		NewEmail string `json:"new_email" format:"email"`
+		Password string `json:"password" doc:"Current password"`
+	}
+}
+
+type AccountOutput struct {
+	Body Account
+}
+
+type DeleteAccountInput struct {
+	Authorization string `header:"Authorization" doc:"Bearer access token" required:"true"`
+	Body          struct {
+		Password string `json:"password" doc:"Current password"`
+	}
+}
+
+type DeleteAccountOutput struct {
+	Body struct {
+		DeletionID string `json:"deletion_id" doc:"Follow the progress on /account-deletions/{id}"`
+	}
+}
+
+type GetAccountDeletionInput struct {
+	Authorization string `header:"Authorization" doc:"Bearer access token of the deleted user" required:"true"`
+	ID            string `path:"id" doc:"Deletion ID"`
+}
+
+type GetAccountDeletionOutput struct {
+	Body struct {
+		DeletionID      string   `json:"deletion_id"`
+		Status          string   `json:"status" enum:"pending,completed"`
+		PendingServices []string `json:"pending_services" doc:"Services that did not erase their data yet"`
+		Attempts        int32    `json:"attempts"`
+		RequestedAt     int64    `json:"requested_at" doc:"Unix seconds"`
+		CompletedAt     int64    `json:"completed_at,omitempty" doc:"Unix seconds"`
+	}
+}
+
+func profileFromProto(user *authv1.User) Profile {
+	return Profile{
+		ID:          user.UserId,
+		Username:    user.Username,
+		DisplayName: user.DisplayName,
+		Bio:         user.Bio,
+		AvatarURL:   user.AvatarUrl,
+		BookGenres:  user.BookGenres,
+		FilmGenres:  user.FilmGenres,
+		MusicGenres: user.MusicGenres,
+		CreatedAt:   user.CreatedAt,
+	}
+}
+
+func accountOutput(user *authv1.User) *AccountOutput {
+	return &AccountOutput{Body: Account{
+		Profile:       profileFromProto(user),
+		Email:         user.Email,
+		EmailVerified: user.EmailVerified,
+		Role:          user.Role,
+		Version:       user.Version,
+	}}
+}
+
+func genreList(genres []string) *authv1.GenreList {
+	if genres == nil {
+		return nil
+	}
+	return &authv1.GenreList{Genres: genres}
+}
+
+func RegisterUserRoutes(api huma.API, client authv1.AuthServiceClient, logger *slog.Logger) {
+	huma.Register(api, huma.Operation{
+		OperationID: "get-user",
+		Method:      http.MethodGet,
+		Path:        "/users/{id}",
+		Summary:     "Get the public profile of a user",
+		Tags:        []string{"Users"},
+	}, func(ctx context.Context, input *GetUserInput) (*GetUserOutput, error) {
+		resp, err := client.GetUser(ctx, &authv1.GetUserRequest{UserId: input.ID})
+		if err != nil {
+			logger.ErrorContext(ctx, "get user failed", "error", err, "user_id", input.ID)
+			return nil, MapGRPCError(err)
+		}
+		return &GetUserOutput{Body: profileFromProto(resp.User)}, nil
+	})
+
+	huma.Register(api, huma.Operation{
+		OperationID: "update-profile",
+		Method:      http.MethodPatch,
+		Path:        "/users/me/profile",
+		Summary:     "Update the profile of the current user",
+		Description: "Only the fields present in the body are changed.",
+		Tags:        []string{"Users"},
+		Security:    BearerSecurity,
+	}, func(ctx context.Context, input *UpdateProfileInput) (*AccountOutput, error) {
+		resp, err := client.UpdateProfile(ctx, &authv1.UpdateProfileRequest{
+			AccessToken: strings.TrimPrefix(input.Authorization, "Bearer "),
+			DisplayName: input.Body.DisplayName,
+			Bio:         input.Body.Bio,
+			AvatarUrl:   input.Body.AvatarURL,
+			BookGenres:  genreList(input.Body.BookGenres),
+			FilmGenres:  genreList(input.Body.FilmGenres),
+			MusicGenres: genreList(input.Body.MusicGenres),
+		})
+		if err != nil {
+			logger.ErrorContext(ctx, "update profile failed", "error", err)
+			return nil, MapGRPCError(err)
+		}
+		return accountOutput(resp.User), nil
+	})
+
+	huma.Register(api, huma.Operation{
+		OperationID: "change-username",
+		Method:      http.MethodPut,
+		Path:        "/users/me/username",
+		Summary:     "Change the username of the current user",
+		Tags:        []string{"Users"},
+		Security:    BearerSecurity,
+	}, func(ctx context.Context, input *ChangeUsernameInput) (*AccountOutput, error) {
+		resp, err := client.ChangeUsername(ctx, &authv1.ChangeUsernameRequest{
+			AccessToken: strings.TrimPrefix(input.Authorization, "Bearer "),
+			Username:    input.Body.Username,
+		})
+		if err != nil {
+			logger.ErrorContext(ctx, "change username failed", "error", err)
+			return nil, MapGRPCError(err)
+		}
+		return accountOutput(resp.User), nil
+	})
+
+	huma.Register(api, huma.Operation{
+		OperationID: "change-email",
+		Method:      http.MethodPut,
+		Path:        "/users/me/email",
+		Summary:     "Change the email of the current user",
+		Description: "A confirmation email is sent to the new address, the current one stays until the link is used.",
+		Tags:        []string{"Users"},
+		Metadata:    map[string]any{RateLimit: ratelimit.Limit{Algorithm: ratelimit.SlidingWindow, Requests: 5, Period: time.Hour}},
+		Security:    BearerSecurity,
+	}, func(ctx context.Context, input *ChangeEmailInput) (*AccountOutput, error) {
+		resp, err := client.ChangeEmail(ctx, &authv1.ChangeEmailRequest{
+			AccessToken: strings.TrimPrefix(input.Authorization, "Bearer "),
+			NewEmail:    input.Body.NewEmail,
+			Password:    input.Body.Password,
+		})
+		if err != nil {
+			logger.ErrorContext(ctx, "change email failed", "error", err)
+			return nil, MapGRPCError(err)
+		}
+		return accountOutput(resp.User), nil
+	})
+
+	huma.Register(api, huma.Operation{
+		OperationID:   "delete-account",
+		Method:        http.MethodDelete,
+		Path:          "/users/me",
+		Summary:       "Delete the current user",
+		Description:   "The account is disabled right away, the data is erased from every service in the background.",
+		Tags:          []string{"Users"},
+		Security:      BearerSecurity,
+		DefaultStatus: http.StatusAccepted,
+	}, func(ctx context.Context, input *DeleteAccountInput) (*DeleteAccountOutput, error) {
+		resp, err := client.DeleteAccount(ctx, &authv1.DeleteAccountRequest{
+			AccessToken: strings.TrimPrefix(input.Authorization, "Bearer "),
+			Password:    input.Body.Password,
+		})
+		if err != nil {
+			logger.ErrorContext(ctx, "delete account failed", "error", err)
+			return nil, MapGRPCError(err)
+		}
+		out := &DeleteAccountOutput{}
+		out.Body.DeletionID = resp.DeletionId
+		return out, nil
+	})
+
+	huma.Register(api, huma.Operation{
+		OperationID: "get-account-deletion",
+		Method:      http.MethodGet,
+		Path:        "/account-deletions/{id}",
+		Summary:     "Get the progress of an account deletion",
+		Description: "Only the deleted user can follow its deletion. The deletion revokes every session, " +
+			"so the access token used to delete the account is accepted until it expires.",
+		Tags: []string{"Users"},
+	}, func(ctx context.Context, input *GetAccountDeletionInput) (*GetAccountDeletionOutput, error) {
+		resp, err := client.GetAccountDeletion(ctx, &authv1.GetAccountDeletionRequest{
+			DeletionId:  input.ID,
+			AccessToken: strings.TrimPrefix(input.Authorization, "Bearer "),
+		})
+		if err != nil {
+			logger.ErrorContext(ctx, "get account deletion failed", "error", err, "deletion_id", input.ID)
+			return nil, MapGRPCError(err)
+		}
+		out := &GetAccountDeletionOutput{}
+		out.Body.DeletionID = resp.DeletionId
+		out.Body.Status = resp.Status
+		out.Body.PendingServices = resp.PendingServices
+		out.Body.Attempts = resp.Attempts
+		out.Body.RequestedAt = resp.RequestedAt
+		out.Body.CompletedAt = resp.CompletedAt
+		return out, nil
+	})
+}
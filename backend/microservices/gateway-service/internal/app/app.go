@@ -25,7 +25,9 @@ import (
 	"github.com/username/progetto/shared/pkg/database/redis"
 	"github.com/username/progetto/shared/pkg/deduplication"
 	"github.com/username/progetto/shared/pkg/grpcutil"
+	"github.com/username/progetto/shared/pkg/jwtutil"
 	"github.com/username/progetto/shared/pkg/observability"
+	"github.com/username/progetto/shared/pkg/ratelimit"
 	"github.com/username/progetto/shared/pkg/watermillutil"
 	"google.golang.org/grpc"
 )
@@ -58,21 +60,26 @@ func New(cfg *config.Config, logger *slog.Logger) (*App, error) {
 	// 2. Deduplicator
 	dedup := deduplication.NewRedisDeduplicator(rdb, "gateway:dedup")
 
-	// 3. gRPC Clients
-	postConn, err := grpcutil.NewClient(cfg.PostService, "post-service")
+	// 3. gRPC Clients, authenticated as the gateway
+	identity, err := grpcutil.NewServiceIdentity(cfg.ServiceName, cfg.ServiceKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load service identity: %w", err)
+	}
+
+	postConn, err := grpcutil.NewClient(cfg.PostService, "post-service", grpcutil.WithServiceIdentity(identity)...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to connect to post-service: %w", err)
 	}
 	postClient := postv1.NewPostServiceClient(postConn)
 
-	authConn, err := grpcutil.NewClient(cfg.AuthService, "auth-service")
+	authConn, err := grpcutil.NewClient(cfg.AuthService, "auth-service", grpcutil.WithServiceIdentity(identity)...)
 	if err != nil {
 		postConn.Close()
 		return nil, fmt.Errorf("failed to connect to auth-service: %w", err)
 	}
 	authClient := authv1.NewAuthServiceClient(authConn)
 
-	searchConn, err := grpcutil.NewClient(cfg.SearchService, "search-service")
+	searchConn, err := grpcutil.NewClient(cfg.SearchService, "search-service", grpcutil.WithServiceIdentity(identity)...)
 	if err != nil {
 		postConn.Close()
 		authConn.Close()
@@ -80,8 +87,10 @@ func New(cfg *config.Config, logger *slog.Logger) (*App, error) {
 	}
 	searchClient := searchv1.NewSearchServiceClient(searchConn)
 
-	// 4. SSE Handler
-	sseHandler := sse.NewHandler(rdb, cfg.JWTSecret)
+	// 4. Token verification & SSE Handler
+	verifier := jwtutil.NewJWKSVerifier(api.NewJWKSFetcher(authClient), 5*time.Minute)
+	denylist := jwtutil.NewRedisDenylist(rdb)
+	sseHandler := sse.NewHandler(rdb, verifier, denylist)
 
 	// 5. Watermill (Kafka)
 	pub, err := watermillutil.NewKafkaPublisher(cfg.KafkaBrokers, logger)
@@ -108,18 +117,42 @@ func New(cfg *config.Config, logger *slog.Logger) (*App, error) {
 	router.Use(otelchi.Middleware("gateway-service", otelchi.WithChiRoutes(router)))
 	router.Use(observability.MiddlewareMetrics)
 	router.Use(api.NewLoggingMiddleware(logger))
+	router.Use(api.NewClientInfoMiddleware(cfg.TrustedProxies))
+	// Per IP limit of the whole API, before any work is done for the request
+	limiter := ratelimit.NewRedisLimiter(rdb, "gateway:ratelimit")
+	router.Use(ratelimit.Middleware(limiter, ratelimit.Limit{
+		Algorithm: ratelimit.SlidingWindow,
+		Requests:  cfg.IPRateLimit,
+		Period:    cfg.IPRateLimitWindow,
+	}, func(r *http.Request) string {
+		return "ip:" + grpcutil.ClientInfoFromContext(r.Context()).IP
+	}))
 	router.Use(api.NewDeduplicationMiddleware(dedup, 10*time.Minute))
-	router.Use(api.NewAdminMiddleware(cfg.JWTSecret))
 
 	// SSE Route
 	router.Get("/events", sseHandler.ServeHTTP)
 
-	humaAPI := humachi.New(router, huma.DefaultConfig("Gateway API", "1.0.0"))
+	// Errors are RFC 9457 problem details carrying the gRPC error details, see api.MapGRPCError
+	huma.NewError = api.NewProblem
+	humaConfig := huma.DefaultConfig("Gateway API", "1.0.0")
+	humaConfig.Components.SecuritySchemes = map[string]*huma.SecurityScheme{
+		api.BearerAuth: {Type: "http", Scheme: "bearer", BearerFormat: "JWT"},
+	}
+	humaConfig.OnAddOperation = append(humaConfig.OnAddOperation, api.DocumentRateLimit)
+	humaAPI := humachi.New(router, humaConfig)
+	// Authentication runs first, the other middlewares read the claims it stores
+	humaAPI.UseMiddleware(api.NewAuthMiddleware(humaAPI, verifier, denylist))
+	humaAPI.UseMiddleware(api.NewRateLimitMiddleware(humaAPI, limiter))
+	humaAPI.UseMiddleware(api.NewVerifiedEmailMiddleware(humaAPI))
+	humaAPI.UseMiddleware(api.NewPermissionMiddleware(humaAPI))
 
 	// Register Routes
 	api.RegisterPostRoutes(humaAPI, postClient, logger)
+	api.RegisterCommentRoutes(humaAPI, postClient, logger)
 	api.RegisterAuthRoutes(humaAPI, authClient, logger)
+	api.RegisterUserRoutes(humaAPI, authClient, logger)
 	api.RegisterSearchRoutes(humaAPI, searchClient, logger)
+	api.RegisterAdminRoutes(humaAPI, authClient, logger)
 
 	// Ping Route
 	huma.Register(humaAPI, huma.Operation{
@@ -1,8 +1,12 @@
 package config
 
 import (
+	"log/slog"
+	"net/netip"
 	"os"
 	"strconv"
+	"strings"
+	"time"
 )
 
 type Config struct {
@@ -12,15 +16,23 @@ type Config struct {
 	SearchService        string
 	KafkaBrokers         string
 	RedisAddr            string
-	JWTSecret            string
+	ServiceName          string
+	ServiceKey           string
+	IPRateLimit          int
+	IPRateLimitWindow    time.Duration
+	TrustedProxies       []netip.Prefix
 	OtelServiceName      string
 	OtelExporterEndpoint string
 }
 
 func Load() *Config {
 	cfg := &Config{
-		Port:            8888, // Default
-		OtelServiceName: "gateway-service",
+		Port:        8888, // Default
+		ServiceName: "gateway-service",
+		// Requests per window of each client IP on the whole API, routes can set stricter limits
+		IPRateLimit:       600,
+		IPRateLimitWindow: time.Minute,
+		OtelServiceName:   "gateway-service",
 	}
 
 	if val := os.Getenv("PORT"); val != "" {
@@ -43,8 +55,32 @@ func Load() *Config {
 	if envRedis := os.Getenv("APP_REDIS_ADDR"); envRedis != "" {
 		cfg.RedisAddr = envRedis
 	}
-	if envJWT := os.Getenv("APP_JWT_SECRET"); envJWT != "" {
-		cfg.JWTSecret = envJWT
+	// Identity presented to the backend services, see grpcutil.NewServiceIdentity
+	if val := os.Getenv("APP_SERVICE_NAME"); val != "" {
+		cfg.ServiceName = val
+	}
+	cfg.ServiceKey = os.Getenv("APP_SERVICE_KEY")
+	if val := os.Getenv("APP_IP_RATE_LIMIT"); val != "" {
+		if n, err := strconv.Atoi(val); err == nil {
+			cfg.IPRateLimit = n
+		}
+	}
+	if val := os.Getenv("APP_IP_RATE_LIMIT_WINDOW"); val != "" {
+		if d, err := time.ParseDuration(val); err == nil {
+			cfg.IPRateLimitWindow = d
+		}
+	}
+	// CIDRs of the proxies in front of the gateway, comma separated, see api.NewClientInfoMiddleware
+	for _, val := range strings.Split(os.Getenv("APP_TRUSTED_PROXIES"), ",") {
+		if val = strings.TrimSpace(val); val == "" {
+			continue
+		}
+		prefix, err := netip.ParsePrefix(val)
+		if err != nil {
+			slog.Warn("ignoring invalid trusted proxy", "value", val, "error", err)
+			continue
+		}
+		cfg.TrustedProxies = append(cfg.TrustedProxies, prefix.Masked())
 	}
 	if val := os.Getenv("OTEL_SERVICE_NAME"); val != "" {
 		cfg.OtelServiceName = val
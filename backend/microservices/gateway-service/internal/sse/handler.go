@@ -31,10 +31,11 @@ type Handler struct {
 	lock       sync.RWMutex
 	rdb        *redis.Client
 	instanceID string
-	jwtSecret  []byte
+	verifier   jwtutil.Verifier
+	denylist   jwtutil.Denylist
 }
 
-func NewHandler(rdb *redis.Client, jwtSecret string) *Handler {
+func NewHandler(rdb *redis.Client, verifier jwtutil.Verifier, denylist jwtutil.Denylist) *Handler {
 	id := os.Getenv("HOSTNAME")
 	if id == "" {
 		id = uuid.New().String()
@@ -44,7 +45,8 @@ func NewHandler(rdb *redis.Client, jwtSecret string) *Handler {
 		clients:    make(map[string]chan Event),
 		rdb:        rdb,
 		instanceID: id,
-		jwtSecret:  []byte(jwtSecret),
+		verifier:   verifier,
+		denylist:   denylist,
 	}
 }
 
@@ -75,7 +77,7 @@ func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	userID, err := jwtutil.ValidateToken(tokenString, h.jwtSecret)
+	claims, err := h.verifier.ParseToken(r.Context(), tokenString)
 	if err != nil {
 		if errors.Is(err, jwtutil.ErrExpiredToken) {
 			slog.Warn("token expired", "error", err)
@@ -87,6 +89,20 @@ func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Reject tokens of sessions revoked by logout or ban
+	revoked, err := h.denylist.IsRevoked(r.Context(), claims.SessionID)
+	if err != nil {
+		slog.Error("failed to check token denylist", "error", err)
+		http.Error(w, "internal server error", http.StatusInternalServerError)
+		return
+	}
+	if revoked {
+		slog.Warn("token revoked", "user_id", claims.UserID)
+		http.Error(w, "token revoked", http.StatusUnauthorized)
+		return
+	}
+	userID := claims.UserID
+
 	clientChan := make(chan Event, 10)
 	h.addClient(userID, clientChan)
 
@@ -3,6 +3,7 @@ package main
 import (
 	"log"
 	"os"
+	"strings"
 	"time"
 
 	"github.com/gocql/gocql"
@@ -59,11 +60,22 @@ func main() {
 		"user_id text PRIMARY KEY, " +
 		"email text, " +
 		"username text, " +
+		"display_name text, " +
+		"avatar_url text, " +
+		"version bigint, " +
 		"created_at timestamp" +
 		");").Exec()
 	if err != nil {
 		log.Fatalf("Failed to create table: %v", err)
 	}
 
+	// 3. Profile columns for tables created before user_updated was consumed
+	for _, column := range []string{"display_name text", "avatar_url text", "version bigint"} {
+		err = session.Query("ALTER TABLE " + keyspace + ".users ADD " + column + ";").Exec()
+		if err != nil && !strings.Contains(err.Error(), "already exist") {
+			log.Fatalf("Failed to add column %s: %v", column, err)
+		}
+	}
+
 	log.Println("Migration completed successfully.")
 }
@@ -34,6 +34,18 @@ func NewEventRouter(logger *slog.Logger, brokers string, handler *handler.Handle
 	}
 
 	// 3. Handlers
+	router.AddConsumerHandler(
+		"messaging_user_updated_handler",
+		"user_updated",
+		subscriber,
+		handler.HandleUserUpdated,
+	)
+	router.AddConsumerHandler(
+		"messaging_user_deletion_requested_handler",
+		"user_deletion_requested",
+		subscriber,
+		handler.HandleUserDeletionRequested,
+	)
 
 	return &EventRouter{
 		Router:     router,
@@ -1,10 +1,14 @@
 package handler
 
 import (
+	"encoding/json"
 	"log/slog"
 
+	"github.com/ThreeDotsLabs/watermill"
 	"github.com/ThreeDotsLabs/watermill/message"
 	"github.com/username/progetto/messaging-service/internal/repository"
+	"github.com/username/progetto/shared/pkg/model"
+	"github.com/username/progetto/shared/pkg/resiliency"
 )
 
 type Handler struct {
@@ -12,3 +16,54 @@ type Handler struct {
 	Publisher message.Publisher
 	Logger    *slog.Logger
 }
+
+// HandleUserUpdated refreshes the users table, older versions are ignored
+func (h *Handler) HandleUserUpdated(msg *message.Message) error {
+	var payload model.UserUpdatedEvent
+	if err := json.Unmarshal(msg.Payload, &payload); err != nil {
+		h.Logger.ErrorContext(msg.Context(), "failed to unmarshal message", "error", err)
+		return nil // Don't retry malformed messages
+	}
+
+	user, err := payload.User()
+	if err != nil {
+		h.Logger.ErrorContext(msg.Context(), "failed to parse user_id", "error", err)
+		return resiliency.NewPermanentError(err)
+	}
+
+	if err := h.Repo.SaveUserVersion(msg.Context(), user); err != nil {
+		h.Logger.ErrorContext(msg.Context(), "failed to save user", "error", err, "user_id", payload.UserID)
+		return err // Retry
+	}
+	return nil
+}
+
+// HandleUserDeletionRequested removes the user row, then confirms with user_deleted_messaging
+func (h *Handler) HandleUserDeletionRequested(msg *message.Message) error {
+	var payload struct {
+		DeletionID string `json:"deletion_id"`
+		UserID     string `json:"user_id"`
+	}
+	if err := json.Unmarshal(msg.Payload, &payload); err != nil || payload.UserID == "" {
+		h.Logger.ErrorContext(msg.Context(), "failed to unmarshal message", "error", err)
+		return nil // Don't retry malformed messages
+	}
+
+	if err := h.Repo.DeleteUser(msg.Context(), payload.UserID); err != nil {
+		h.Logger.ErrorContext(msg.Context(), "failed to delete user", "error", err, "user_id", payload.UserID)
+		return err // Retry
+	}
+
+	confirmBytes, _ := json.Marshal(payload)
+	confirmMsg := message.NewMessage(watermill.NewUUID(), confirmBytes)
+	confirmMsg.Metadata.Set("user_id", payload.UserID)
+	confirmMsg.SetContext(msg.Context())
+
+	if err := h.Publisher.Publish("user_deleted_messaging", confirmMsg); err != nil {
+		h.Logger.ErrorContext(msg.Context(), "failed to publish user_deleted_messaging event", "error", err)
+		return err // Retry
+	}
+
+	h.Logger.InfoContext(msg.Context(), "user deleted", "user_id", payload.UserID, "deletion_id", payload.DeletionID)
+	return nil
+}
@@ -2,6 +2,7 @@ package repository
 
 import (
 	"context"
+	"errors"
 	"log/slog"
 	"strconv"
 	"time"
@@ -10,8 +11,14 @@ import (
 	"github.com/username/progetto/shared/pkg/model"
 )
 
+// ErrConcurrentUpdate is returned when the row changed between read and write, retrying is safe
+var ErrConcurrentUpdate = errors.New("user changed concurrently")
+
 type UserRepository interface {
 	SaveUser(ctx context.Context, user model.User) error
+	// SaveUserVersion stores the user unless the same or a newer version is already stored
+	SaveUserVersion(ctx context.Context, user model.User) error
+	DeleteUser(ctx context.Context, userID string) error
 	Close()
 }
 
@@ -34,6 +41,48 @@ func (r *cassandraRepository) SaveUser(ctx context.Context, user model.User) err
 	return nil
 }
 
+func (r *cassandraRepository) SaveUserVersion(ctx context.Context, user model.User) error {
+	userID := strconv.Itoa(int(user.ID))
+
+	// Rows written before versioning have a null version
+	var current *int64
+	err := r.session.Query(`SELECT version FROM messaging.users WHERE user_id = ?`, userID).WithContext(ctx).Scan(&current)
+	if errors.Is(err, gocql.ErrNotFound) {
+		applied, err := r.session.Query(`INSERT INTO messaging.users (user_id, email, username, display_name, avatar_url, version, created_at) VALUES (?, ?, ?, ?, ?, ?, ?) IF NOT EXISTS`,
+			userID, user.Email, user.Username, user.DisplayName, user.AvatarURL, user.Version, time.Now()).WithContext(ctx).MapScanCAS(map[string]interface{}{})
+		if err != nil {
+			return err
+		}
+		if !applied {
+			return ErrConcurrentUpdate
+		}
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	if current != nil && *current >= user.Version {
+		slog.InfoContext(ctx, "ignoring stale user version", "user_id", userID, "version", user.Version, "current", *current)
+		return nil
+	}
+
+	// Compare-and-set on the version read above, so a concurrent newer write is never overwritten
+	applied, err := r.session.Query(`UPDATE messaging.users SET email = ?, username = ?, display_name = ?, avatar_url = ?, version = ? WHERE user_id = ? IF version = ?`,
+		user.Email, user.Username, user.DisplayName, user.AvatarURL, user.Version, userID, current).WithContext(ctx).MapScanCAS(map[string]interface{}{})
+	if err != nil {
+		return err
+	}
+	if !applied {
+		return ErrConcurrentUpdate
+	}
+	return nil
+}
+
+func (r *cassandraRepository) DeleteUser(ctx context.Context, userID string) error {
+	return r.session.Query(`DELETE FROM messaging.users WHERE user_id = ?`, userID).WithContext(ctx).Exec()
+}
+
 func (r *cassandraRepository) Close() {
 	if r.session != nil {
 		r.session.Close()
@@ -37,6 +37,31 @@ func NewEventRouter(logger *slog.Logger, brokers string, h *handler.Notification
 		subscriber,
 		h.HandleNotification,
 	)
+	router.AddConsumerHandler(
+		"notifications_session_compromised",
+		"session_compromised",
+		subscriber,
+		h.HandleNotification,
+	)
+	router.AddConsumerHandler(
+		"notifications_account_locked",
+		"account_locked",
+		subscriber,
+		h.HandleNotification,
+	)
+	router.AddConsumerHandler(
+		"notifications_new_device_login",
+		"new_device_login",
+		subscriber,
+		h.HandleNotification,
+	)
+
+	router.AddConsumerHandler(
+		"notifications_comment_created",
+		"comment.created",
+		subscriber,
+		h.HandleCommentCreated,
+	)
 
 	// Aggregator handlers
 	router.AddConsumerHandler(
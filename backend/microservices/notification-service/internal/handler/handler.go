@@ -42,31 +42,62 @@ func (h *NotificationHandler) HandleNotification(msg *message.Message) error {
 		return nil
 	}
 
-	instanceID, err := h.Presence.GetUserGateway(msg.Context(), payload.UserID)
+	return h.notify(msg.Context(), payload.UserID, message.SubscribeTopicFromCtx(msg.Context()), msg.Payload)
+}
+
+// HandleCommentCreated notifies the post author and the author of the replied comment,
+// unless they wrote the comment themselves
+func (h *NotificationHandler) HandleCommentCreated(msg *message.Message) error {
+	var payload struct {
+		AuthorID       string `json:"author_id"`
+		PostAuthorID   string `json:"post_author_id"`
+		ParentAuthorID string `json:"parent_author_id"`
+	}
+	if err := json.Unmarshal(msg.Payload, &payload); err != nil {
+		h.Logger.Error("failed to unmarshal payload", "error", err)
+		return nil // skip
+	}
+
+	topic := message.SubscribeTopicFromCtx(msg.Context())
+	notified := map[string]bool{payload.AuthorID: true, "": true}
+	for _, userID := range []string{payload.PostAuthorID, payload.ParentAuthorID} {
+		if notified[userID] {
+			continue
+		}
+		notified[userID] = true
+		if err := h.notify(msg.Context(), userID, topic, msg.Payload); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// notify routes the event to the gateway instance the user is connected to, if any
+func (h *NotificationHandler) notify(ctx context.Context, userID, topic string, payload []byte) error {
+	instanceID, err := h.Presence.GetUserGateway(ctx, userID)
 	if err != nil {
 		return err
 	}
 
 	if instanceID == "" {
-		h.Logger.Debug("user not connected, skipping", "user_id", payload.UserID)
+		h.Logger.Debug("user not connected, skipping", "user_id", userID)
 		return nil
 	}
 
-	topic := message.SubscribeTopicFromCtx(msg.Context())
 	channel := fmt.Sprintf("gateway_events:%s", instanceID)
 
 	sseEvent := presence.TargetedEvent{
-		UserID:  payload.UserID,
+		UserID:  userID,
 		Type:    topic,
-		Payload: string(msg.Payload),
+		Payload: string(payload),
 	}
 
 	b, _ := json.Marshal(sseEvent)
-	if err := h.Redis.Publish(msg.Context(), channel, b).Err(); err != nil {
+	if err := h.Redis.Publish(ctx, channel, b).Err(); err != nil {
 		return err
 	}
 
-	h.Logger.Info("routed notification to gateway", "user_id", payload.UserID, "instance_id", instanceID)
+	h.Logger.Info("routed notification to gateway", "user_id", userID, "instance_id", instanceID)
 	return nil
 }
 
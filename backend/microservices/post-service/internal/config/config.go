@@ -1,21 +1,48 @@
 package config
 
 import (
+	"strconv"
+
 	"github.com/username/progetto/shared/pkg/config"
 )
 
 type Config struct {
 	MongoURI             string
+	RedisAddr            string
 	KafkaBrokers         string
+	TrustedServices      string
+	CassandraHost        string
+	CassandraConsistency string
+	CassandraKeyspace    string
+	SocialService        string
+	ServiceName          string
+	ServiceKey           string
+	CelebrityFollowers   int64
 	OtelServiceName      string
 	OtelExporterEndpoint string
 }
 
+// Load reads the config from the environment. APP_SERVICE_KEY is the identity
+// presented to social-service, see grpcutil.NewServiceIdentity.
 func Load() *Config {
-	return &Config{
+	cfg := &Config{
 		MongoURI:             config.MustGetEnv("APP_MONGO_URI"),
+		RedisAddr:            config.MustGetEnv("APP_REDIS_ADDR"),
 		KafkaBrokers:         config.MustGetEnv("APP_KAFKA_BROKERS"),
+		TrustedServices:      config.MustGetEnv("APP_TRUSTED_SERVICES"),
+		CassandraHost:        config.MustGetEnv("APP_CASSANDRA_HOST"),
+		CassandraConsistency: config.GetEnv("APP_CASSANDRA_CONSISTENCY", "LOCAL_QUORUM"),
+		CassandraKeyspace:    config.MustGetEnv("APP_CASSANDRA_KEYSPACE"),
+		SocialService:        config.GetEnv("SOCIAL_SERVICE", "social-service:50051"),
+		ServiceName:          config.GetEnv("APP_SERVICE_NAME", "post-service"),
+		ServiceKey:           config.MustGetEnv("APP_SERVICE_KEY"),
+		CelebrityFollowers:   10000,
 		OtelServiceName:      config.GetEnv("OTEL_SERVICE_NAME", "post-service"),
 		OtelExporterEndpoint: config.GetEnv("OTEL_EXPORTER_OTLP_ENDPOINT", "alloy:4317"),
 	}
+	// Authors with at least this many followers aren't fanned out, their posts are merged on read
+	if n, err := strconv.ParseInt(config.GetEnv("APP_CELEBRITY_FOLLOWERS", ""), 10, 64); err == nil && n > 0 {
+		cfg.CelebrityFollowers = n
+	}
+	return cfg
 }
@@ -15,7 +15,7 @@ type EventRouter struct {
 	Publisher  message.Publisher
 }
 
-func NewEventRouter(logger *slog.Logger, brokers string, publisher message.Publisher, userHandler *handler.UserHandler) (*EventRouter, error) {
+func NewEventRouter(logger *slog.Logger, brokers string, publisher message.Publisher, userHandler *handler.UserHandler, timelineHandler *handler.TimelineHandler) (*EventRouter, error) {
 	// 1. Subscriber
 	subscriber, err := watermillutil.NewKafkaSubscriber(brokers, "post_service_user_sync", logger)
 	if err != nil {
@@ -44,6 +44,28 @@ func NewEventRouter(logger *slog.Logger, brokers string, publisher message.Publi
 		userHandler.HandleCreated,
 	)
 
+	router.AddConsumerHandler(
+		"post_user_updated_handler",
+		"user_updated",
+		subscriber,
+		userHandler.HandleUpdated,
+	)
+
+	router.AddConsumerHandler(
+		"post_user_deletion_requested_handler",
+		"user_deletion_requested",
+		subscriber,
+		userHandler.HandleDeletionRequested,
+	)
+
+	// Fan-out-on-write of the home timelines
+	router.AddConsumerHandler(
+		"post_timeline_fanout_handler",
+		"post.created",
+		subscriber,
+		timelineHandler.HandlePostCreated,
+	)
+
 	return &EventRouter{
 		Router:     router,
 		Subscriber: subscriber,
@@ -0,0 +1,282 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/username/progetto/post-service/internal/model"
+	"github.com/username/progetto/post-service/internal/repository"
+	postv1 "github.com/username/progetto/proto/gen/go/post/v1"
+	sharedmodel "github.com/username/progetto/shared/pkg/model"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+const (
+	maxCommentLength     = 2000
+	defaultCommentsLimit = 20
+	maxCommentsLimit     = 100
+	// Replies are deleted in batches of this size when their thread is deleted
+	replyDeleteBatch = 100
+)
+
+func (h *PostHandler) CreateComment(ctx context.Context, req *postv1.CreateCommentRequest) (*postv1.CreateCommentResponse, error) {
+	authorID, err := actingUser(ctx, req.AuthorId)
+	if err != nil {
+		return nil, err
+	}
+	content, err := validateCommentContent(req.Content)
+	if err != nil {
+		return nil, err
+	}
+	post, err := h.getPost(ctx, req.PostId)
+	if err != nil {
+		return nil, err
+	}
+
+	comment := &model.Comment{
+		PostID:       post.ID.Hex(),
+		PostAuthorID: post.AuthorID,
+		AuthorID:     authorID,
+		Content:      content,
+		CreatedAt:    time.Now(),
+	}
+	parentAuthorID := ""
+	if req.ParentId != "" {
+		parent, err := h.comments.GetByID(ctx, req.ParentId)
+		if errors.Is(err, repository.ErrNotFound) || (err == nil && parent.PostID != comment.PostID) {
+			return nil, invalidField("parent_id", "comment not found in this post")
+		}
+		if err != nil {
+			h.logger.ErrorContext(ctx, "failed to get parent comment", "error", err, "comment_id", req.ParentId)
+			return nil, status.Errorf(codes.Internal, "failed to get comment: %v", err)
+		}
+		// Threads have one level, a reply to a reply joins the same thread
+		comment.ParentID = parent.ID.Hex()
+		if parent.ParentID != "" {
+			comment.ParentID = parent.ParentID
+		}
+		parentAuthorID = parent.AuthorID
+	}
+
+	if err := h.comments.Create(ctx, comment); err != nil {
+		h.logger.ErrorContext(ctx, "failed to create comment", "error", err, "post_id", req.PostId)
+		return nil, status.Errorf(codes.Internal, "failed to create comment: %v", err)
+	}
+	if _, err := h.repo.IncrementComments(ctx, comment.PostID, 1); err != nil {
+		h.logger.ErrorContext(ctx, "failed to update comments count", "error", err, "post_id", comment.PostID)
+	}
+	if comment.ParentID != "" {
+		if err := h.comments.IncrementReplies(ctx, comment.ParentID, 1); err != nil {
+			h.logger.ErrorContext(ctx, "failed to update replies count", "error", err, "comment_id", comment.ParentID)
+		}
+	}
+
+	h.publishCommentCreated(ctx, comment, parentAuthorID)
+	return &postv1.CreateCommentResponse{Comment: commentToProto(comment)}, nil
+}
+
+func (h *PostHandler) ListComments(ctx context.Context, req *postv1.ListCommentsRequest) (*postv1.ListCommentsResponse, error) {
+	post, err := h.getPost(ctx, req.PostId)
+	if err != nil {
+		return nil, err
+	}
+
+	limit := int64(req.Limit)
+	if limit <= 0 {
+		limit = defaultCommentsLimit
+	}
+	limit = min(limit, maxCommentsLimit)
+
+	comments, nextToken, err := h.comments.List(ctx, post.ID.Hex(), req.ParentId, limit, req.NextPageToken)
+	if errors.Is(err, repository.ErrInvalidCursor) {
+		return nil, status.Error(codes.InvalidArgument, "invalid page token")
+	}
+	if err != nil {
+		h.logger.ErrorContext(ctx, "failed to list comments", "error", err, "post_id", req.PostId)
+		return nil, status.Errorf(codes.Internal, "failed to list comments: %v", err)
+	}
+
+	protoComments := make([]*postv1.Comment, 0, len(comments))
+	for _, c := range comments {
+		protoComments = append(protoComments, commentToProto(c))
+	}
+	return &postv1.ListCommentsResponse{Comments: protoComments, NextPageToken: nextToken}, nil
+}
+
+// UpdateComment replaces the content of a comment of the acting user
+func (h *PostHandler) UpdateComment(ctx context.Context, req *postv1.UpdateCommentRequest) (*postv1.UpdateCommentResponse, error) {
+	userID, err := actingUser(ctx, req.UserId)
+	if err != nil {
+		return nil, err
+	}
+	content, err := validateCommentContent(req.Content)
+	if err != nil {
+		return nil, err
+	}
+	comment, err := h.getComment(ctx, req.CommentId)
+	if err != nil {
+		return nil, err
+	}
+	if comment.AuthorID != userID {
+		return nil, status.Error(codes.PermissionDenied, "only the author can edit a comment")
+	}
+
+	comment, err = h.comments.UpdateContent(ctx, req.CommentId, content, time.Now())
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil, status.Error(codes.NotFound, "comment not found")
+	}
+	if err != nil {
+		h.logger.ErrorContext(ctx, "failed to update comment", "error", err, "comment_id", req.CommentId)
+		return nil, status.Errorf(codes.Internal, "failed to update comment: %v", err)
+	}
+	return &postv1.UpdateCommentResponse{Comment: commentToProto(comment)}, nil
+}
+
+// DeleteComment deletes a comment of the acting user, or a comment of one of their posts
+func (h *PostHandler) DeleteComment(ctx context.Context, req *postv1.DeleteCommentRequest) (*postv1.DeleteCommentResponse, error) {
+	userID, err := actingUser(ctx, req.UserId)
+	if err != nil {
+		return nil, err
+	}
+	comment, err := h.getComment(ctx, req.CommentId)
+	if err != nil {
+		return nil, err
+	}
+	if comment.AuthorID != userID && comment.PostAuthorID != userID {
+		return nil, status.Error(codes.PermissionDenied, "only the author or the post author can delete a comment")
+	}
+
+	deleted, err := deleteComment(ctx, h.comments, h.repo, comment)
+	if err != nil {
+		h.logger.ErrorContext(ctx, "failed to delete comment", "error", err, "comment_id", req.CommentId)
+		return nil, status.Errorf(codes.Internal, "failed to delete comment: %v", err)
+	}
+	return &postv1.DeleteCommentResponse{DeletedCount: int32(deleted)}, nil
+}
+
+// deleteComment deletes the comment with its replies and updates the counts, returning how many comments were deleted.
+// A comment is restored when the decrement following its deletion fails, so a retry finds it again
+// and the count is never left without its decrement.
+func deleteComment(ctx context.Context, comments repository.CommentRepository, posts repository.PostRepository, comment *model.Comment) (int64, error) {
+	var deleted int64
+	if comment.ParentID == "" {
+		for {
+			replies, _, err := comments.List(ctx, comment.PostID, comment.ID.Hex(), replyDeleteBatch, "")
+			if err != nil {
+				return deleted, err
+			}
+			if len(replies) == 0 {
+				break
+			}
+			for _, reply := range replies {
+				removed, err := removeComment(ctx, comments, posts, reply)
+				if err != nil {
+					return deleted, err
+				}
+				if removed {
+					deleted++
+				}
+			}
+		}
+	}
+
+	// The replies are gone, a restored comment has none
+	restored := *comment
+	restored.Replies = 0
+	removed, err := removeComment(ctx, comments, posts, &restored)
+	if err != nil || !removed {
+		return deleted, err
+	}
+	deleted++
+	if comment.ParentID != "" {
+		if err := comments.IncrementReplies(ctx, comment.ParentID, -1); err != nil && !errors.Is(err, repository.ErrNotFound) {
+			return deleted, err
+		}
+	}
+	return deleted, nil
+}
+
+// removeComment deletes a single comment and decrements its post, restoring the comment when the decrement fails.
+// It returns false when the comment was already deleted.
+func removeComment(ctx context.Context, comments repository.CommentRepository, posts repository.PostRepository, comment *model.Comment) (bool, error) {
+	removed, err := comments.Delete(ctx, comment.ID.Hex())
+	if err != nil || !removed {
+		return false, err
+	}
+	if _, err := posts.IncrementComments(ctx, comment.PostID, -1); err != nil && !errors.Is(err, repository.ErrNotFound) {
+		// The restored comment keeps its ID, so it stays in place in its thread
+		if restoreErr := comments.Create(context.WithoutCancel(ctx), comment); restoreErr != nil {
+			return false, errors.Join(err, fmt.Errorf("failed to restore comment: %w", restoreErr))
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+func (h *PostHandler) getComment(ctx context.Context, commentID string) (*model.Comment, error) {
+	comment, err := h.comments.GetByID(ctx, commentID)
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil, status.Error(codes.NotFound, "comment not found")
+	}
+	if err != nil {
+		h.logger.ErrorContext(ctx, "failed to get comment", "error", err, "comment_id", commentID)
+		return nil, status.Errorf(codes.Internal, "failed to get comment: %v", err)
+	}
+	return comment, nil
+}
+
+// publishCommentCreated lets the post author and the replied user be notified
+func (h *PostHandler) publishCommentCreated(ctx context.Context, comment *model.Comment, parentAuthorID string) {
+	h.publish(ctx, "comment.created", comment.PostID, sharedmodel.CommentCreatedEvent{
+		CommentID:      comment.ID.Hex(),
+		PostID:         comment.PostID,
+		PostAuthorID:   comment.PostAuthorID,
+		ParentID:       comment.ParentID,
+		ParentAuthorID: parentAuthorID,
+		AuthorID:       comment.AuthorID,
+		Content:        comment.Content,
+		CreatedAt:      comment.CreatedAt,
+	})
+}
+
+func validateCommentContent(content string) (string, error) {
+	content = strings.TrimSpace(content)
+	if content == "" {
+		return "", invalidField("content", "is required")
+	}
+	if utf8.RuneCountInString(content) > maxCommentLength {
+		return "", invalidField("content", "must be at most 2000 characters")
+	}
+	return content, nil
+}
+
+// invalidField is an InvalidArgument status with the field violation as BadRequest
+func invalidField(field, description string) error {
+	st, _ := status.New(codes.InvalidArgument, field+" "+description).WithDetails(&errdetails.BadRequest{
+		FieldViolations: []*errdetails.BadRequest_FieldViolation{{Field: field, Description: description}},
+	})
+	return st.Err()
+}
+
+func commentToProto(c *model.Comment) *postv1.Comment {
+	comment := &postv1.Comment{
+		Id:           c.ID.Hex(),
+		PostId:       c.PostID,
+		AuthorId:     c.AuthorID,
+		ParentId:     c.ParentID,
+		Content:      c.Content,
+		RepliesCount: c.Replies,
+		CreatedAt:    timestamppb.New(c.CreatedAt),
+	}
+	if c.EditedAt != nil {
+		comment.EditedAt = timestamppb.New(*c.EditedAt)
+	}
+	return comment
+}
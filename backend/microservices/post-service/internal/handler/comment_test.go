@@ -0,0 +1,163 @@
+package handler
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/username/progetto/post-service/internal/model"
+	postv1 "github.com/username/progetto/proto/gen/go/post/v1"
+	"google.golang.org/grpc/codes"
+)
+
+// commentFixture is a post with a top level comment of "alice" and two replies of "bob"
+type commentFixture struct {
+	posts    *fakePostRepository
+	comments *fakeCommentRepository
+	post     *model.Post
+	thread   *model.Comment
+	replies  []*model.Comment
+}
+
+func newCommentFixture(t *testing.T) *commentFixture {
+	t.Helper()
+	f := &commentFixture{
+		post:     &model.Post{AuthorID: "author", Content: "hello", Comments: 3},
+		comments: newFakeCommentRepository(),
+	}
+	f.posts = newFakePostRepository(f.post)
+
+	f.thread = &model.Comment{PostID: f.post.ID.Hex(), PostAuthorID: "author", AuthorID: "alice", Content: "first", Replies: 2}
+	if err := f.comments.Create(t.Context(), f.thread); err != nil {
+		t.Fatal(err)
+	}
+	for range 2 {
+		reply := &model.Comment{PostID: f.post.ID.Hex(), PostAuthorID: "author", ParentID: f.thread.ID.Hex(), AuthorID: "bob", Content: "reply"}
+		if err := f.comments.Create(t.Context(), reply); err != nil {
+			t.Fatal(err)
+		}
+		f.replies = append(f.replies, reply)
+	}
+	return f
+}
+
+func TestCreateComment(t *testing.T) {
+	f := newCommentFixture(t)
+	otherComment := &model.Comment{PostID: "elsewhere", AuthorID: "bob", Content: "elsewhere"}
+	f.comments.Create(t.Context(), otherComment)
+
+	tests := []struct {
+		name       string
+		parentID   string
+		content    string
+		wantCode   codes.Code
+		wantParent string
+	}{
+		{name: "top level comment", content: "nice"},
+		{name: "reply", parentID: f.thread.ID.Hex(), content: "agreed", wantParent: f.thread.ID.Hex()},
+		{name: "reply to a reply joins the thread", parentID: f.replies[0].ID.Hex(), content: "me too", wantParent: f.thread.ID.Hex()},
+		{name: "parent of another post", parentID: otherComment.ID.Hex(), content: "lost", wantCode: codes.InvalidArgument},
+		{name: "blank content", content: "   ", wantCode: codes.InvalidArgument},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h, publisher := newTestHandler(f.posts, newFakeLikeRepository(), f.comments)
+			before := f.posts.posts[f.post.ID.Hex()].Comments
+
+			resp, err := h.CreateComment(userContext("carol"), &postv1.CreateCommentRequest{
+				PostId:   f.post.ID.Hex(),
+				ParentId: tt.parentID,
+				Content:  tt.content,
+			})
+			if tt.wantCode != codes.OK {
+				assertCode(t, err, tt.wantCode)
+				if got := f.posts.posts[f.post.ID.Hex()].Comments; got != before {
+					t.Errorf("comments count = %d, want %d", got, before)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("CreateComment() error = %v", err)
+			}
+
+			if resp.Comment.ParentId != tt.wantParent {
+				t.Errorf("ParentId = %q, want %q", resp.Comment.ParentId, tt.wantParent)
+			}
+			if got := f.posts.posts[f.post.ID.Hex()].Comments; got != before+1 {
+				t.Errorf("comments count = %d, want %d", got, before+1)
+			}
+			if got := publisher.published(); !slices.Equal(got, []string{"comment.created"}) {
+				t.Errorf("published = %v, want [comment.created]", got)
+			}
+		})
+	}
+}
+
+func TestDeleteComment(t *testing.T) {
+	tests := []struct {
+		name        string
+		user        string
+		target      func(f *commentFixture) *model.Comment
+		wantCode    codes.Code
+		wantDeleted int32
+		wantLeft    int
+		wantReplies int32
+	}{
+		{name: "reply by its author", user: "bob", target: func(f *commentFixture) *model.Comment { return f.replies[0] }, wantDeleted: 1, wantLeft: 2, wantReplies: 1},
+		{name: "thread with its replies", user: "alice", target: func(f *commentFixture) *model.Comment { return f.thread }, wantDeleted: 3, wantLeft: 0},
+		{name: "moderated by the post author", user: "author", target: func(f *commentFixture) *model.Comment { return f.thread }, wantDeleted: 3, wantLeft: 0},
+		{name: "other users can't delete", user: "mallory", target: func(f *commentFixture) *model.Comment { return f.thread }, wantCode: codes.PermissionDenied, wantLeft: 3, wantReplies: 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newCommentFixture(t)
+			h, _ := newTestHandler(f.posts, newFakeLikeRepository(), f.comments)
+
+			resp, err := h.DeleteComment(userContext(tt.user), &postv1.DeleteCommentRequest{CommentId: tt.target(f).ID.Hex()})
+			if tt.wantCode != codes.OK {
+				assertCode(t, err, tt.wantCode)
+			} else if err != nil {
+				t.Fatalf("DeleteComment() error = %v", err)
+			} else if resp.DeletedCount != tt.wantDeleted {
+				t.Errorf("DeletedCount = %d, want %d", resp.DeletedCount, tt.wantDeleted)
+			}
+
+			if got := len(f.comments.comments); got != tt.wantLeft {
+				t.Errorf("comments left = %d, want %d", got, tt.wantLeft)
+			}
+			if got := f.posts.posts[f.post.ID.Hex()].Comments; got != int32(tt.wantLeft) {
+				t.Errorf("comments count = %d, want %d", got, tt.wantLeft)
+			}
+			if thread, ok := f.comments.comments[f.thread.ID.Hex()]; ok && thread.Replies != tt.wantReplies {
+				t.Errorf("replies count = %d, want %d", thread.Replies, tt.wantReplies)
+			}
+		})
+	}
+}
+
+// A failed decrement restores the deleted comment, so the retry decrements every comment once
+func TestDeleteCommentRetryAfterFailedDecrement(t *testing.T) {
+	f := newCommentFixture(t)
+	h, _ := newTestHandler(f.posts, newFakeLikeRepository(), f.comments)
+	req := &postv1.DeleteCommentRequest{CommentId: f.thread.ID.Hex()}
+
+	f.posts.failIncrement = errStorage
+	_, err := h.DeleteComment(userContext("alice"), req)
+	assertCode(t, err, codes.Internal)
+	if got := len(f.comments.comments); got != 3 {
+		t.Fatalf("comments left after the failure = %d, want 3", got)
+	}
+
+	f.posts.failIncrement = nil
+	resp, err := h.DeleteComment(userContext("alice"), req)
+	if err != nil {
+		t.Fatalf("DeleteComment() retry error = %v", err)
+	}
+	if resp.DeletedCount != 3 {
+		t.Errorf("DeletedCount = %d, want 3", resp.DeletedCount)
+	}
+	if got := f.posts.posts[f.post.ID.Hex()].Comments; got != 0 {
+		t.Errorf("comments count = %d, want 0", got)
+	}
+}
@@ -0,0 +1,358 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ThreeDotsLabs/watermill/message"
+	"github.com/username/progetto/post-service/internal/model"
+	"github.com/username/progetto/post-service/internal/repository"
+	"github.com/username/progetto/shared/pkg/grpcutil"
+	sharedmodel "github.com/username/progetto/shared/pkg/model"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+var errStorage = errors.New("storage unavailable")
+
+// userContext is the context of a call made by gateway-service for the user
+func userContext(userID string) context.Context {
+	return grpcutil.WithCaller(context.Background(), grpcutil.Caller{Service: "gateway-service", UserID: userID})
+}
+
+func newTestHandler(posts *fakePostRepository, likes *fakeLikeRepository, comments *fakeCommentRepository) (*PostHandler, *fakePublisher) {
+	publisher := &fakePublisher{}
+	return NewPostHandler(posts, likes, comments, nil, publisher), publisher
+}
+
+// fakePostRepository keeps the posts in memory, failIncrement makes the counter updates fail
+type fakePostRepository struct {
+	posts         map[string]*model.Post
+	failIncrement error
+}
+
+func newFakePostRepository(posts ...*model.Post) *fakePostRepository {
+	r := &fakePostRepository{posts: make(map[string]*model.Post)}
+	for _, post := range posts {
+		if post.ID.IsZero() {
+			post.ID = primitive.NewObjectID()
+		}
+		r.posts[post.ID.Hex()] = post
+	}
+	return r
+}
+
+func (r *fakePostRepository) get(id string) (*model.Post, bool) {
+	post, ok := r.posts[id]
+	if !ok || post.DeletedAt != nil {
+		return nil, false
+	}
+	return post, true
+}
+
+func (r *fakePostRepository) Create(ctx context.Context, post *model.Post) error {
+	post.ID = primitive.NewObjectID()
+	stored := *post
+	r.posts[post.ID.Hex()] = &stored
+	return nil
+}
+
+func (r *fakePostRepository) GetByID(ctx context.Context, id string) (*model.Post, error) {
+	post, ok := r.get(id)
+	if !ok {
+		return nil, repository.ErrNotFound
+	}
+	found := *post
+	return &found, nil
+}
+
+func (r *fakePostRepository) GetByIDs(ctx context.Context, ids []string) ([]*model.Post, error) {
+	var posts []*model.Post
+	for _, id := range ids {
+		if post, ok := r.get(id); ok {
+			found := *post
+			posts = append(posts, &found)
+		}
+	}
+	return posts, nil
+}
+
+func (r *fakePostRepository) Update(ctx context.Context, id, authorID, content string, mediaURLs []string, editedAt time.Time) (*model.Post, error) {
+	post, ok := r.get(id)
+	if !ok || post.AuthorID != authorID {
+		return nil, repository.ErrNotFound
+	}
+	writtenAt := post.CreatedAt
+	if post.EditedAt != nil {
+		writtenAt = *post.EditedAt
+	}
+	post.Revisions = append(post.Revisions, sharedmodel.PostRevision{Content: post.Content, MediaURLs: post.MediaURLs, WrittenAt: writtenAt})
+	post.Content, post.MediaURLs, post.EditedAt = content, mediaURLs, &editedAt
+	updated := *post
+	return &updated, nil
+}
+
+func (r *fakePostRepository) SoftDelete(ctx context.Context, id, authorID string, deletedAt time.Time) error {
+	post, ok := r.get(id)
+	if !ok || post.AuthorID != authorID {
+		return repository.ErrNotFound
+	}
+	post.Content, post.MediaURLs, post.Revisions, post.DeletedAt = "", nil, nil, &deletedAt
+	return nil
+}
+
+func (r *fakePostRepository) increment(id string, delta int32, counter func(*model.Post) *int32) (*model.Post, error) {
+	if r.failIncrement != nil {
+		return nil, r.failIncrement
+	}
+	post, ok := r.posts[id]
+	if !ok {
+		return nil, repository.ErrNotFound
+	}
+	*counter(post) = max(*counter(post)+delta, 0)
+	updated := *post
+	return &updated, nil
+}
+
+func (r *fakePostRepository) IncrementLikes(ctx context.Context, id string, delta int32) (*model.Post, error) {
+	return r.increment(id, delta, func(p *model.Post) *int32 { return &p.Likes })
+}
+
+func (r *fakePostRepository) IncrementComments(ctx context.Context, id string, delta int32) (*model.Post, error) {
+	return r.increment(id, delta, func(p *model.Post) *int32 { return &p.Comments })
+}
+
+func (r *fakePostRepository) List(ctx context.Context, authorID string, limit int64, cursor string) ([]*model.Post, string, error) {
+	return nil, "", errors.New("not implemented")
+}
+
+// ListByAuthors returns the newest posts of the authors older than before, like the Mongo query
+func (r *fakePostRepository) ListByAuthors(ctx context.Context, authorIDs []string, before string, limit int64) ([]*model.Post, error) {
+	if _, err := primitive.ObjectIDFromHex(before); before != "" && err != nil {
+		return nil, repository.ErrInvalidCursor
+	}
+	var posts []*model.Post
+	for _, post := range r.posts {
+		if post.DeletedAt != nil || !slices.Contains(authorIDs, post.AuthorID) {
+			continue
+		}
+		if before != "" && post.ID.Hex() >= before {
+			continue
+		}
+		found := *post
+		posts = append(posts, &found)
+	}
+	slices.SortFunc(posts, func(a, b *model.Post) int { return strings.Compare(b.ID.Hex(), a.ID.Hex()) })
+	if int64(len(posts)) > limit {
+		posts = posts[:limit]
+	}
+	return posts, nil
+}
+
+func (r *fakePostRepository) DeleteByAuthor(ctx context.Context, authorID string) (int64, error) {
+	var deleted int64
+	for id, post := range r.posts {
+		if post.AuthorID == authorID {
+			delete(r.posts, id)
+			deleted++
+		}
+	}
+	return deleted, nil
+}
+
+// fakeLikeRepository keeps the likes in memory, keyed by post and user like the unique index
+type fakeLikeRepository struct {
+	likes map[string]*model.Like
+}
+
+func newFakeLikeRepository() *fakeLikeRepository {
+	return &fakeLikeRepository{likes: make(map[string]*model.Like)}
+}
+
+func likeKey(postID, userID string) string {
+	return postID + "/" + userID
+}
+
+func (r *fakeLikeRepository) Add(ctx context.Context, like *model.Like) (bool, error) {
+	key := likeKey(like.PostID, like.UserID)
+	if _, ok := r.likes[key]; ok {
+		return false, nil
+	}
+	if like.ID.IsZero() {
+		like.ID = primitive.NewObjectID()
+	}
+	stored := *like
+	r.likes[key] = &stored
+	return true, nil
+}
+
+func (r *fakeLikeRepository) Remove(ctx context.Context, postID, userID string) (*model.Like, error) {
+	key := likeKey(postID, userID)
+	like, ok := r.likes[key]
+	if !ok {
+		return nil, nil
+	}
+	delete(r.likes, key)
+	return like, nil
+}
+
+func (r *fakeLikeRepository) ListByPost(ctx context.Context, postID string, limit int64, cursor string) ([]*model.Like, string, error) {
+	return nil, "", errors.New("not implemented")
+}
+
+func (r *fakeLikeRepository) PostsLikedBy(ctx context.Context, userID string) ([]string, error) {
+	var postIDs []string
+	for _, like := range r.likes {
+		if like.UserID == userID {
+			postIDs = append(postIDs, like.PostID)
+		}
+	}
+	return postIDs, nil
+}
+
+func (r *fakeLikeRepository) DeleteByPostAuthor(ctx context.Context, authorID string) (int64, error) {
+	var deleted int64
+	for key, like := range r.likes {
+		if like.AuthorID == authorID {
+			delete(r.likes, key)
+			deleted++
+		}
+	}
+	return deleted, nil
+}
+
+// fakeCommentRepository keeps the comments in memory, failList makes List fail
+type fakeCommentRepository struct {
+	comments map[string]*model.Comment
+	failList error
+}
+
+func newFakeCommentRepository() *fakeCommentRepository {
+	return &fakeCommentRepository{comments: make(map[string]*model.Comment)}
+}
+
+func (r *fakeCommentRepository) Create(ctx context.Context, comment *model.Comment) error {
+	if comment.ID.IsZero() {
+		comment.ID = primitive.NewObjectID()
+	}
+	stored := *comment
+	r.comments[comment.ID.Hex()] = &stored
+	return nil
+}
+
+func (r *fakeCommentRepository) GetByID(ctx context.Context, id string) (*model.Comment, error) {
+	comment, ok := r.comments[id]
+	if !ok {
+		return nil, repository.ErrNotFound
+	}
+	found := *comment
+	return &found, nil
+}
+
+// List pages through the thread oldest first, like the Mongo query
+func (r *fakeCommentRepository) List(ctx context.Context, postID, parentID string, limit int64, cursor string) ([]*model.Comment, string, error) {
+	if r.failList != nil {
+		return nil, "", r.failList
+	}
+	var comments []*model.Comment
+	for _, comment := range r.comments {
+		if comment.PostID == postID && comment.ParentID == parentID && comment.ID.Hex() > cursor {
+			found := *comment
+			comments = append(comments, &found)
+		}
+	}
+	slices.SortFunc(comments, func(a, b *model.Comment) int { return strings.Compare(a.ID.Hex(), b.ID.Hex()) })
+	if int64(len(comments)) < limit {
+		return comments, "", nil
+	}
+	comments = comments[:limit]
+	return comments, comments[limit-1].ID.Hex(), nil
+}
+
+func (r *fakeCommentRepository) UpdateContent(ctx context.Context, id, content string, editedAt time.Time) (*model.Comment, error) {
+	comment, ok := r.comments[id]
+	if !ok {
+		return nil, repository.ErrNotFound
+	}
+	comment.Content, comment.EditedAt = content, &editedAt
+	updated := *comment
+	return &updated, nil
+}
+
+func (r *fakeCommentRepository) Delete(ctx context.Context, id string) (bool, error) {
+	if _, ok := r.comments[id]; !ok {
+		return false, nil
+	}
+	delete(r.comments, id)
+	return true, nil
+}
+
+func (r *fakeCommentRepository) IncrementReplies(ctx context.Context, id string, delta int32) error {
+	comment, ok := r.comments[id]
+	if !ok {
+		return repository.ErrNotFound
+	}
+	comment.Replies = max(comment.Replies+delta, 0)
+	return nil
+}
+
+func (r *fakeCommentRepository) ListByAuthor(ctx context.Context, authorID string) ([]*model.Comment, error) {
+	var comments []*model.Comment
+	for _, comment := range r.comments {
+		if comment.AuthorID == authorID {
+			found := *comment
+			comments = append(comments, &found)
+		}
+	}
+	return comments, nil
+}
+
+func (r *fakeCommentRepository) DeleteByPostAuthor(ctx context.Context, postAuthorID string) (int64, error) {
+	var deleted int64
+	for id, comment := range r.comments {
+		if comment.PostAuthorID == postAuthorID {
+			delete(r.comments, id)
+			deleted++
+		}
+	}
+	return deleted, nil
+}
+
+// fakePublisher records the topics of the published messages
+type fakePublisher struct {
+	mu     sync.Mutex
+	topics []string
+}
+
+func (p *fakePublisher) Publish(topic string, messages ...*message.Message) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for range messages {
+		p.topics = append(p.topics, topic)
+	}
+	return nil
+}
+
+func (p *fakePublisher) Close() error {
+	return nil
+}
+
+func (p *fakePublisher) published() []string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return slices.Clone(p.topics)
+}
+
+// assertCode fails the test when err doesn't carry the wanted gRPC code
+func assertCode(t *testing.T, err error, want codes.Code) {
+	t.Helper()
+	if got := status.Code(err); got != want {
+		t.Fatalf("error code = %v, want %v (error: %v)", got, want, err)
+	}
+}
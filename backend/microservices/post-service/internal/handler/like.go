@@ -0,0 +1,158 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/username/progetto/post-service/internal/model"
+	"github.com/username/progetto/post-service/internal/repository"
+	postv1 "github.com/username/progetto/proto/gen/go/post/v1"
+	sharedmodel "github.com/username/progetto/shared/pkg/model"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+const (
+	defaultLikersLimit = 20
+	maxLikersLimit     = 100
+)
+
+// LikePost likes the post for the acting user. The unique (post_id, user_id) index makes
+// the like idempotent, liking again returns the current count and publishes nothing.
+// The like is removed again when the count can't be updated, so a retry counts it once.
+func (h *PostHandler) LikePost(ctx context.Context, req *postv1.LikePostRequest) (*postv1.LikePostResponse, error) {
+	userID, err := actingUser(ctx, req.UserId)
+	if err != nil {
+		return nil, err
+	}
+	post, err := h.getPost(ctx, req.PostId)
+	if err != nil {
+		return nil, err
+	}
+
+	like := &model.Like{
+		PostID:    post.ID.Hex(),
+		AuthorID:  post.AuthorID,
+		UserID:    userID,
+		CreatedAt: time.Now(),
+	}
+	created, err := h.likes.Add(ctx, like)
+	if err != nil {
+		h.logger.ErrorContext(ctx, "failed to like post", "error", err, "post_id", req.PostId)
+		return nil, status.Errorf(codes.Internal, "failed to like post: %v", err)
+	}
+	if !created {
+		return &postv1.LikePostResponse{Success: true, NewLikesCount: post.Likes}, nil
+	}
+
+	post, err = h.repo.IncrementLikes(ctx, like.PostID, 1)
+	if err != nil {
+		if _, undoErr := h.likes.Remove(context.WithoutCancel(ctx), like.PostID, userID); undoErr != nil {
+			h.logger.ErrorContext(ctx, "failed to undo like", "error", undoErr, "post_id", req.PostId)
+		}
+		return nil, h.likeCountError(ctx, req.PostId, err)
+	}
+
+	h.publishLikeEvent(ctx, "post.liked", post, userID)
+	return &postv1.LikePostResponse{Success: true, NewLikesCount: post.Likes}, nil
+}
+
+// UnlikePost removes the like of the acting user, unliking a post that isn't liked changes nothing.
+// The like is restored when the count can't be updated, so a retry counts it once.
+func (h *PostHandler) UnlikePost(ctx context.Context, req *postv1.UnlikePostRequest) (*postv1.UnlikePostResponse, error) {
+	userID, err := actingUser(ctx, req.UserId)
+	if err != nil {
+		return nil, err
+	}
+	post, err := h.getPost(ctx, req.PostId)
+	if err != nil {
+		return nil, err
+	}
+
+	removed, err := h.likes.Remove(ctx, post.ID.Hex(), userID)
+	if err != nil {
+		h.logger.ErrorContext(ctx, "failed to unlike post", "error", err, "post_id", req.PostId)
+		return nil, status.Errorf(codes.Internal, "failed to unlike post: %v", err)
+	}
+	if removed == nil {
+		return &postv1.UnlikePostResponse{Success: true, NewLikesCount: post.Likes}, nil
+	}
+
+	post, err = h.repo.IncrementLikes(ctx, removed.PostID, -1)
+	if err != nil {
+		// The restored like keeps its ID, so it stays in place in the likers list
+		if _, undoErr := h.likes.Add(context.WithoutCancel(ctx), removed); undoErr != nil {
+			h.logger.ErrorContext(ctx, "failed to restore like", "error", undoErr, "post_id", req.PostId)
+		}
+		return nil, h.likeCountError(ctx, req.PostId, err)
+	}
+
+	h.publishLikeEvent(ctx, "post.unliked", post, userID)
+	return &postv1.UnlikePostResponse{Success: true, NewLikesCount: post.Likes}, nil
+}
+
+// ListPostLikers pages through the users who like the post, newest first
+func (h *PostHandler) ListPostLikers(ctx context.Context, req *postv1.ListPostLikersRequest) (*postv1.ListPostLikersResponse, error) {
+	post, err := h.getPost(ctx, req.PostId)
+	if err != nil {
+		return nil, err
+	}
+
+	limit := int64(req.Limit)
+	if limit <= 0 {
+		limit = defaultLikersLimit
+	}
+	limit = min(limit, maxLikersLimit)
+
+	likes, nextToken, err := h.likes.ListByPost(ctx, post.ID.Hex(), limit, req.NextPageToken)
+	if errors.Is(err, repository.ErrInvalidCursor) {
+		return nil, status.Error(codes.InvalidArgument, "invalid page token")
+	}
+	if err != nil {
+		h.logger.ErrorContext(ctx, "failed to list likers", "error", err, "post_id", req.PostId)
+		return nil, status.Errorf(codes.Internal, "failed to list likers: %v", err)
+	}
+
+	likers := make([]*postv1.Liker, 0, len(likes))
+	for _, like := range likes {
+		likers = append(likers, &postv1.Liker{
+			UserId:  like.UserID,
+			LikedAt: timestamppb.New(like.CreatedAt),
+		})
+	}
+	return &postv1.ListPostLikersResponse{Likers: likers, NextPageToken: nextToken}, nil
+}
+
+// getPost loads the post, mapping a missing post to NotFound
+func (h *PostHandler) getPost(ctx context.Context, postID string) (*model.Post, error) {
+	post, err := h.repo.GetByID(ctx, postID)
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil, status.Error(codes.NotFound, "post not found")
+	}
+	if err != nil {
+		h.logger.ErrorContext(ctx, "failed to get post", "error", err, "post_id", postID)
+		return nil, status.Errorf(codes.Internal, "failed to get post: %v", err)
+	}
+	return post, nil
+}
+
+func (h *PostHandler) likeCountError(ctx context.Context, postID string, err error) error {
+	if errors.Is(err, repository.ErrNotFound) {
+		return status.Error(codes.NotFound, "post not found")
+	}
+	h.logger.ErrorContext(ctx, "failed to update likes count", "error", err, "post_id", postID)
+	return status.Errorf(codes.Internal, "failed to update likes count: %v", err)
+}
+
+// publishLikeEvent publishes post.liked or post.unliked with the count after the change
+func (h *PostHandler) publishLikeEvent(ctx context.Context, topic string, post *model.Post, userID string) {
+	h.publish(ctx, topic, post.ID.Hex(), sharedmodel.PostLikeEvent{
+		PostID:     post.ID.Hex(),
+		AuthorID:   post.AuthorID,
+		UserID:     userID,
+		LikesCount: post.Likes,
+		OccurredAt: time.Now(),
+	})
+}
@@ -0,0 +1,177 @@
+package handler
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/username/progetto/post-service/internal/model"
+	postv1 "github.com/username/progetto/proto/gen/go/post/v1"
+	"google.golang.org/grpc/codes"
+)
+
+func TestLikePost(t *testing.T) {
+	tests := []struct {
+		name          string
+		alreadyLiked  bool
+		failIncrement error
+		wantCode      codes.Code
+		wantCount     int32
+		wantLiked     bool
+		wantEvents    []string
+	}{
+		{name: "first like", wantCount: 4, wantLiked: true, wantEvents: []string{"post.liked"}},
+		{name: "liking again is idempotent", alreadyLiked: true, wantCount: 3, wantLiked: true},
+		{name: "failed count update undoes the like", failIncrement: errStorage, wantCode: codes.Internal, wantCount: 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			post := &model.Post{AuthorID: "author", Content: "hello", Likes: 3}
+			posts := newFakePostRepository(post)
+			likes := newFakeLikeRepository()
+			if tt.alreadyLiked {
+				likes.Add(t.Context(), &model.Like{PostID: post.ID.Hex(), UserID: "fan"})
+			}
+			posts.failIncrement = tt.failIncrement
+			h, publisher := newTestHandler(posts, likes, newFakeCommentRepository())
+
+			resp, err := h.LikePost(userContext("fan"), &postv1.LikePostRequest{PostId: post.ID.Hex()})
+			if tt.wantCode != codes.OK {
+				assertCode(t, err, tt.wantCode)
+			} else if err != nil {
+				t.Fatalf("LikePost() error = %v", err)
+			} else if resp.NewLikesCount != tt.wantCount {
+				t.Errorf("NewLikesCount = %d, want %d", resp.NewLikesCount, tt.wantCount)
+			}
+
+			if got := posts.posts[post.ID.Hex()].Likes; got != tt.wantCount {
+				t.Errorf("stored likes = %d, want %d", got, tt.wantCount)
+			}
+			if _, liked := likes.likes[likeKey(post.ID.Hex(), "fan")]; liked != tt.wantLiked {
+				t.Errorf("liked = %v, want %v", liked, tt.wantLiked)
+			}
+			if got := publisher.published(); !slices.Equal(got, tt.wantEvents) {
+				t.Errorf("published = %v, want %v", got, tt.wantEvents)
+			}
+		})
+	}
+}
+
+func TestUnlikePost(t *testing.T) {
+	tests := []struct {
+		name          string
+		liked         bool
+		failIncrement error
+		wantCode      codes.Code
+		wantCount     int32
+		wantLiked     bool
+		wantEvents    []string
+	}{
+		{name: "unlike", liked: true, wantCount: 2, wantEvents: []string{"post.unliked"}},
+		{name: "unliking a post not liked changes nothing", wantCount: 3},
+		{name: "failed count update restores the like", liked: true, failIncrement: errStorage, wantCode: codes.Internal, wantCount: 3, wantLiked: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			post := &model.Post{AuthorID: "author", Content: "hello", Likes: 3}
+			posts := newFakePostRepository(post)
+			likes := newFakeLikeRepository()
+			like := &model.Like{PostID: post.ID.Hex(), AuthorID: post.AuthorID, UserID: "fan"}
+			if tt.liked {
+				likes.Add(t.Context(), like)
+			}
+			posts.failIncrement = tt.failIncrement
+			h, publisher := newTestHandler(posts, likes, newFakeCommentRepository())
+
+			resp, err := h.UnlikePost(userContext("fan"), &postv1.UnlikePostRequest{PostId: post.ID.Hex()})
+			if tt.wantCode != codes.OK {
+				assertCode(t, err, tt.wantCode)
+			} else if err != nil {
+				t.Fatalf("UnlikePost() error = %v", err)
+			} else if resp.NewLikesCount != tt.wantCount {
+				t.Errorf("NewLikesCount = %d, want %d", resp.NewLikesCount, tt.wantCount)
+			}
+
+			if got := posts.posts[post.ID.Hex()].Likes; got != tt.wantCount {
+				t.Errorf("stored likes = %d, want %d", got, tt.wantCount)
+			}
+			restored, liked := likes.likes[likeKey(post.ID.Hex(), "fan")]
+			if liked != tt.wantLiked {
+				t.Fatalf("liked = %v, want %v", liked, tt.wantLiked)
+			}
+			if liked && restored.ID != like.ID {
+				t.Errorf("restored like ID = %s, want %s", restored.ID.Hex(), like.ID.Hex())
+			}
+			if got := publisher.published(); !slices.Equal(got, tt.wantEvents) {
+				t.Errorf("published = %v, want %v", got, tt.wantEvents)
+			}
+		})
+	}
+}
+
+func TestEraseLikes(t *testing.T) {
+	tests := []struct {
+		name          string
+		failIncrement error
+		wantErr       bool
+		wantCount     int32
+		wantLiked     bool
+	}{
+		{name: "erase decrements the liked post", wantCount: 2},
+		{name: "failed count update restores the like", failIncrement: errStorage, wantErr: true, wantCount: 3, wantLiked: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			post := &model.Post{AuthorID: "author", Content: "hello", Likes: 3}
+			posts := newFakePostRepository(post)
+			likes := newFakeLikeRepository()
+			like := &model.Like{PostID: post.ID.Hex(), AuthorID: post.AuthorID, UserID: "fan"}
+			likes.Add(t.Context(), like)
+			posts.failIncrement = tt.failIncrement
+			h := NewUserHandler(nil, posts, likes, newFakeCommentRepository(), &fakePublisher{})
+
+			err := h.eraseLikes(t.Context(), "fan")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("eraseLikes() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got := posts.posts[post.ID.Hex()].Likes; got != tt.wantCount {
+				t.Errorf("stored likes = %d, want %d", got, tt.wantCount)
+			}
+			restored, liked := likes.likes[likeKey(post.ID.Hex(), "fan")]
+			if liked != tt.wantLiked {
+				t.Fatalf("liked = %v, want %v", liked, tt.wantLiked)
+			}
+			if !liked {
+				return
+			}
+			if restored.ID != like.ID {
+				t.Errorf("restored like ID = %s, want %s", restored.ID.Hex(), like.ID.Hex())
+			}
+
+			// The redelivered event finds the like again and decrements the post once
+			posts.failIncrement = nil
+			if err := h.eraseLikes(t.Context(), "fan"); err != nil {
+				t.Fatalf("redelivered eraseLikes() error = %v", err)
+			}
+			if got := posts.posts[post.ID.Hex()].Likes; got != tt.wantCount-1 {
+				t.Errorf("stored likes after redelivery = %d, want %d", got, tt.wantCount-1)
+			}
+		})
+	}
+}
+
+func TestLikePostRequiresUser(t *testing.T) {
+	post := &model.Post{AuthorID: "author", Content: "hello"}
+	h, _ := newTestHandler(newFakePostRepository(post), newFakeLikeRepository(), newFakeCommentRepository())
+
+	_, err := h.LikePost(t.Context(), &postv1.LikePostRequest{PostId: post.ID.Hex()})
+	assertCode(t, err, codes.Unauthenticated)
+
+	_, err = h.LikePost(userContext("fan"), &postv1.LikePostRequest{PostId: post.ID.Hex(), UserId: "someone-else"})
+	assertCode(t, err, codes.PermissionDenied)
+
+	_, err = h.LikePost(userContext("fan"), &postv1.LikePostRequest{PostId: "missing"})
+	assertCode(t, err, codes.NotFound)
+}
@@ -3,6 +3,7 @@ package handler
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"time"
 
 	"log/slog"
@@ -12,6 +13,9 @@ import (
 	"github.com/username/progetto/post-service/internal/model"
 	"github.com/username/progetto/post-service/internal/repository"
 	postv1 "github.com/username/progetto/proto/gen/go/post/v1"
+	"github.com/username/progetto/shared/pkg/grpcutil"
+	sharedmodel "github.com/username/progetto/shared/pkg/model"
+	"github.com/username/progetto/shared/pkg/watermillutil"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/types/known/timestamppb"
@@ -20,25 +24,65 @@ import (
 type PostHandler struct {
 	postv1.UnimplementedPostServiceServer
 	repo      repository.PostRepository
+	likes     repository.LikeRepository
+	comments  repository.CommentRepository
+	timeline  *TimelineHandler
 	publisher message.Publisher
 	logger    *slog.Logger
 }
 
-func NewPostHandler(repo repository.PostRepository, publisher message.Publisher) *PostHandler {
+func NewPostHandler(repo repository.PostRepository, likes repository.LikeRepository, comments repository.CommentRepository, timeline *TimelineHandler, publisher message.Publisher) *PostHandler {
 	return &PostHandler{
 		repo:      repo,
+		likes:     likes,
+		comments:  comments,
+		timeline:  timeline,
 		publisher: publisher,
 		logger:    slog.Default().With("component", "post_handler"),
 	}
 }
 
+// AllowList is the services allowed to call each method of PostService
+var AllowList = grpcutil.MethodAllowList{
+	postv1.PostService_CreatePost_FullMethodName:     {"gateway-service"},
+	postv1.PostService_GetPost_FullMethodName:        {"gateway-service"},
+	postv1.PostService_ListPosts_FullMethodName:      {"gateway-service"},
+	postv1.PostService_GetHomeFeed_FullMethodName:    {"gateway-service"},
+	postv1.PostService_UpdatePost_FullMethodName:     {"gateway-service"},
+	postv1.PostService_DeletePost_FullMethodName:     {"gateway-service"},
+	postv1.PostService_LikePost_FullMethodName:       {"gateway-service"},
+	postv1.PostService_UnlikePost_FullMethodName:     {"gateway-service"},
+	postv1.PostService_ListPostLikers_FullMethodName: {"gateway-service"},
+	postv1.PostService_CreateComment_FullMethodName:  {"gateway-service"},
+	postv1.PostService_ListComments_FullMethodName:   {"gateway-service"},
+	postv1.PostService_UpdateComment_FullMethodName:  {"gateway-service"},
+	postv1.PostService_DeleteComment_FullMethodName:  {"gateway-service"},
+}
+
+// actingUser returns the end user asserted by the calling service.
+// A user ID of the request must match it, services can't act for another user.
+func actingUser(ctx context.Context, requestUserID string) (string, error) {
+	userID, ok := grpcutil.UserIDFromContext(ctx)
+	if !ok {
+		return "", status.Error(codes.Unauthenticated, "authenticated user required")
+	}
+	if requestUserID != "" && requestUserID != userID {
+		return "", status.Error(codes.PermissionDenied, "cannot act for another user")
+	}
+	return userID, nil
+}
+
 func (h *PostHandler) CreatePost(ctx context.Context, req *postv1.CreatePostRequest) (*postv1.CreatePostResponse, error) {
-	if req.AuthorId == "" || req.Content == "" {
-		return nil, status.Error(codes.InvalidArgument, "author_id and content are required")
+	authorID, err := actingUser(ctx, req.AuthorId)
+	if err != nil {
+		return nil, err
+	}
+	if req.Content == "" {
+		return nil, status.Error(codes.InvalidArgument, "content is required")
 	}
 
 	post := &model.Post{
-		AuthorID:  req.AuthorId,
+		AuthorID:  authorID,
 		Content:   req.Content,
 		MediaURLs: req.MediaUrls,
 		Likes:     0,
@@ -52,24 +96,85 @@ func (h *PostHandler) CreatePost(ctx context.Context, req *postv1.CreatePostRequ
 
 	// Publish Event
 	protoPost := h.mapToProto(post)
-	payload, _ := json.Marshal(protoPost)
-	msg := message.NewMessage(watermill.NewUUID(), payload)
-	msg.SetContext(ctx)
-	if err := h.publisher.Publish("post.created", msg); err != nil {
-		// Log error but proceed
-		h.logger.ErrorContext(ctx, "failed to publish post.created event", "error", err, "post_id", post.ID.Hex())
-	}
+	h.publish(ctx, "post.created", post.ID.Hex(), protoPost)
 
 	return &postv1.CreatePostResponse{
 		Post: protoPost,
 	}, nil
 }
 
+// UpdatePost edits a post of the acting user, the replaced version goes to the revision history
+func (h *PostHandler) UpdatePost(ctx context.Context, req *postv1.UpdatePostRequest) (*postv1.UpdatePostResponse, error) {
+	userID, err := actingUser(ctx, req.UserId)
+	if err != nil {
+		return nil, err
+	}
+	if req.Content == "" {
+		return nil, invalidField("content", "is required")
+	}
+	if _, err := h.ownPost(ctx, req.PostId, userID); err != nil {
+		return nil, err
+	}
+
+	post, err := h.repo.Update(ctx, req.PostId, userID, req.Content, req.MediaUrls, time.Now())
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil, status.Error(codes.NotFound, "post not found")
+	}
+	if err != nil {
+		h.logger.ErrorContext(ctx, "failed to update post", "error", err, "post_id", req.PostId)
+		return nil, status.Errorf(codes.Internal, "failed to update post: %v", err)
+	}
+
+	protoPost := h.mapToProto(post)
+	h.publish(ctx, "post.updated", post.ID.Hex(), protoPost)
+	return &postv1.UpdatePostResponse{Post: protoPost}, nil
+}
+
+// DeletePost replaces a post of the acting user with a tombstone
+func (h *PostHandler) DeletePost(ctx context.Context, req *postv1.DeletePostRequest) (*postv1.DeletePostResponse, error) {
+	userID, err := actingUser(ctx, req.UserId)
+	if err != nil {
+		return nil, err
+	}
+	post, err := h.ownPost(ctx, req.PostId, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	deletedAt := time.Now()
+	err = h.repo.SoftDelete(ctx, req.PostId, userID, deletedAt)
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil, status.Error(codes.NotFound, "post not found")
+	}
+	if err != nil {
+		h.logger.ErrorContext(ctx, "failed to delete post", "error", err, "post_id", req.PostId)
+		return nil, status.Errorf(codes.Internal, "failed to delete post: %v", err)
+	}
+
+	h.publish(ctx, "post.deleted", post.ID.Hex(), sharedmodel.PostDeletedEvent{
+		PostID:    post.ID.Hex(),
+		AuthorID:  post.AuthorID,
+		DeletedAt: deletedAt,
+	})
+	return &postv1.DeletePostResponse{}, nil
+}
+
+// ownPost loads a post of the user, posts of other users are PermissionDenied
+func (h *PostHandler) ownPost(ctx context.Context, postID, userID string) (*model.Post, error) {
+	post, err := h.getPost(ctx, postID)
+	if err != nil {
+		return nil, err
+	}
+	if post.AuthorID != userID {
+		return nil, status.Error(codes.PermissionDenied, "only the author can change a post")
+	}
+	return post, nil
+}
+
 func (h *PostHandler) GetPost(ctx context.Context, req *postv1.GetPostRequest) (*postv1.GetPostResponse, error) {
-	post, err := h.repo.GetByID(ctx, req.PostId)
+	post, err := h.getPost(ctx, req.PostId)
 	if err != nil {
-		h.logger.WarnContext(ctx, "post not found", "error", err, "post_id", req.PostId)
-		return nil, status.Errorf(codes.NotFound, "post not found: %v", err)
+		return nil, err
 	}
 	return &postv1.GetPostResponse{
 		Post: h.mapToProto(post),
@@ -98,13 +203,31 @@ func (h *PostHandler) ListPosts(ctx context.Context, req *postv1.ListPostsReques
 	}, nil
 }
 
+// publish publishes the event keyed by post, so the events of a post stay ordered.
+// The change is already stored, a failed publish is only logged.
+func (h *PostHandler) publish(ctx context.Context, topic, postID string, event any) {
+	payload, _ := json.Marshal(event)
+	msg := message.NewMessage(watermill.NewUUID(), payload)
+	msg.Metadata.Set(watermillutil.PartitionKeyMetadata, postID)
+	msg.SetContext(ctx)
+	if err := h.publisher.Publish(topic, msg); err != nil {
+		h.logger.ErrorContext(ctx, "failed to publish "+topic+" event", "error", err, "post_id", postID)
+	}
+}
+
 func (h *PostHandler) mapToProto(p *model.Post) *postv1.Post {
+	var editedAt *timestamppb.Timestamp
+	if p.EditedAt != nil {
+		editedAt = timestamppb.New(*p.EditedAt)
+	}
 	return &postv1.Post{
-		Id:         p.ID.Hex(),
-		AuthorId:   p.AuthorID,
-		Content:    p.Content,
-		MediaUrls:  p.MediaURLs,
-		LikesCount: p.Likes,
-		CreatedAt:  timestamppb.New(p.CreatedAt),
+		Id:            p.ID.Hex(),
+		AuthorId:      p.AuthorID,
+		Content:       p.Content,
+		MediaUrls:     p.MediaURLs,
+		LikesCount:    p.Likes,
+		CommentsCount: p.Comments,
+		CreatedAt:     timestamppb.New(p.CreatedAt),
+		EditedAt:      editedAt,
 	}
 }
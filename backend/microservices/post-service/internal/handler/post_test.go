@@ -0,0 +1,112 @@
+package handler
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/username/progetto/post-service/internal/model"
+	postv1 "github.com/username/progetto/proto/gen/go/post/v1"
+	"google.golang.org/grpc/codes"
+)
+
+func TestUpdatePost(t *testing.T) {
+	deletedAt := time.Now()
+	tests := []struct {
+		name      string
+		user      string
+		content   string
+		deleted   bool
+		wantCode  codes.Code
+		wantEvent bool
+	}{
+		{name: "author edits", user: "author", content: "edited", wantEvent: true},
+		{name: "other user", user: "mallory", content: "edited", wantCode: codes.PermissionDenied},
+		{name: "empty content", user: "author", content: "", wantCode: codes.InvalidArgument},
+		{name: "deleted post", user: "author", content: "edited", deleted: true, wantCode: codes.NotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			post := &model.Post{AuthorID: "author", Content: "original", MediaURLs: []string{"a.png"}, CreatedAt: time.Now().Add(-time.Hour), Likes: 2}
+			if tt.deleted {
+				post.DeletedAt = &deletedAt
+			}
+			posts := newFakePostRepository(post)
+			h, publisher := newTestHandler(posts, newFakeLikeRepository(), newFakeCommentRepository())
+
+			resp, err := h.UpdatePost(userContext(tt.user), &postv1.UpdatePostRequest{PostId: post.ID.Hex(), Content: tt.content})
+			if tt.wantCode != codes.OK {
+				assertCode(t, err, tt.wantCode)
+				if got := posts.posts[post.ID.Hex()]; got.Content != "original" {
+					t.Errorf("content = %q, want unchanged", got.Content)
+				}
+			} else {
+				if err != nil {
+					t.Fatalf("UpdatePost() error = %v", err)
+				}
+				if resp.Post.Content != tt.content || resp.Post.EditedAt == nil || resp.Post.LikesCount != 2 {
+					t.Errorf("UpdatePost() post = %v, want edited content with the counters kept", resp.Post)
+				}
+				revisions := posts.posts[post.ID.Hex()].Revisions
+				if len(revisions) != 1 || revisions[0].Content != "original" {
+					t.Errorf("revisions = %v, want the original version", revisions)
+				}
+			}
+
+			var want []string
+			if tt.wantEvent {
+				want = []string{"post.updated"}
+			}
+			if got := publisher.published(); !slices.Equal(got, want) {
+				t.Errorf("published = %v, want %v", got, want)
+			}
+		})
+	}
+}
+
+func TestDeletePost(t *testing.T) {
+	tests := []struct {
+		name     string
+		user     string
+		wantCode codes.Code
+	}{
+		{name: "author deletes", user: "author"},
+		{name: "other user", user: "mallory", wantCode: codes.PermissionDenied},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			post := &model.Post{AuthorID: "author", Content: "original", Likes: 2, Comments: 1}
+			posts := newFakePostRepository(post)
+			h, publisher := newTestHandler(posts, newFakeLikeRepository(), newFakeCommentRepository())
+
+			_, err := h.DeletePost(userContext(tt.user), &postv1.DeletePostRequest{PostId: post.ID.Hex()})
+			if tt.wantCode != codes.OK {
+				assertCode(t, err, tt.wantCode)
+				if _, err := h.GetPost(userContext(tt.user), &postv1.GetPostRequest{PostId: post.ID.Hex()}); err != nil {
+					t.Errorf("GetPost() after a refused delete error = %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("DeletePost() error = %v", err)
+			}
+
+			tombstone := posts.posts[post.ID.Hex()]
+			if tombstone.DeletedAt == nil || tombstone.Content != "" {
+				t.Errorf("stored post = %+v, want a tombstone without content", tombstone)
+			}
+			if tombstone.Likes != 2 || tombstone.Comments != 1 {
+				t.Errorf("counters = (%d, %d), want them kept", tombstone.Likes, tombstone.Comments)
+			}
+			_, err = h.GetPost(userContext(tt.user), &postv1.GetPostRequest{PostId: post.ID.Hex()})
+			assertCode(t, err, codes.NotFound)
+			_, err = h.DeletePost(userContext(tt.user), &postv1.DeletePostRequest{PostId: post.ID.Hex()})
+			assertCode(t, err, codes.NotFound)
+			if got := publisher.published(); !slices.Equal(got, []string{"post.deleted"}) {
+				t.Errorf("published = %v, want [post.deleted]", got)
+			}
+		})
+	}
+}
@@ -0,0 +1,194 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"slices"
+	"strings"
+
+	"github.com/ThreeDotsLabs/watermill/message"
+	"github.com/username/progetto/post-service/internal/model"
+	"github.com/username/progetto/post-service/internal/repository"
+	postv1 "github.com/username/progetto/proto/gen/go/post/v1"
+	socialv1 "github.com/username/progetto/proto/gen/go/social/v1"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	defaultFeedLimit = 20
+	maxFeedLimit     = 100
+	// followersPageSize is how many followers are fanned out at a time
+	followersPageSize = 1000
+	// maxCelebrities bounds the followed celebrities merged into a feed page
+	maxCelebrities = 1000
+)
+
+// TimelineHandler fans new posts out to the home timelines of the followers of their author
+// and reads the home feed back. Posts of authors with celebrityFollowers followers or more
+// aren't fanned out, they are merged into the feed of their followers when it's read.
+type TimelineHandler struct {
+	posts              repository.PostRepository
+	timelines          repository.TimelineRepository
+	social             socialv1.SocialServiceClient
+	celebrityFollowers int64
+	logger             *slog.Logger
+}
+
+func NewTimelineHandler(posts repository.PostRepository, timelines repository.TimelineRepository, social socialv1.SocialServiceClient, celebrityFollowers int64) *TimelineHandler {
+	return &TimelineHandler{
+		posts:              posts,
+		timelines:          timelines,
+		social:             social,
+		celebrityFollowers: celebrityFollowers,
+		logger:             slog.Default().With("component", "timeline_handler"),
+	}
+}
+
+// HandlePostCreated writes the post to the timeline of its author and of each follower.
+// Timeline inserts are idempotent, a redelivered event is fanned out again harmlessly.
+func (h *TimelineHandler) HandlePostCreated(msg *message.Message) error {
+	ctx := msg.Context()
+	var post postv1.Post
+	if err := json.Unmarshal(msg.Payload, &post); err != nil || post.Id == "" || post.AuthorId == "" {
+		h.logger.ErrorContext(ctx, "failed to unmarshal post.created event", "error", err)
+		return nil // Don't retry malformed messages
+	}
+
+	if err := h.timelines.Add(ctx, []string{post.AuthorId}, post.Id, post.AuthorId); err != nil {
+		h.logger.ErrorContext(ctx, "failed to write author timeline", "error", err, "post_id", post.Id)
+		return err // Retry
+	}
+
+	followers, err := h.social.CountFollowers(ctx, &socialv1.CountFollowersRequest{UserId: post.AuthorId})
+	if err != nil {
+		h.logger.ErrorContext(ctx, "failed to count followers", "error", err, "author_id", post.AuthorId)
+		return err // Retry
+	}
+	if followers.Count >= h.celebrityFollowers {
+		h.logger.InfoContext(ctx, "post left to fan-out-on-read", "post_id", post.Id, "followers", followers.Count)
+		return nil
+	}
+
+	fannedOut := 0
+	token := ""
+	for {
+		resp, err := h.social.ListFollowers(ctx, &socialv1.ListFollowersRequest{
+			UserId:        post.AuthorId,
+			Limit:         followersPageSize,
+			NextPageToken: token,
+		})
+		if err != nil {
+			h.logger.ErrorContext(ctx, "failed to list followers", "error", err, "author_id", post.AuthorId)
+			return err // Retry
+		}
+		if err := h.timelines.Add(ctx, resp.FollowerIds, post.Id, post.AuthorId); err != nil {
+			h.logger.ErrorContext(ctx, "failed to write follower timelines", "error", err, "post_id", post.Id)
+			return err // Retry
+		}
+		fannedOut += len(resp.FollowerIds)
+		if resp.NextPageToken == "" {
+			break
+		}
+		token = resp.NextPageToken
+	}
+
+	h.logger.InfoContext(ctx, "post fanned out", "post_id", post.Id, "followers", fannedOut)
+	return nil
+}
+
+// HomeFeed returns up to limit posts of the home feed of the user older than the before
+// post ID, newest first, and the token of the next page, empty on the last one.
+// The fanned out timeline is merged with the posts of the followed celebrities.
+func (h *TimelineHandler) HomeFeed(ctx context.Context, userID, before string, limit int) ([]*model.Post, string, error) {
+	ids, err := h.timelines.Page(ctx, userID, before, limit)
+	if err != nil {
+		return nil, "", err
+	}
+	timelinePosts, err := h.posts.GetByIDs(ctx, ids)
+	if err != nil {
+		return nil, "", err
+	}
+	celebrityPosts, err := h.posts.ListByAuthors(ctx, h.followedCelebrities(ctx, userID), before, int64(limit))
+	if err != nil {
+		return nil, "", err
+	}
+
+	// A full source may have more posts past its last one, so the merged feed is only
+	// complete down to the highest of these boundaries. ObjectID hex strings sort by time.
+	boundary := ""
+	if len(ids) == limit {
+		boundary = ids[len(ids)-1]
+	}
+	if len(celebrityPosts) == limit {
+		boundary = max(boundary, celebrityPosts[len(celebrityPosts)-1].ID.Hex())
+	}
+
+	// Deleted posts are gone from the hydrated ones, a celebrity's own post is in both sources
+	seen := make(map[string]bool)
+	var feed []*model.Post
+	for _, post := range append(timelinePosts, celebrityPosts...) {
+		id := post.ID.Hex()
+		if seen[id] || id < boundary {
+			continue
+		}
+		seen[id] = true
+		feed = append(feed, post)
+	}
+	slices.SortFunc(feed, func(a, b *model.Post) int {
+		return strings.Compare(b.ID.Hex(), a.ID.Hex())
+	})
+
+	nextToken := boundary
+	if len(feed) > limit {
+		feed = feed[:limit]
+		nextToken = feed[limit-1].ID.Hex()
+	}
+	return feed, nextToken, nil
+}
+
+// followedCelebrities returns the followed users whose posts aren't fanned out.
+// Without the follow graph the feed degrades to the fanned out posts.
+func (h *TimelineHandler) followedCelebrities(ctx context.Context, userID string) []string {
+	resp, err := h.social.ListFollowing(ctx, &socialv1.ListFollowingRequest{
+		UserId:       userID,
+		MinFollowers: h.celebrityFollowers,
+		Limit:        maxCelebrities,
+	})
+	if err != nil {
+		h.logger.WarnContext(ctx, "failed to list followed celebrities", "error", err, "user_id", userID)
+		return nil
+	}
+	return resp.UserIds
+}
+
+// GetHomeFeed pages through the home feed of the acting user, newest first
+func (h *PostHandler) GetHomeFeed(ctx context.Context, req *postv1.GetHomeFeedRequest) (*postv1.GetHomeFeedResponse, error) {
+	userID, err := actingUser(ctx, req.UserId)
+	if err != nil {
+		return nil, err
+	}
+
+	limit := int(req.Limit)
+	if limit <= 0 {
+		limit = defaultFeedLimit
+	}
+	limit = min(limit, maxFeedLimit)
+
+	posts, nextToken, err := h.timeline.HomeFeed(ctx, userID, req.NextPageToken, limit)
+	if errors.Is(err, repository.ErrInvalidCursor) {
+		return nil, status.Error(codes.InvalidArgument, "invalid page token")
+	}
+	if err != nil {
+		h.logger.ErrorContext(ctx, "failed to read home feed", "error", err, "user_id", userID)
+		return nil, status.Errorf(codes.Internal, "failed to read home feed: %v", err)
+	}
+
+	protoPosts := make([]*postv1.Post, 0, len(posts))
+	for _, post := range posts {
+		protoPosts = append(protoPosts, h.mapToProto(post))
+	}
+	return &postv1.GetHomeFeedResponse{Posts: protoPosts, NextPageToken: nextToken}, nil
+}
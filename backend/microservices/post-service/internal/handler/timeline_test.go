@@ -0,0 +1,316 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ThreeDotsLabs/watermill"
+	"github.com/ThreeDotsLabs/watermill/message"
+	"github.com/username/progetto/post-service/internal/model"
+	"github.com/username/progetto/post-service/internal/repository"
+	postv1 "github.com/username/progetto/proto/gen/go/post/v1"
+	socialv1 "github.com/username/progetto/proto/gen/go/social/v1"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+)
+
+// fakeTimelineRepository keeps the timelines in memory, ordered by post ID like the Cassandra table
+type fakeTimelineRepository struct {
+	timelines map[string]map[string]bool
+}
+
+func newFakeTimelineRepository() *fakeTimelineRepository {
+	return &fakeTimelineRepository{timelines: make(map[string]map[string]bool)}
+}
+
+func (r *fakeTimelineRepository) Add(ctx context.Context, userIDs []string, postID, authorID string) error {
+	for _, userID := range userIDs {
+		if r.timelines[userID] == nil {
+			r.timelines[userID] = make(map[string]bool)
+		}
+		r.timelines[userID][postID] = true
+	}
+	return nil
+}
+
+func (r *fakeTimelineRepository) Page(ctx context.Context, userID, before string, limit int) ([]string, error) {
+	if _, err := primitive.ObjectIDFromHex(before); before != "" && err != nil {
+		return nil, repository.ErrInvalidCursor
+	}
+	var ids []string
+	for id := range r.timelines[userID] {
+		if before == "" || id < before {
+			ids = append(ids, id)
+		}
+	}
+	slices.SortFunc(ids, func(a, b string) int { return strings.Compare(b, a) })
+	return ids[:min(limit, len(ids))], nil
+}
+
+// fakeSocialClient serves the follow graph, followers maps each user to their followers
+type fakeSocialClient struct {
+	socialv1.SocialServiceClient
+	followers     map[string][]string
+	failFollowing error
+}
+
+func (c *fakeSocialClient) ListFollowers(ctx context.Context, in *socialv1.ListFollowersRequest, opts ...grpc.CallOption) (*socialv1.ListFollowersResponse, error) {
+	start, _ := strconv.Atoi(in.NextPageToken)
+	followers := c.followers[in.UserId]
+	end := min(start+int(in.Limit), len(followers))
+	resp := &socialv1.ListFollowersResponse{FollowerIds: followers[start:end]}
+	if end < len(followers) {
+		resp.NextPageToken = strconv.Itoa(end)
+	}
+	return resp, nil
+}
+
+func (c *fakeSocialClient) CountFollowers(ctx context.Context, in *socialv1.CountFollowersRequest, opts ...grpc.CallOption) (*socialv1.CountFollowersResponse, error) {
+	return &socialv1.CountFollowersResponse{Count: int64(len(c.followers[in.UserId]))}, nil
+}
+
+func (c *fakeSocialClient) ListFollowing(ctx context.Context, in *socialv1.ListFollowingRequest, opts ...grpc.CallOption) (*socialv1.ListFollowingResponse, error) {
+	if c.failFollowing != nil {
+		return nil, c.failFollowing
+	}
+	var following []string
+	for userID, followers := range c.followers {
+		if slices.Contains(followers, in.UserId) && int64(len(followers)) >= in.MinFollowers {
+			following = append(following, userID)
+		}
+	}
+	slices.Sort(following)
+	return &socialv1.ListFollowingResponse{UserIds: following}, nil
+}
+
+// feedFixture is the home feed of "me", who follows "friend" and "celeb".
+// With 3 followers celeb is a celebrity, its posts are merged on read instead of fanned out.
+type feedFixture struct {
+	posts     *fakePostRepository
+	timelines *fakeTimelineRepository
+	social    *fakeSocialClient
+	timeline  *TimelineHandler
+	// ids of the posts "me" should see, newest first
+	want []string
+}
+
+const testCelebrityFollowers = 3
+
+func newFeedFixture(t *testing.T, count int) *feedFixture {
+	t.Helper()
+	f := &feedFixture{
+		posts:     newFakePostRepository(),
+		timelines: newFakeTimelineRepository(),
+		social: &fakeSocialClient{followers: map[string][]string{
+			"friend":   {"me"},
+			"celeb":    {"me", "fan1", "fan2"},
+			"stranger": {"fan1"},
+		}},
+	}
+	f.timeline = NewTimelineHandler(f.posts, f.timelines, f.social, testCelebrityFollowers)
+
+	authors := []string{"celeb", "friend", "friend", "stranger"}
+	base := time.Now().Add(-time.Hour)
+	for i := range count {
+		post := &model.Post{
+			ID:        primitive.NewObjectIDFromTimestamp(base.Add(time.Duration(i) * time.Second)),
+			AuthorID:  authors[i%len(authors)],
+			Content:   "post " + strconv.Itoa(i),
+			CreatedAt: base.Add(time.Duration(i) * time.Second),
+		}
+		f.posts.posts[post.ID.Hex()] = post
+		f.publishCreated(t, post)
+		if post.AuthorID != "stranger" {
+			f.want = append([]string{post.ID.Hex()}, f.want...)
+		}
+	}
+	return f
+}
+
+func (f *feedFixture) publishCreated(t *testing.T, post *model.Post) {
+	t.Helper()
+	payload, _ := json.Marshal(&postv1.Post{Id: post.ID.Hex(), AuthorId: post.AuthorID})
+	if err := f.timeline.HandlePostCreated(message.NewMessage(watermill.NewUUID(), payload)); err != nil {
+		t.Fatalf("HandlePostCreated() error = %v", err)
+	}
+}
+
+// readFeed pages through the whole feed of the user and returns the post IDs in order
+func readFeed(t *testing.T, timeline *TimelineHandler, userID string, limit int) []string {
+	t.Helper()
+	var ids []string
+	before := ""
+	for page := 0; ; page++ {
+		if page > 100 {
+			t.Fatal("the feed doesn't end")
+		}
+		posts, next, err := timeline.HomeFeed(t.Context(), userID, before, limit)
+		if err != nil {
+			t.Fatalf("HomeFeed() error = %v", err)
+		}
+		if len(posts) > limit {
+			t.Fatalf("HomeFeed() returned %d posts, limit %d", len(posts), limit)
+		}
+		for _, post := range posts {
+			ids = append(ids, post.ID.Hex())
+		}
+		if next == "" {
+			return ids
+		}
+		if before != "" && next >= before {
+			t.Fatalf("next page token %s doesn't move past %s", next, before)
+		}
+		before = next
+	}
+}
+
+func TestHandlePostCreated(t *testing.T) {
+	f := newFeedFixture(t, 8)
+
+	for _, tt := range []struct {
+		user string
+		want []string
+	}{
+		// Posts are fanned out to the author and the followers, except those of celebrities
+		{user: "me", want: []string{"friend"}},
+		{user: "fan1", want: []string{"stranger"}},
+		{user: "friend", want: []string{"friend"}},
+		{user: "celeb", want: []string{"celeb"}},
+	} {
+		var authors []string
+		for id := range f.timelines.timelines[tt.user] {
+			authors = append(authors, f.posts.posts[id].AuthorID)
+		}
+		slices.Sort(authors)
+		authors = slices.Compact(authors)
+		if !slices.Equal(authors, tt.want) {
+			t.Errorf("timeline of %s has posts of %v, want %v", tt.user, authors, tt.want)
+		}
+	}
+}
+
+func TestHandlePostCreatedPagesFollowers(t *testing.T) {
+	posts := newFakePostRepository()
+	timelines := newFakeTimelineRepository()
+	followers := make([]string, followersPageSize+5)
+	for i := range followers {
+		followers[i] = "follower" + strconv.Itoa(i)
+	}
+	social := &fakeSocialClient{followers: map[string][]string{"author": followers}}
+	timeline := NewTimelineHandler(posts, timelines, social, int64(len(followers)+1))
+
+	payload, _ := json.Marshal(&postv1.Post{Id: primitive.NewObjectID().Hex(), AuthorId: "author"})
+	if err := timeline.HandlePostCreated(message.NewMessage(watermill.NewUUID(), payload)); err != nil {
+		t.Fatalf("HandlePostCreated() error = %v", err)
+	}
+	if got := len(timelines.timelines); got != len(followers)+1 {
+		t.Errorf("timelines written = %d, want %d", got, len(followers)+1)
+	}
+}
+
+func TestHomeFeedPaging(t *testing.T) {
+	f := newFeedFixture(t, 23)
+
+	// Every page size must return each post once and in order, whichever source ends first
+	for _, limit := range []int{1, 2, 3, 5, 7, 16, 17, 30} {
+		t.Run("limit "+strconv.Itoa(limit), func(t *testing.T) {
+			if got := readFeed(t, f.timeline, "me", limit); !slices.Equal(got, f.want) {
+				t.Errorf("feed = %v, want %v", got, f.want)
+			}
+		})
+	}
+}
+
+func TestHomeFeed(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(f *feedFixture) []string
+	}{
+		{
+			name: "deleted posts are skipped",
+			modify: func(f *feedFixture) []string {
+				deletedAt := time.Now()
+				f.posts.posts[f.want[1]].DeletedAt = &deletedAt
+				f.posts.posts[f.want[4]].DeletedAt = &deletedAt
+				want := slices.Clone(f.want)
+				return slices.Delete(slices.Delete(want, 4, 5), 1, 2)
+			},
+		},
+		{
+			name: "a page of deleted posts doesn't hide older ones",
+			modify: func(f *feedFixture) []string {
+				deletedAt := time.Now()
+				var want []string
+				for i, id := range f.want {
+					if i < len(f.want)/2 && f.posts.posts[id].AuthorID == "friend" {
+						f.posts.posts[id].DeletedAt = &deletedAt
+						continue
+					}
+					want = append(want, id)
+				}
+				return want
+			},
+		},
+		{
+			name: "without the follow graph only fanned out posts are shown",
+			modify: func(f *feedFixture) []string {
+				f.social.failFollowing = errStorage
+				var want []string
+				for _, id := range f.want {
+					if f.posts.posts[id].AuthorID != "celeb" {
+						want = append(want, id)
+					}
+				}
+				return want
+			},
+		},
+		{
+			name: "a post in both sources is shown once",
+			modify: func(f *feedFixture) []string {
+				for _, id := range f.want {
+					if f.posts.posts[id].AuthorID == "celeb" {
+						f.timelines.Add(context.Background(), []string{"me"}, id, "celeb")
+					}
+				}
+				return f.want
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newFeedFixture(t, 13)
+			want := tt.modify(f)
+			for limit := 1; limit <= 14; limit++ {
+				if got := readFeed(t, f.timeline, "me", limit); !slices.Equal(got, want) {
+					t.Errorf("limit %d: feed = %v, want %v", limit, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestGetHomeFeed(t *testing.T) {
+	f := newFeedFixture(t, 5)
+	h := NewPostHandler(f.posts, newFakeLikeRepository(), newFakeCommentRepository(), f.timeline, &fakePublisher{})
+
+	resp, err := h.GetHomeFeed(userContext("me"), &postv1.GetHomeFeedRequest{Limit: 2})
+	if err != nil {
+		t.Fatalf("GetHomeFeed() error = %v", err)
+	}
+	if len(resp.Posts) != 2 || resp.Posts[0].Id != f.want[0] || resp.NextPageToken == "" {
+		t.Errorf("GetHomeFeed() = %v, want the 2 newest posts and a next page", resp)
+	}
+
+	_, err = h.GetHomeFeed(userContext("me"), &postv1.GetHomeFeedRequest{NextPageToken: "not-a-post-id"})
+	assertCode(t, err, codes.InvalidArgument)
+
+	_, err = h.GetHomeFeed(userContext("me"), &postv1.GetHomeFeedRequest{UserId: "someone-else"})
+	assertCode(t, err, codes.PermissionDenied)
+}
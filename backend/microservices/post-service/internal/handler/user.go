@@ -1,7 +1,9 @@
 package handler
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"log/slog"
 
 	"strconv"
@@ -10,18 +12,25 @@ import (
 	"github.com/ThreeDotsLabs/watermill/message"
 	"github.com/username/progetto/post-service/internal/model"
 	"github.com/username/progetto/post-service/internal/repository"
+	sharedmodel "github.com/username/progetto/shared/pkg/model"
 	"github.com/username/progetto/shared/pkg/resiliency"
 )
 
 type UserHandler struct {
 	Repo      repository.UserRepository
+	Posts     repository.PostRepository
+	Likes     repository.LikeRepository
+	Comments  repository.CommentRepository
 	Publisher message.Publisher
 	Logger    *slog.Logger
 }
 
-func NewUserHandler(repo repository.UserRepository, publisher message.Publisher) *UserHandler {
+func NewUserHandler(repo repository.UserRepository, posts repository.PostRepository, likes repository.LikeRepository, comments repository.CommentRepository, publisher message.Publisher) *UserHandler {
 	return &UserHandler{
 		Repo:      repo,
+		Posts:     posts,
+		Likes:     likes,
+		Comments:  comments,
 		Publisher: publisher,
 		Logger:    slog.Default().With("component", "user_handler"),
 	}
@@ -32,6 +41,7 @@ func (h *UserHandler) HandleCreated(msg *message.Message) error {
 		UserID   string `json:"user_id"`
 		Email    string `json:"email"`
 		Username string `json:"username"`
+		Version  int64  `json:"version"`
 	}
 
 	if err := json.Unmarshal(msg.Payload, &payload); err != nil {
@@ -53,6 +63,7 @@ func (h *UserHandler) HandleCreated(msg *message.Message) error {
 		ID:       uint(userIDUint),
 		Email:    payload.Email,
 		Username: payload.Username,
+		Version:  payload.Version,
 	}
 
 	if err := h.Repo.Save(msg.Context(), user); err != nil {
@@ -80,6 +91,119 @@ func (h *UserHandler) HandleCreated(msg *message.Message) error {
 	return nil
 }
 
+// HandleUpdated refreshes the local copy of the user, older versions are ignored
+func (h *UserHandler) HandleUpdated(msg *message.Message) error {
+	var payload sharedmodel.UserUpdatedEvent
+	if err := json.Unmarshal(msg.Payload, &payload); err != nil {
+		h.Logger.ErrorContext(msg.Context(), "failed to unmarshal message", "error", err)
+		return nil // Don't retry malformed messages
+	}
+
+	user, err := payload.User()
+	if err != nil {
+		h.Logger.ErrorContext(msg.Context(), "failed to parse user_id", "error", err)
+		return resiliency.NewPermanentError(err)
+	}
+
+	h.Logger.InfoContext(msg.Context(), "received user_updated event", "user_id", payload.UserID, "version", payload.Version)
+
+	if err := h.Repo.Save(msg.Context(), &user); err != nil {
+		h.Logger.ErrorContext(msg.Context(), "failed to save user", "error", err)
+		return err // Retry
+	}
+	return nil
+}
+
+// HandleDeletionRequested erases the likes, the comments, the posts and the copy of the user, then confirms with user_deleted_post.
+// Every deletion is idempotent, so retried requests are confirmed again.
+func (h *UserHandler) HandleDeletionRequested(msg *message.Message) error {
+	var payload struct {
+		DeletionID string `json:"deletion_id"`
+		UserID     string `json:"user_id"`
+	}
+	if err := json.Unmarshal(msg.Payload, &payload); err != nil || payload.UserID == "" {
+		h.Logger.ErrorContext(msg.Context(), "failed to unmarshal message", "error", err)
+		return nil // Don't retry malformed messages
+	}
+
+	h.Logger.InfoContext(msg.Context(), "received user_deletion_requested event", "user_id", payload.UserID, "deletion_id", payload.DeletionID)
+
+	if err := h.eraseLikes(msg.Context(), payload.UserID); err != nil {
+		h.Logger.ErrorContext(msg.Context(), "failed to delete likes", "error", err)
+		return err // Retry
+	}
+	if err := h.eraseComments(msg.Context(), payload.UserID); err != nil {
+		h.Logger.ErrorContext(msg.Context(), "failed to delete comments", "error", err)
+		return err // Retry
+	}
+	deleted, err := h.Posts.DeleteByAuthor(msg.Context(), payload.UserID)
+	if err != nil {
+		h.Logger.ErrorContext(msg.Context(), "failed to delete posts", "error", err)
+		return err // Retry
+	}
+	if err := h.Repo.Delete(msg.Context(), payload.UserID); err != nil {
+		h.Logger.ErrorContext(msg.Context(), "failed to delete user", "error", err)
+		return err // Retry
+	}
+
+	confirmBytes, _ := json.Marshal(payload)
+	confirmMsg := message.NewMessage(watermill.NewUUID(), confirmBytes)
+	confirmMsg.Metadata.Set("user_id", payload.UserID)
+	confirmMsg.SetContext(msg.Context())
+
+	if err := h.Publisher.Publish("user_deleted_post", confirmMsg); err != nil {
+		h.Logger.ErrorContext(msg.Context(), "failed to publish user_deleted_post event", "error", err)
+		return err // Retry
+	}
+
+	h.Logger.InfoContext(msg.Context(), "user data erased", "user_id", payload.UserID, "posts", deleted)
+	return nil
+}
+
+// eraseLikes removes the likes of the user, decrementing the liked posts, and the likes of the user's posts.
+// A like is only decremented by the call that removed it. When the decrement fails the like is restored,
+// so the redelivered event removes and decrements it again.
+func (h *UserHandler) eraseLikes(ctx context.Context, userID string) error {
+	postIDs, err := h.Likes.PostsLikedBy(ctx, userID)
+	if err != nil {
+		return err
+	}
+	for _, postID := range postIDs {
+		removed, err := h.Likes.Remove(ctx, postID, userID)
+		if err != nil {
+			return err
+		}
+		if removed == nil {
+			continue
+		}
+		if _, err := h.Posts.IncrementLikes(ctx, postID, -1); err != nil && !errors.Is(err, repository.ErrNotFound) {
+			if _, undoErr := h.Likes.Add(context.WithoutCancel(ctx), removed); undoErr != nil {
+				h.Logger.ErrorContext(ctx, "failed to restore like", "error", undoErr, "post_id", postID, "user_id", userID)
+			}
+			return err
+		}
+	}
+
+	_, err = h.Likes.DeleteByPostAuthor(ctx, userID)
+	return err
+}
+
+// eraseComments removes the comments of the user with their replies, and the comments of the user's posts
+func (h *UserHandler) eraseComments(ctx context.Context, userID string) error {
+	comments, err := h.Comments.ListByAuthor(ctx, userID)
+	if err != nil {
+		return err
+	}
+	for _, comment := range comments {
+		if _, err := deleteComment(ctx, h.Comments, h.Posts, comment); err != nil {
+			return err
+		}
+	}
+
+	_, err = h.Comments.DeleteByPostAuthor(ctx, userID)
+	return err
+}
+
 // HandleFailure constructs a compensation message for user creation failure.
 func (h *UserHandler) HandleFailure(err error, msg *message.Message) (string, *message.Message, error) {
 	userID := msg.Metadata.Get("user_id")
@@ -5,4 +5,6 @@ import (
 )
 
 type Post = model.Post
+type Like = model.Like
+type Comment = model.Comment
 type User = model.User
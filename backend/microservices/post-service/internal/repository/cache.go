@@ -0,0 +1,317 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/username/progetto/post-service/internal/model"
+	"github.com/username/progetto/shared/pkg/observability"
+	"golang.org/x/sync/errgroup"
+	"golang.org/x/sync/singleflight"
+)
+
+const (
+	// postCacheTTL is slid on every read of a post body, counters expire after it from their load
+	postCacheTTL = 24 * time.Hour
+	// loadConcurrency bounds the cold posts of a batch read loaded at a time
+	loadConcurrency = 16
+	// invalidatePageSize is how many posts of an author are invalidated at a time
+	invalidatePageSize = 1000
+	// invalidationWindow is how long an invalidated post isn't cached again,
+	// so a load that read the post before the change can't cache the old version
+	invalidationWindow = 10 * time.Second
+)
+
+// incrIfExistsScript is INCR_IF_EXISTS: it adds ARGV[1] to a cached counter, clamped at zero
+// like the stored count, and returns nil on a cold key. Creating the key would restart the
+// counter from the delta, it's set from the stored post on the next read instead.
+// On a cold key the invalidation marker KEYS[2] is set for ARGV[2] milliseconds,
+// a load that read the post before the change can't cache the old count.
+var incrIfExistsScript = redis.NewScript(`
+if redis.call("EXISTS", KEYS[1]) == 0 then
+	redis.call("SET", KEYS[2], 1, "PX", ARGV[2])
+	return nil
+end
+local value = redis.call("INCRBY", KEYS[1], ARGV[1])
+if value < 0 then
+	redis.call("SET", KEYS[1], 0, "KEEPTTL")
+	value = 0
+end
+return value
+`)
+
+// storeScript caches the body and the counters of a post unless it was just invalidated.
+// KEYS: body, likes, comments, invalidation marker. ARGV: body, likes, comments, TTL in seconds.
+var storeScript = redis.NewScript(`
+if redis.call("EXISTS", KEYS[4]) == 1 then
+	return 0
+end
+redis.call("SET", KEYS[1], ARGV[1], "EX", ARGV[4])
+redis.call("SET", KEYS[2], ARGV[2], "EX", ARGV[4])
+redis.call("SET", KEYS[3], ARGV[3], "EX", ARGV[4])
+return 1
+`)
+
+// cachedPostRepository is a read-through Redis cache in front of a PostRepository.
+// The body of a post, without its revision history, is cached under post:{id} with a sliding TTL,
+// the likes and comments counts under post:{id}:likes and post:{id}:comments, updated in place.
+// Concurrent misses of a post share one load. Redis errors fall back to the wrapped repository.
+type cachedPostRepository struct {
+	PostRepository
+	rdb     *redis.Client
+	group   singleflight.Group
+	metrics *observability.CacheMetrics
+	logger  *slog.Logger
+}
+
+func NewCachedPostRepository(next PostRepository, rdb *redis.Client) PostRepository {
+	return &cachedPostRepository{
+		PostRepository: next,
+		rdb:            rdb,
+		metrics:        observability.NewCacheMetrics("post"),
+		logger:         slog.Default().With("component", "post_cache"),
+	}
+}
+
+func postKey(id string) string        { return "post:" + id }
+func likesKey(id string) string       { return "post:" + id + ":likes" }
+func commentsKey(id string) string    { return "post:" + id + ":comments" }
+func invalidatedKey(id string) string { return "post:" + id + ":invalidated" }
+
+func (r *cachedPostRepository) Create(ctx context.Context, post *model.Post) error {
+	if err := r.PostRepository.Create(ctx, post); err != nil {
+		return err
+	}
+	r.store(ctx, post)
+	return nil
+}
+
+func (r *cachedPostRepository) GetByID(ctx context.Context, id string) (*model.Post, error) {
+	if post, ok := r.cached(ctx, []string{id})[id]; ok {
+		r.metrics.Hit(ctx, 1)
+		return post, nil
+	}
+	r.metrics.Miss(ctx, 1)
+	return r.load(ctx, id)
+}
+
+func (r *cachedPostRepository) GetByIDs(ctx context.Context, ids []string) ([]*model.Post, error) {
+	cached := r.cached(ctx, ids)
+	posts := make([]*model.Post, 0, len(ids))
+	var missing []string
+	for _, id := range ids {
+		if post, ok := cached[id]; ok {
+			posts = append(posts, post)
+		} else {
+			missing = append(missing, id)
+		}
+	}
+	r.metrics.Hit(ctx, len(posts))
+	r.metrics.Miss(ctx, len(missing))
+
+	loaded := make([]*model.Post, len(missing))
+	g, gctx := errgroup.WithContext(ctx)
+	g.SetLimit(loadConcurrency)
+	for i, id := range missing {
+		g.Go(func() error {
+			post, err := r.load(gctx, id)
+			if errors.Is(err, ErrNotFound) {
+				return nil
+			}
+			loaded[i] = post
+			return err
+		})
+	}
+	if err := g.Wait(); err != nil {
+		return nil, err
+	}
+
+	for _, post := range loaded {
+		if post != nil {
+			posts = append(posts, post)
+		}
+	}
+	return posts, nil
+}
+
+func (r *cachedPostRepository) Update(ctx context.Context, id, authorID, content string, mediaURLs []string, editedAt time.Time) (*model.Post, error) {
+	post, err := r.PostRepository.Update(ctx, id, authorID, content, mediaURLs, editedAt)
+	if err != nil {
+		return nil, err
+	}
+	r.invalidate(ctx, id)
+	return post, nil
+}
+
+func (r *cachedPostRepository) SoftDelete(ctx context.Context, id, authorID string, deletedAt time.Time) error {
+	if err := r.PostRepository.SoftDelete(ctx, id, authorID, deletedAt); err != nil {
+		return err
+	}
+	r.invalidate(ctx, id)
+	return nil
+}
+
+func (r *cachedPostRepository) IncrementLikes(ctx context.Context, id string, delta int32) (*model.Post, error) {
+	post, err := r.PostRepository.IncrementLikes(ctx, id, delta)
+	if err != nil {
+		return nil, err
+	}
+	r.incrIfExists(ctx, id, likesKey(id), delta)
+	return post, nil
+}
+
+func (r *cachedPostRepository) IncrementComments(ctx context.Context, id string, delta int32) (*model.Post, error) {
+	post, err := r.PostRepository.IncrementComments(ctx, id, delta)
+	if err != nil {
+		return nil, err
+	}
+	r.incrIfExists(ctx, id, commentsKey(id), delta)
+	return post, nil
+}
+
+func (r *cachedPostRepository) DeleteByAuthor(ctx context.Context, authorID string) (int64, error) {
+	// The posts are listed first, once deleted there's nothing left to find the cached ones
+	var ids []string
+	cursor := ""
+	for {
+		posts, next, err := r.PostRepository.List(ctx, authorID, invalidatePageSize, cursor)
+		if err != nil {
+			return 0, err
+		}
+		for _, post := range posts {
+			ids = append(ids, post.ID.Hex())
+		}
+		if len(posts) < invalidatePageSize {
+			break
+		}
+		cursor = next
+	}
+
+	deleted, err := r.PostRepository.DeleteByAuthor(ctx, authorID)
+	if err != nil {
+		return 0, err
+	}
+	r.invalidate(ctx, ids...)
+	return deleted, nil
+}
+
+// cached returns the posts fully cached among the IDs, sliding the TTL of their bodies.
+// A post whose body or counters are cold is missing.
+func (r *cachedPostRepository) cached(ctx context.Context, ids []string) map[string]*model.Post {
+	type entry struct {
+		body, likes, comments *redis.StringCmd
+	}
+	entries := make([]entry, len(ids))
+	pipe := r.rdb.Pipeline()
+	for i, id := range ids {
+		entries[i] = entry{
+			body:     pipe.GetEx(ctx, postKey(id), postCacheTTL),
+			likes:    pipe.Get(ctx, likesKey(id)),
+			comments: pipe.Get(ctx, commentsKey(id)),
+		}
+	}
+	if _, err := pipe.Exec(ctx); err != nil && !errors.Is(err, redis.Nil) {
+		r.logger.WarnContext(ctx, "failed to read post cache", "error", err)
+		return nil
+	}
+
+	posts := make(map[string]*model.Post, len(ids))
+	for i, id := range ids {
+		body, err := entries[i].body.Bytes()
+		if err != nil {
+			continue
+		}
+		likes, err := entries[i].likes.Int()
+		if err != nil {
+			continue
+		}
+		comments, err := entries[i].comments.Int()
+		if err != nil {
+			continue
+		}
+
+		var post model.Post
+		if err := json.Unmarshal(body, &post); err != nil {
+			r.logger.WarnContext(ctx, "failed to decode cached post", "error", err, "post_id", id)
+			continue
+		}
+		post.Likes = int32(likes)
+		post.Comments = int32(comments)
+		posts[id] = &post
+	}
+	return posts
+}
+
+// load reads a cold post from the wrapped repository and caches it.
+// Concurrent loads of the post share the first one, which isn't canceled with its caller.
+func (r *cachedPostRepository) load(ctx context.Context, id string) (*model.Post, error) {
+	v, err, _ := r.group.Do(id, func() (any, error) {
+		ctx := context.WithoutCancel(ctx)
+		post, err := r.PostRepository.GetByID(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		r.store(ctx, post)
+		return post, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	// Every caller gets its own copy of the shared result
+	post := *v.(*model.Post)
+	return &post, nil
+}
+
+// store caches the body and the counters of the post
+func (r *cachedPostRepository) store(ctx context.Context, post *model.Post) {
+	id := post.ID.Hex()
+	body, err := json.Marshal(post)
+	if err != nil {
+		r.logger.WarnContext(ctx, "failed to encode post for the cache", "error", err, "post_id", id)
+		return
+	}
+
+	keys := []string{postKey(id), likesKey(id), commentsKey(id), invalidatedKey(id)}
+	err = storeScript.Run(ctx, r.rdb, keys, body, post.Likes, post.Comments, int(postCacheTTL/time.Second)).Err()
+	if err != nil {
+		r.logger.WarnContext(ctx, "failed to cache post", "error", err, "post_id", id)
+	}
+}
+
+// incrIfExists applies the change of a stored counter to its cached copy, if any.
+// A cold counter keeps the post out of the cache for the invalidation window, like invalidate.
+// When that fails the post is invalidated, a stale counter would outlive the request.
+func (r *cachedPostRepository) incrIfExists(ctx context.Context, id, key string, delta int32) {
+	keys := []string{key, invalidatedKey(id)}
+	err := incrIfExistsScript.Run(ctx, r.rdb, keys, strconv.Itoa(int(delta)), invalidationWindow.Milliseconds()).Err()
+	if errors.Is(err, redis.Nil) {
+		r.group.Forget(id)
+		return
+	}
+	if err != nil {
+		r.logger.WarnContext(ctx, "failed to update cached counter", "error", err, "key", key)
+		r.invalidate(ctx, id)
+	}
+}
+
+// invalidate drops the cached posts and keeps them out of the cache for the invalidation window.
+// Loads in flight aren't shared with later readers, so they read the change.
+func (r *cachedPostRepository) invalidate(ctx context.Context, ids ...string) {
+	if len(ids) == 0 {
+		return
+	}
+	pipe := r.rdb.Pipeline()
+	for _, id := range ids {
+		r.group.Forget(id)
+		pipe.Set(ctx, invalidatedKey(id), 1, invalidationWindow)
+		pipe.Del(ctx, postKey(id), likesKey(id), commentsKey(id))
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		r.logger.ErrorContext(ctx, "failed to invalidate cached posts", "error", err, "posts", len(ids))
+	}
+}
@@ -0,0 +1,390 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/username/progetto/post-service/internal/model"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// fakePostRepository is an in-memory PostRepository counting the reads of single posts.
+// When loaded is set, GetByID signals it after reading the post and waits for release.
+type fakePostRepository struct {
+	PostRepository
+	mu      sync.Mutex
+	posts   map[string]*model.Post
+	gets    int
+	loaded  chan struct{}
+	release chan struct{}
+}
+
+func newFakePostRepository(posts ...*model.Post) *fakePostRepository {
+	repo := &fakePostRepository{posts: make(map[string]*model.Post)}
+	for _, post := range posts {
+		repo.posts[post.ID.Hex()] = post
+	}
+	return repo
+}
+
+func (f *fakePostRepository) GetByID(_ context.Context, id string) (*model.Post, error) {
+	f.mu.Lock()
+	f.gets++
+	post, ok := f.posts[id]
+	var snapshot model.Post
+	if ok {
+		snapshot = *post
+	}
+	loaded, release := f.loaded, f.release
+	f.mu.Unlock()
+
+	if loaded != nil {
+		loaded <- struct{}{}
+		<-release
+	}
+	if !ok || snapshot.DeletedAt != nil {
+		return nil, ErrNotFound
+	}
+	return &snapshot, nil
+}
+
+func (f *fakePostRepository) Update(_ context.Context, id, authorID, content string, mediaURLs []string, editedAt time.Time) (*model.Post, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	post, ok := f.posts[id]
+	if !ok || post.AuthorID != authorID {
+		return nil, ErrNotFound
+	}
+	post.Content = content
+	post.MediaURLs = mediaURLs
+	post.EditedAt = &editedAt
+	updated := *post
+	return &updated, nil
+}
+
+func (f *fakePostRepository) SoftDelete(_ context.Context, id, authorID string, deletedAt time.Time) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	post, ok := f.posts[id]
+	if !ok || post.AuthorID != authorID {
+		return ErrNotFound
+	}
+	post.Content = ""
+	post.DeletedAt = &deletedAt
+	return nil
+}
+
+func (f *fakePostRepository) increment(id string, counter func(*model.Post) *int32, delta int32) (*model.Post, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	post, ok := f.posts[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	count := counter(post)
+	*count = max(*count+delta, 0)
+	updated := *post
+	return &updated, nil
+}
+
+func (f *fakePostRepository) IncrementLikes(_ context.Context, id string, delta int32) (*model.Post, error) {
+	return f.increment(id, func(p *model.Post) *int32 { return &p.Likes }, delta)
+}
+
+func (f *fakePostRepository) IncrementComments(_ context.Context, id string, delta int32) (*model.Post, error) {
+	return f.increment(id, func(p *model.Post) *int32 { return &p.Comments }, delta)
+}
+
+func (f *fakePostRepository) reads() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.gets
+}
+
+func newTestPost(likes, comments int32) *model.Post {
+	return &model.Post{
+		ID:        primitive.NewObjectID(),
+		AuthorID:  "author-1",
+		Content:   "hello",
+		Likes:     likes,
+		Comments:  comments,
+		CreatedAt: time.Now().UTC().Truncate(time.Millisecond),
+	}
+}
+
+func newTestCache(t *testing.T, next PostRepository) (*cachedPostRepository, *miniredis.Miniredis) {
+	t.Helper()
+	mr := miniredis.RunT(t)
+	rdb := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { rdb.Close() })
+	return NewCachedPostRepository(next, rdb).(*cachedPostRepository), mr
+}
+
+func TestCachedPostRepositoryGetByID(t *testing.T) {
+	post := newTestPost(3, 2)
+	repo := newFakePostRepository(post)
+	cache, _ := newTestCache(t, repo)
+	ctx := context.Background()
+
+	for i := range 3 {
+		got, err := cache.GetByID(ctx, post.ID.Hex())
+		if err != nil {
+			t.Fatalf("GetByID() #%d error = %v", i, err)
+		}
+		if got.Content != post.Content || got.Likes != post.Likes || got.Comments != post.Comments {
+			t.Errorf("GetByID() #%d = %+v, want %+v", i, got, post)
+		}
+	}
+	if got := repo.reads(); got != 1 {
+		t.Errorf("repository reads = %d, want 1", got)
+	}
+
+	if _, err := cache.GetByID(ctx, primitive.NewObjectID().Hex()); !errors.Is(err, ErrNotFound) {
+		t.Errorf("GetByID() of a missing post error = %v, want %v", err, ErrNotFound)
+	}
+}
+
+func TestCachedPostRepositoryIncrement(t *testing.T) {
+	tests := []struct {
+		name         string
+		warm         bool
+		likes        int32
+		comments     int32
+		wantLikes    int32
+		wantComments int32
+		wantCached   bool
+	}{
+		{name: "warm counters", warm: true, likes: 1, comments: 2, wantLikes: 4, wantComments: 7, wantCached: true},
+		{name: "warm counters clamped at zero", warm: true, likes: -10, comments: -10, wantLikes: 0, wantComments: 0, wantCached: true},
+		{name: "cold counters", likes: 1, comments: 2, wantLikes: 4, wantComments: 7},
+		{name: "cold counters clamped at zero", likes: -10, comments: -10, wantLikes: 0, wantComments: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			post := newTestPost(3, 5)
+			id := post.ID.Hex()
+			repo := newFakePostRepository(post)
+			cache, mr := newTestCache(t, repo)
+			ctx := context.Background()
+
+			if tt.warm {
+				if _, err := cache.GetByID(ctx, id); err != nil {
+					t.Fatalf("GetByID() error = %v", err)
+				}
+			}
+			if _, err := cache.IncrementLikes(ctx, id, tt.likes); err != nil {
+				t.Fatalf("IncrementLikes() error = %v", err)
+			}
+			if _, err := cache.IncrementComments(ctx, id, tt.comments); err != nil {
+				t.Fatalf("IncrementComments() error = %v", err)
+			}
+
+			if got := mr.Exists(likesKey(id)); got != tt.wantCached {
+				t.Errorf("likes cached = %v, want %v", got, tt.wantCached)
+			}
+			if got := mr.Exists(invalidatedKey(id)); got == tt.wantCached {
+				t.Errorf("invalidation marker set = %v, want %v", got, !tt.wantCached)
+			}
+
+			got, err := cache.GetByID(ctx, id)
+			if err != nil {
+				t.Fatalf("GetByID() error = %v", err)
+			}
+			if got.Likes != tt.wantLikes || got.Comments != tt.wantComments {
+				t.Errorf("GetByID() counters = %d/%d, want %d/%d", got.Likes, got.Comments, tt.wantLikes, tt.wantComments)
+			}
+		})
+	}
+}
+
+func TestCachedPostRepositoryInFlightLoad(t *testing.T) {
+	tests := []struct {
+		name      string
+		change    func(ctx context.Context, cache *cachedPostRepository, id string) error
+		wantLikes int32
+		wantErr   error
+	}{
+		{
+			name: "cold counter incremented",
+			change: func(ctx context.Context, cache *cachedPostRepository, id string) error {
+				_, err := cache.IncrementLikes(ctx, id, 1)
+				return err
+			},
+			wantLikes: 4,
+		},
+		{
+			name: "post updated",
+			change: func(ctx context.Context, cache *cachedPostRepository, id string) error {
+				_, err := cache.Update(ctx, id, "author-1", "edited", nil, time.Now())
+				return err
+			},
+			wantLikes: 3,
+		},
+		{
+			name: "post deleted",
+			change: func(ctx context.Context, cache *cachedPostRepository, id string) error {
+				return cache.SoftDelete(ctx, id, "author-1", time.Now())
+			},
+			wantErr: ErrNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			post := newTestPost(3, 0)
+			id := post.ID.Hex()
+			repo := newFakePostRepository(post)
+			repo.loaded = make(chan struct{})
+			repo.release = make(chan struct{})
+			cache, mr := newTestCache(t, repo)
+			ctx := context.Background()
+
+			done := make(chan error)
+			go func() {
+				_, err := cache.GetByID(ctx, id)
+				done <- err
+			}()
+			<-repo.loaded
+
+			if err := tt.change(ctx, cache, id); err != nil {
+				t.Fatalf("change error = %v", err)
+			}
+			// The load in flight read the post before the change
+			release := repo.release
+			repo.mu.Lock()
+			repo.loaded, repo.release = nil, nil
+			repo.mu.Unlock()
+			close(release)
+			if err := <-done; err != nil {
+				t.Fatalf("GetByID() in flight error = %v", err)
+			}
+
+			if mr.Exists(postKey(id)) {
+				t.Errorf("post cached by the load in flight")
+			}
+			got, err := cache.GetByID(ctx, id)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("GetByID() error = %v, want %v", err, tt.wantErr)
+			}
+			if err == nil && got.Likes != tt.wantLikes {
+				t.Errorf("GetByID() likes = %d, want %d", got.Likes, tt.wantLikes)
+			}
+		})
+	}
+}
+
+func TestCachedPostRepositoryInvalidate(t *testing.T) {
+	tests := []struct {
+		name        string
+		change      func(ctx context.Context, cache *cachedPostRepository, id string) error
+		wantContent string
+		wantErr     error
+	}{
+		{
+			name: "update",
+			change: func(ctx context.Context, cache *cachedPostRepository, id string) error {
+				_, err := cache.Update(ctx, id, "author-1", "edited", nil, time.Now())
+				return err
+			},
+			wantContent: "edited",
+		},
+		{
+			name: "soft delete",
+			change: func(ctx context.Context, cache *cachedPostRepository, id string) error {
+				return cache.SoftDelete(ctx, id, "author-1", time.Now())
+			},
+			wantErr: ErrNotFound,
+		},
+		{
+			name: "update of another author",
+			change: func(ctx context.Context, cache *cachedPostRepository, id string) error {
+				if _, err := cache.Update(ctx, id, "author-2", "edited", nil, time.Now()); !errors.Is(err, ErrNotFound) {
+					return errors.New("update of another author not refused")
+				}
+				return nil
+			},
+			wantContent: "hello",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			post := newTestPost(1, 1)
+			id := post.ID.Hex()
+			repo := newFakePostRepository(post)
+			cache, mr := newTestCache(t, repo)
+			ctx := context.Background()
+
+			if _, err := cache.GetByID(ctx, id); err != nil {
+				t.Fatalf("GetByID() error = %v", err)
+			}
+			if err := tt.change(ctx, cache, id); err != nil {
+				t.Fatalf("change error = %v", err)
+			}
+
+			got, err := cache.GetByID(ctx, id)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("GetByID() error = %v, want %v", err, tt.wantErr)
+			}
+			if err == nil && got.Content != tt.wantContent {
+				t.Errorf("GetByID() content = %q, want %q", got.Content, tt.wantContent)
+			}
+
+			if err != nil {
+				return
+			}
+			// Once the invalidation window is over the post is cached again
+			mr.FastForward(invalidationWindow)
+			if _, err := cache.GetByID(ctx, id); err != nil {
+				t.Fatalf("GetByID() error = %v", err)
+			}
+			reads := repo.reads()
+			if _, err := cache.GetByID(ctx, id); err != nil {
+				t.Fatalf("GetByID() error = %v", err)
+			}
+			if got := repo.reads(); got != reads {
+				t.Errorf("repository reads = %d, want %d", got, reads)
+			}
+		})
+	}
+}
+
+func TestCachedPostRepositoryGetByIDs(t *testing.T) {
+	warm, cold, deleted := newTestPost(1, 0), newTestPost(2, 0), newTestPost(3, 0)
+	now := time.Now()
+	deleted.DeletedAt = &now
+	repo := newFakePostRepository(warm, cold, deleted)
+	cache, _ := newTestCache(t, repo)
+	ctx := context.Background()
+
+	if _, err := cache.GetByID(ctx, warm.ID.Hex()); err != nil {
+		t.Fatalf("GetByID() error = %v", err)
+	}
+	ids := []string{warm.ID.Hex(), cold.ID.Hex(), deleted.ID.Hex(), primitive.NewObjectID().Hex()}
+	posts, err := cache.GetByIDs(ctx, ids)
+	if err != nil {
+		t.Fatalf("GetByIDs() error = %v", err)
+	}
+
+	got := make([]string, 0, len(posts))
+	for _, post := range posts {
+		got = append(got, post.ID.Hex())
+	}
+	want := []string{warm.ID.Hex(), cold.ID.Hex()}
+	sort.Strings(got)
+	sort.Strings(want)
+	if len(got) != len(want) || got[0] != want[0] || got[1] != want[1] {
+		t.Errorf("GetByIDs() = %v, want %v", got, want)
+	}
+	// The warm post is read once, the others once each by the batch
+	if got := repo.reads(); got != 4 {
+		t.Errorf("repository reads = %d, want 4", got)
+	}
+}
@@ -0,0 +1,157 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/username/progetto/post-service/internal/model"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type CommentRepository interface {
+	// Create stores the comment, a comment with an ID keeps it so a deleted comment can be restored
+	Create(ctx context.Context, comment *model.Comment) error
+	GetByID(ctx context.Context, id string) (*model.Comment, error)
+	// List returns the comments of the post with the given parent oldest first, an empty parent
+	// lists the top level comments. The cursor is empty on the last page.
+	List(ctx context.Context, postID, parentID string, limit int64, cursor string) ([]*model.Comment, string, error)
+	// UpdateContent replaces the content and sets the edit time, returning the updated comment
+	UpdateContent(ctx context.Context, id, content string, editedAt time.Time) (*model.Comment, error)
+	// Delete removes the comment, it returns false when it was already deleted
+	Delete(ctx context.Context, id string) (bool, error)
+	// IncrementReplies atomically adds delta to the replies count, which never goes below zero
+	IncrementReplies(ctx context.Context, id string, delta int32) error
+	// ListByAuthor returns every comment of the author
+	ListByAuthor(ctx context.Context, authorID string) ([]*model.Comment, error)
+	// DeleteByPostAuthor removes the comments of every post of the author and returns how many were deleted
+	DeleteByPostAuthor(ctx context.Context, postAuthorID string) (int64, error)
+}
+
+type mongoCommentRepository struct {
+	collection *mongo.Collection
+}
+
+func NewMongoCommentRepository(db *mongo.Database) CommentRepository {
+	return &mongoCommentRepository{collection: db.Collection("comments")}
+}
+
+// commentIndexes serve the thread listing and the erasure queries
+var commentIndexes = []mongo.IndexModel{
+	{Keys: bson.D{{Key: "post_id", Value: 1}, {Key: "parent_id", Value: 1}, {Key: "_id", Value: 1}}},
+	{Keys: bson.D{{Key: "parent_id", Value: 1}}},
+	{Keys: bson.D{{Key: "author_id", Value: 1}}},
+	{Keys: bson.D{{Key: "post_author_id", Value: 1}}},
+}
+
+func (r *mongoCommentRepository) Create(ctx context.Context, comment *model.Comment) error {
+	res, err := r.collection.InsertOne(ctx, comment)
+	if err != nil {
+		return err
+	}
+	comment.ID = res.InsertedID.(primitive.ObjectID)
+	return nil
+}
+
+func (r *mongoCommentRepository) GetByID(ctx context.Context, id string) (*model.Comment, error) {
+	oid, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, ErrNotFound
+	}
+	var comment model.Comment
+	err = r.collection.FindOne(ctx, bson.M{"_id": oid}).Decode(&comment)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, ErrNotFound
+	}
+	return &comment, err
+}
+
+func (r *mongoCommentRepository) List(ctx context.Context, postID, parentID string, limit int64, cursor string) ([]*model.Comment, string, error) {
+	filter := bson.M{"post_id": postID, "parent_id": parentID}
+	if cursor != "" {
+		oid, err := primitive.ObjectIDFromHex(cursor)
+		if err != nil {
+			return nil, "", ErrInvalidCursor
+		}
+		filter["_id"] = bson.M{"$gt": oid}
+	}
+
+	opts := options.Find().SetLimit(limit).SetSort(bson.M{"_id": 1})
+	cur, err := r.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, "", err
+	}
+	var comments []*model.Comment
+	if err := cur.All(ctx, &comments); err != nil {
+		return nil, "", err
+	}
+
+	// A short page is the last one
+	nextCursor := ""
+	if int64(len(comments)) == limit {
+		nextCursor = comments[len(comments)-1].ID.Hex()
+	}
+	return comments, nextCursor, nil
+}
+
+func (r *mongoCommentRepository) UpdateContent(ctx context.Context, id, content string, editedAt time.Time) (*model.Comment, error) {
+	oid, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, ErrNotFound
+	}
+	var comment model.Comment
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+	update := bson.M{"$set": bson.M{"content": content, "edited_at": editedAt}}
+	err = r.collection.FindOneAndUpdate(ctx, bson.M{"_id": oid}, update, opts).Decode(&comment)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &comment, nil
+}
+
+func (r *mongoCommentRepository) Delete(ctx context.Context, id string) (bool, error) {
+	oid, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return false, nil
+	}
+	res, err := r.collection.DeleteOne(ctx, bson.M{"_id": oid})
+	if err != nil {
+		return false, err
+	}
+	return res.DeletedCount > 0, nil
+}
+
+func (r *mongoCommentRepository) IncrementReplies(ctx context.Context, id string, delta int32) error {
+	oid, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return ErrNotFound
+	}
+	_, err = r.collection.UpdateOne(ctx, bson.M{"_id": oid}, counterUpdate("replies_count", delta))
+	return err
+}
+
+func (r *mongoCommentRepository) ListByAuthor(ctx context.Context, authorID string) ([]*model.Comment, error) {
+	cur, err := r.collection.Find(ctx, bson.M{"author_id": authorID})
+	if err != nil {
+		return nil, err
+	}
+	var comments []*model.Comment
+	if err := cur.All(ctx, &comments); err != nil {
+		return nil, err
+	}
+	return comments, nil
+}
+
+func (r *mongoCommentRepository) DeleteByPostAuthor(ctx context.Context, postAuthorID string) (int64, error) {
+	res, err := r.collection.DeleteMany(ctx, bson.M{"post_author_id": postAuthorID})
+	if err != nil {
+		return 0, err
+	}
+	return res.DeletedCount, nil
+}
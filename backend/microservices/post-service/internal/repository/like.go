@@ -0,0 +1,120 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"github.com/username/progetto/post-service/internal/model"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+var ErrInvalidCursor = errors.New("invalid page token")
+
+type LikeRepository interface {
+	// Add stores the like, it returns false when the user already likes the post
+	Add(ctx context.Context, like *model.Like) (bool, error)
+	// Remove deletes and returns the like, nil when the user doesn't like the post
+	Remove(ctx context.Context, postID, userID string) (*model.Like, error)
+	// ListByPost returns the likes of the post newest first, the cursor is empty on the last page
+	ListByPost(ctx context.Context, postID string, limit int64, cursor string) ([]*model.Like, string, error)
+	// PostsLikedBy returns the IDs of the posts the user likes
+	PostsLikedBy(ctx context.Context, userID string) ([]string, error)
+	// DeleteByPostAuthor removes the likes of every post of the author and returns how many were deleted
+	DeleteByPostAuthor(ctx context.Context, authorID string) (int64, error)
+}
+
+type mongoLikeRepository struct {
+	collection *mongo.Collection
+}
+
+func NewMongoLikeRepository(db *mongo.Database) LikeRepository {
+	return &mongoLikeRepository{collection: db.Collection("likes")}
+}
+
+// likeIndexes make double likes impossible and serve the likers and erasure queries
+var likeIndexes = []mongo.IndexModel{
+	{Keys: bson.D{{Key: "post_id", Value: 1}, {Key: "user_id", Value: 1}}, Options: options.Index().SetUnique(true)},
+	{Keys: bson.D{{Key: "post_id", Value: 1}, {Key: "_id", Value: -1}}},
+	{Keys: bson.D{{Key: "user_id", Value: 1}}},
+	{Keys: bson.D{{Key: "author_id", Value: 1}}},
+}
+
+func (r *mongoLikeRepository) Add(ctx context.Context, like *model.Like) (bool, error) {
+	res, err := r.collection.InsertOne(ctx, like)
+	if mongo.IsDuplicateKeyError(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	like.ID = res.InsertedID.(primitive.ObjectID)
+	return true, nil
+}
+
+func (r *mongoLikeRepository) Remove(ctx context.Context, postID, userID string) (*model.Like, error) {
+	var like model.Like
+	err := r.collection.FindOneAndDelete(ctx, bson.M{"post_id": postID, "user_id": userID}).Decode(&like)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &like, nil
+}
+
+func (r *mongoLikeRepository) ListByPost(ctx context.Context, postID string, limit int64, cursor string) ([]*model.Like, string, error) {
+	filter := bson.M{"post_id": postID}
+	if cursor != "" {
+		oid, err := primitive.ObjectIDFromHex(cursor)
+		if err != nil {
+			return nil, "", ErrInvalidCursor
+		}
+		filter["_id"] = bson.M{"$lt": oid}
+	}
+
+	opts := options.Find().SetLimit(limit).SetSort(bson.M{"_id": -1})
+	cur, err := r.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, "", err
+	}
+	var likes []*model.Like
+	if err := cur.All(ctx, &likes); err != nil {
+		return nil, "", err
+	}
+
+	// A short page is the last one
+	nextCursor := ""
+	if int64(len(likes)) == limit {
+		nextCursor = likes[len(likes)-1].ID.Hex()
+	}
+	return likes, nextCursor, nil
+}
+
+func (r *mongoLikeRepository) PostsLikedBy(ctx context.Context, userID string) ([]string, error) {
+	cur, err := r.collection.Find(ctx, bson.M{"user_id": userID}, options.Find().SetProjection(bson.M{"post_id": 1}))
+	if err != nil {
+		return nil, err
+	}
+	var likes []*model.Like
+	if err := cur.All(ctx, &likes); err != nil {
+		return nil, err
+	}
+
+	postIDs := make([]string, 0, len(likes))
+	for _, like := range likes {
+		postIDs = append(postIDs, like.PostID)
+	}
+	return postIDs, nil
+}
+
+func (r *mongoLikeRepository) DeleteByPostAuthor(ctx context.Context, authorID string) (int64, error) {
+	res, err := r.collection.DeleteMany(ctx, bson.M{"author_id": authorID})
+	if err != nil {
+		return 0, err
+	}
+	return res.DeletedCount, nil
+}
@@ -2,7 +2,10 @@ package repository
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"strconv"
+	"time"
 
 	"github.com/username/progetto/post-service/internal/model"
 	"go.mongodb.org/mongo-driver/bson"
@@ -11,14 +14,63 @@ import (
 	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
+var ErrNotFound = errors.New("not found")
+
+// maxRevisions caps the edit history kept on a post
+const maxRevisions = 20
+
+// notDeleted filters out the tombstones of deleted posts
+var notDeleted = bson.M{"$exists": false}
+
+// EnsureIndexes creates the indexes of the collections, existing indexes are left as they are
+func EnsureIndexes(ctx context.Context, db *mongo.Database) error {
+	if _, err := db.Collection("posts").Indexes().CreateMany(ctx, postIndexes); err != nil {
+		return fmt.Errorf("failed to create posts indexes: %w", err)
+	}
+	if _, err := db.Collection("likes").Indexes().CreateMany(ctx, likeIndexes); err != nil {
+		return fmt.Errorf("failed to create likes indexes: %w", err)
+	}
+	if _, err := db.Collection("comments").Indexes().CreateMany(ctx, commentIndexes); err != nil {
+		return fmt.Errorf("failed to create comments indexes: %w", err)
+	}
+	return nil
+}
+
+// postIndexes serve the listing of the posts of an author, newest first
+var postIndexes = []mongo.IndexModel{
+	{Keys: bson.D{{Key: "author_id", Value: 1}, {Key: "_id", Value: -1}}},
+}
+
 type PostRepository interface {
 	Create(ctx context.Context, post *model.Post) error
+	// GetByID returns the post, deleted posts are ErrNotFound
 	GetByID(ctx context.Context, id string) (*model.Post, error)
+	// GetByIDs returns the posts found among the IDs in no particular order,
+	// missing and deleted posts are skipped
+	GetByIDs(ctx context.Context, ids []string) ([]*model.Post, error)
+	// Update replaces the content and media of a post of the author, pushing the replaced
+	// version to the capped revision history, and returns the updated post
+	Update(ctx context.Context, id, authorID, content string, mediaURLs []string, editedAt time.Time) (*model.Post, error)
+	// SoftDelete replaces the post of the author with a tombstone, erasing its content and history.
+	// The counters stay, so the likes and comments of the post remain consistent.
+	SoftDelete(ctx context.Context, id, authorID string, deletedAt time.Time) error
+	// IncrementLikes atomically adds delta to the likes count and returns the updated post.
+	// The count never goes below zero.
+	IncrementLikes(ctx context.Context, id string, delta int32) (*model.Post, error)
+	// IncrementComments atomically adds delta to the comments count, which never goes below zero
+	IncrementComments(ctx context.Context, id string, delta int32) (*model.Post, error)
 	List(ctx context.Context, authorID string, limit int64, cursor string) ([]*model.Post, string, error)
+	// ListByAuthors returns the newest posts of the authors older than the before post ID,
+	// empty for the newest ones. An invalid before is ErrInvalidCursor.
+	ListByAuthors(ctx context.Context, authorIDs []string, before string, limit int64) ([]*model.Post, error)
+	// DeleteByAuthor removes every post of the author and returns how many were deleted
+	DeleteByAuthor(ctx context.Context, authorID string) (int64, error)
 }
 
 type UserRepository interface {
+	// Save upserts the user unless a newer version is already stored
 	Save(ctx context.Context, user *model.User) error
+	Delete(ctx context.Context, userID string) error
 }
 
 type mongoPostRepository struct {
@@ -41,15 +93,126 @@ func (r *mongoPostRepository) Create(ctx context.Context, post *model.Post) erro
 func (r *mongoPostRepository) GetByID(ctx context.Context, id string) (*model.Post, error) {
 	oid, err := primitive.ObjectIDFromHex(id)
 	if err != nil {
-		return nil, err
+		return nil, ErrNotFound
 	}
 	var post model.Post
-	err = r.collection.FindOne(ctx, bson.M{"_id": oid}).Decode(&post)
+	err = r.collection.FindOne(ctx, bson.M{"_id": oid, "deleted_at": notDeleted}).Decode(&post)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, ErrNotFound
+	}
 	return &post, err
 }
 
+func (r *mongoPostRepository) GetByIDs(ctx context.Context, ids []string) ([]*model.Post, error) {
+	oids := make([]primitive.ObjectID, 0, len(ids))
+	for _, id := range ids {
+		if oid, err := primitive.ObjectIDFromHex(id); err == nil {
+			oids = append(oids, oid)
+		}
+	}
+	if len(oids) == 0 {
+		return nil, nil
+	}
+
+	cur, err := r.collection.Find(ctx, bson.M{"_id": bson.M{"$in": oids}, "deleted_at": notDeleted})
+	if err != nil {
+		return nil, err
+	}
+	var posts []*model.Post
+	if err := cur.All(ctx, &posts); err != nil {
+		return nil, err
+	}
+	return posts, nil
+}
+
+func (r *mongoPostRepository) Update(ctx context.Context, id, authorID, content string, mediaURLs []string, editedAt time.Time) (*model.Post, error) {
+	oid, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, ErrNotFound
+	}
+	if mediaURLs == nil {
+		mediaURLs = []string{}
+	}
+
+	// An update pipeline reads the current version, so it's archived atomically with the edit
+	revision := bson.M{
+		"content":    "$content",
+		"media_urls": "$media_urls",
+		"written_at": bson.M{"$ifNull": bson.A{"$edited_at", "$created_at"}},
+	}
+	update := bson.A{bson.M{"$set": bson.M{
+		"revisions": bson.M{"$slice": bson.A{
+			bson.M{"$concatArrays": bson.A{bson.M{"$ifNull": bson.A{"$revisions", bson.A{}}}, bson.A{revision}}},
+			-maxRevisions,
+		}},
+		"content":    bson.M{"$literal": content},
+		"media_urls": bson.M{"$literal": mediaURLs},
+		"edited_at":  editedAt,
+	}}}
+
+	var post model.Post
+	filter := bson.M{"_id": oid, "author_id": authorID, "deleted_at": notDeleted}
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+	err = r.collection.FindOneAndUpdate(ctx, filter, update, opts).Decode(&post)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &post, nil
+}
+
+func (r *mongoPostRepository) SoftDelete(ctx context.Context, id, authorID string, deletedAt time.Time) error {
+	oid, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return ErrNotFound
+	}
+	filter := bson.M{"_id": oid, "author_id": authorID, "deleted_at": notDeleted}
+	update := bson.M{
+		"$set":   bson.M{"deleted_at": deletedAt, "content": "", "media_urls": bson.A{}},
+		"$unset": bson.M{"revisions": ""},
+	}
+	res, err := r.collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return err
+	}
+	if res.MatchedCount == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (r *mongoPostRepository) IncrementLikes(ctx context.Context, id string, delta int32) (*model.Post, error) {
+	return r.increment(ctx, id, "likes_count", delta)
+}
+
+func (r *mongoPostRepository) IncrementComments(ctx context.Context, id string, delta int32) (*model.Post, error) {
+	return r.increment(ctx, id, "comments_count", delta)
+}
+
+// increment adds delta to a counter of the post, clamped at zero
+func (r *mongoPostRepository) increment(ctx context.Context, id, field string, delta int32) (*model.Post, error) {
+	oid, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, ErrNotFound
+	}
+
+	var post model.Post
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+	// Tombstones are counted too, erasing the likes of a deleted post still decrements it
+	err = r.collection.FindOneAndUpdate(ctx, bson.M{"_id": oid}, counterUpdate(field, delta), opts).Decode(&post)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &post, nil
+}
+
 func (r *mongoPostRepository) List(ctx context.Context, authorID string, limit int64, cursor string) ([]*model.Post, string, error) {
-	filter := bson.M{}
+	filter := bson.M{"deleted_at": notDeleted}
 	if authorID != "" {
 		filter["author_id"] = authorID
 	}
@@ -81,6 +244,49 @@ func (r *mongoPostRepository) List(ctx context.Context, authorID string, limit i
 	return posts, nextCursor, nil
 }
 
+func (r *mongoPostRepository) ListByAuthors(ctx context.Context, authorIDs []string, before string, limit int64) ([]*model.Post, error) {
+	if len(authorIDs) == 0 {
+		return nil, nil
+	}
+	filter := bson.M{"author_id": bson.M{"$in": authorIDs}, "deleted_at": notDeleted}
+	if before != "" {
+		oid, err := primitive.ObjectIDFromHex(before)
+		if err != nil {
+			return nil, ErrInvalidCursor
+		}
+		filter["_id"] = bson.M{"$lt": oid}
+	}
+
+	opts := options.Find().SetLimit(limit).SetSort(bson.M{"_id": -1})
+	cur, err := r.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	var posts []*model.Post
+	if err := cur.All(ctx, &posts); err != nil {
+		return nil, err
+	}
+	return posts, nil
+}
+
+// counterUpdate is an atomic $inc, decrements use an update pipeline so the counter stops at zero
+func counterUpdate(field string, delta int32) any {
+	if delta >= 0 {
+		return bson.M{"$inc": bson.M{field: delta}}
+	}
+	return bson.A{bson.M{"$set": bson.M{
+		field: bson.M{"$max": bson.A{0, bson.M{"$add": bson.A{"$" + field, delta}}}},
+	}}}
+}
+
+func (r *mongoPostRepository) DeleteByAuthor(ctx context.Context, authorID string) (int64, error) {
+	res, err := r.collection.DeleteMany(ctx, bson.M{"author_id": authorID})
+	if err != nil {
+		return 0, err
+	}
+	return res.DeletedCount, nil
+}
+
 type mongoUserRepository struct {
 	collection *mongo.Collection
 }
@@ -94,19 +300,51 @@ func (r *mongoUserRepository) Save(ctx context.Context, user *model.User) error
 
 	// Persist as string _id for compatibility with other services (NoSQL) and legacy data.
 	type UserWrapper struct {
-		ID       string `bson:"_id"`
-		Username string `bson:"username"`
-		Email    string `bson:"email"`
-		Role     string `bson:"role"`
+		ID          string   `bson:"_id"`
+		Username    string   `bson:"username"`
+		Email       string   `bson:"email"`
+		Role        string   `bson:"role"`
+		DisplayName string   `bson:"display_name"`
+		Bio         string   `bson:"bio"`
+		AvatarURL   string   `bson:"avatar_url"`
+		BookGenres  []string `bson:"book_genres"`
+		FilmGenres  []string `bson:"film_genres"`
+		MusicGenres []string `bson:"music_genres"`
+		Version     int64    `bson:"version"`
 	}
 
 	wrapper := UserWrapper{
-		ID:       strconv.Itoa(int(user.ID)),
-		Username: user.Username,
-		Email:    user.Email,
-		Role:     user.Role,
+		ID:          strconv.Itoa(int(user.ID)),
+		Username:    user.Username,
+		Email:       user.Email,
+		Role:        user.Role,
+		DisplayName: user.DisplayName,
+		Bio:         user.Bio,
+		AvatarURL:   user.AvatarURL,
+		BookGenres:  user.BookGenres,
+		FilmGenres:  user.FilmGenres,
+		MusicGenres: user.MusicGenres,
+		Version:     user.Version,
 	}
 
-	_, err := r.collection.ReplaceOne(ctx, bson.M{"_id": wrapper.ID}, wrapper, opts)
+	// Only replace older versions, documents saved before versioning have none
+	filter := bson.M{
+		"_id": wrapper.ID,
+		"$or": bson.A{
+			bson.M{"version": bson.M{"$lt": wrapper.Version}},
+			bson.M{"version": bson.M{"$exists": false}},
+		},
+	}
+
+	_, err := r.collection.ReplaceOne(ctx, filter, wrapper, opts)
+	if mongo.IsDuplicateKeyError(err) {
+		// The filter missed because a newer version is stored, the upsert then hit the same _id
+		return nil
+	}
+	return err
+}
+
+func (r *mongoUserRepository) Delete(ctx context.Context, userID string) error {
+	_, err := r.collection.DeleteOne(ctx, bson.M{"_id": userID})
 	return err
 }
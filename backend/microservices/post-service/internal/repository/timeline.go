@@ -0,0 +1,92 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/gocql/gocql"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"golang.org/x/sync/errgroup"
+)
+
+const (
+	// timelineBucket is the time span of a timeline partition
+	timelineBucket = 7 * 24 * time.Hour
+	// timelineBuckets is how many partitions a read walks back, covering the table TTL of 4 weeks
+	timelineBuckets = 5
+	// fanoutConcurrency bounds the inserts in flight while fanning a post out
+	fanoutConcurrency = 32
+)
+
+// TimelineRepository stores the home timelines, the IDs of the posts each user sees.
+// A timeline is split in weekly partitions, (user_id, bucket), ordered by post ID:
+// the IDs are Mongo ObjectIDs, so the order is the creation order.
+type TimelineRepository interface {
+	// Add inserts the post in the timeline of each user, inserting it again is a no-op
+	Add(ctx context.Context, userIDs []string, postID, authorID string) error
+	// Page returns up to limit post IDs of the timeline older than the before post ID, newest first.
+	// An empty before starts from the newest post, an invalid one is ErrInvalidCursor.
+	Page(ctx context.Context, userID, before string, limit int) ([]string, error)
+}
+
+type cassandraTimelineRepository struct {
+	session *gocql.Session
+}
+
+func NewCassandraTimelineRepository(session *gocql.Session) TimelineRepository {
+	return &cassandraTimelineRepository{session: session}
+}
+
+func (r *cassandraTimelineRepository) Add(ctx context.Context, userIDs []string, postID, authorID string) error {
+	oid, err := primitive.ObjectIDFromHex(postID)
+	if err != nil {
+		return err
+	}
+	bucket := bucketOf(oid.Timestamp())
+
+	// Each user is another partition, a batch would only load the coordinator
+	g, ctx := errgroup.WithContext(ctx)
+	g.SetLimit(fanoutConcurrency)
+	for _, userID := range userIDs {
+		g.Go(func() error {
+			return r.session.Query(`INSERT INTO home_timeline (user_id, bucket, post_id, author_id) VALUES (?, ?, ?, ?)`,
+				userID, bucket, postID, authorID).WithContext(ctx).Exec()
+		})
+	}
+	return g.Wait()
+}
+
+func (r *cassandraTimelineRepository) Page(ctx context.Context, userID, before string, limit int) ([]string, error) {
+	bucket := bucketOf(time.Now())
+	if before != "" {
+		oid, err := primitive.ObjectIDFromHex(before)
+		if err != nil {
+			return nil, ErrInvalidCursor
+		}
+		bucket = bucketOf(oid.Timestamp())
+	}
+
+	ids := make([]string, 0, limit)
+	for b := bucket; b > bucket-timelineBuckets && len(ids) < limit; b-- {
+		query := r.session.Query(`SELECT post_id FROM home_timeline WHERE user_id = ? AND bucket = ? LIMIT ?`,
+			userID, b, limit-len(ids))
+		if before != "" {
+			query = r.session.Query(`SELECT post_id FROM home_timeline WHERE user_id = ? AND bucket = ? AND post_id < ? LIMIT ?`,
+				userID, b, before, limit-len(ids))
+		}
+
+		iter := query.WithContext(ctx).Iter()
+		var postID string
+		for iter.Scan(&postID) {
+			ids = append(ids, postID)
+		}
+		if err := iter.Close(); err != nil {
+			return nil, err
+		}
+	}
+	return ids, nil
+}
+
+func bucketOf(t time.Time) int {
+	return int(t.Unix() / int64(timelineBucket/time.Second))
+}
@@ -7,13 +7,17 @@ import (
 	"os"
 	"os/signal"
 	"syscall"
+	"time"
 
 	"github.com/username/progetto/post-service/internal/config"
 	"github.com/username/progetto/post-service/internal/events"
 	"github.com/username/progetto/post-service/internal/handler"
 	"github.com/username/progetto/post-service/internal/repository"
 	postv1 "github.com/username/progetto/proto/gen/go/post/v1"
+	socialv1 "github.com/username/progetto/proto/gen/go/social/v1"
+	"github.com/username/progetto/shared/pkg/database/cassandra"
 	"github.com/username/progetto/shared/pkg/database/mongo"
+	"github.com/username/progetto/shared/pkg/database/redis"
 	"github.com/username/progetto/shared/pkg/grpcutil"
 	"github.com/username/progetto/shared/pkg/observability"
 	"github.com/username/progetto/shared/pkg/watermillutil"
@@ -53,11 +57,52 @@ func main() {
 		}
 	}()
 
-	// 2. Repositories
-	postRepo := repository.NewMongoPostRepository(db)
+	// 2. Redis (Post Cache)
+	rdb, err := redis.NewRedis(cfg.RedisAddr, logger)
+	if err != nil {
+		slog.Error("failed to connect to redis", "error", err)
+		os.Exit(1)
+	}
+	defer rdb.Close()
+
+	// 3. Cassandra (Home Timelines)
+	session, err := cassandra.NewCassandra(cassandra.Config{
+		Host:           cfg.CassandraHost,
+		Consistency:    cfg.CassandraConsistency,
+		ConnectTimeout: 10 * time.Second,
+		Keyspace:       cfg.CassandraKeyspace,
+	}, logger)
+	if err != nil {
+		slog.Error("failed to connect to cassandra", "error", err)
+		os.Exit(1)
+	}
+	defer session.Close()
+
+	// 4. Social Service Client (Follow Graph)
+	identity, err := grpcutil.NewServiceIdentity(cfg.ServiceName, cfg.ServiceKey)
+	if err != nil {
+		slog.Error("failed to load service identity", "error", err)
+		os.Exit(1)
+	}
+	socialConn, err := grpcutil.NewClient(cfg.SocialService, "social-service", grpcutil.WithServiceIdentity(identity)...)
+	if err != nil {
+		slog.Error("failed to connect to social service", "error", err)
+		os.Exit(1)
+	}
+	defer socialConn.Close()
+
+	// 5. Repositories
+	postRepo := repository.NewCachedPostRepository(repository.NewMongoPostRepository(db), rdb)
+	likeRepo := repository.NewMongoLikeRepository(db)
+	commentRepo := repository.NewMongoCommentRepository(db)
 	userRepo := repository.NewMongoUserRepository(db)
+	timelineRepo := repository.NewCassandraTimelineRepository(session)
+	if err := repository.EnsureIndexes(context.Background(), db); err != nil {
+		slog.Error("failed to create mongodb indexes", "error", err)
+		os.Exit(1)
+	}
 
-	// 3. Kafka Publisher (Shared)
+	// 6. Kafka Publisher (Shared)
 	publisher, err := watermillutil.NewKafkaPublisher(cfg.KafkaBrokers, logger)
 	if err != nil {
 		slog.Error("failed to create kafka publisher", "error", err)
@@ -65,26 +110,32 @@ func main() {
 	}
 	defer publisher.Close()
 
-	// 4. Wiring
-	userHandler := handler.NewUserHandler(userRepo, publisher)
-	postHandler := handler.NewPostHandler(postRepo, publisher)
+	// 7. Wiring
+	userHandler := handler.NewUserHandler(userRepo, postRepo, likeRepo, commentRepo, publisher)
+	timelineHandler := handler.NewTimelineHandler(postRepo, timelineRepo, socialv1.NewSocialServiceClient(socialConn), cfg.CelebrityFollowers)
+	postHandler := handler.NewPostHandler(postRepo, likeRepo, commentRepo, timelineHandler, publisher)
 
-	// 5. Watermill Event Router (User Sync)
-	eventRouter, err := events.NewEventRouter(logger, cfg.KafkaBrokers, publisher, userHandler)
+	// 8. Watermill Event Router (User Sync, Timeline Fan-out)
+	eventRouter, err := events.NewEventRouter(logger, cfg.KafkaBrokers, publisher, userHandler, timelineHandler)
 	if err != nil {
 		slog.Error("failed to create event router", "error", err)
 		os.Exit(1)
 	}
 	defer eventRouter.Close()
 
-	// 6. gRPC Server
+	// 9. gRPC Server
 	lis, err := net.Listen("tcp", ":50051")
 	if err != nil {
 		slog.Error("failed to listen", "error", err)
 		os.Exit(1)
 	}
 
-	srv := grpcutil.NewServer()
+	trustedServices, err := grpcutil.ParseTrustedServices(cfg.TrustedServices)
+	if err != nil {
+		slog.Error("failed to parse trusted services", "error", err)
+		os.Exit(1)
+	}
+	srv := grpcutil.NewServer(grpcutil.WithServiceAuth(trustedServices, handler.AllowList)...)
 	postv1.RegisterPostServiceServer(srv, postHandler)
 	reflection.Register(srv)
 
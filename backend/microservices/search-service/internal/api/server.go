@@ -6,6 +6,8 @@ import (
 
 	searchv1 "github.com/username/progetto/proto/gen/go/search/v1"
 	"github.com/username/progetto/search-service/internal/search"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
 type Server struct {
@@ -25,7 +27,10 @@ func (s *Server) SearchUsers(ctx context.Context, req *searchv1.SearchUsersReque
 	resp, err := s.Meili.SearchUsers(ctx, req.GetQuery(), int64(req.GetLimit()), int64(req.GetOffset()))
 	if err != nil {
 		slog.ErrorContext(ctx, "failed to search users", "error", err)
-		return nil, err
+		if ctx.Err() != nil {
+			return nil, status.FromContextError(ctx.Err()).Err()
+		}
+		return nil, status.Error(codes.Unavailable, "search is temporarily unavailable")
 	}
 
 	var users []*searchv1.UserResult
@@ -18,6 +18,7 @@ import (
 	"github.com/username/progetto/search-service/internal/events"
 	"github.com/username/progetto/search-service/internal/handler"
 	"github.com/username/progetto/search-service/internal/search"
+	"github.com/username/progetto/shared/pkg/grpcutil"
 	"github.com/username/progetto/shared/pkg/observability"
 	"github.com/username/progetto/shared/pkg/watermillutil"
 )
@@ -86,7 +87,18 @@ func New(cfg *config.Config) (*App, error) {
 	}
 
 	// gRPC Server
-	grpcServer := grpc.NewServer(observability.GRPCServerOptions()...)
+	trustedServices, err := grpcutil.ParseTrustedServices(cfg.TrustedServices)
+	if err != nil {
+		publisher.Close()
+		subscriber.Close()
+		return nil, fmt.Errorf("failed to parse trusted services: %w", err)
+	}
+	grpcOpts := append(observability.GRPCServerOptions(),
+		grpcutil.WithServiceAuth(trustedServices, grpcutil.MethodAllowList{
+			searchv1.SearchService_SearchUsers_FullMethodName: {"gateway-service"},
+		})...,
+	)
+	grpcServer := grpc.NewServer(grpcOpts...)
 	searchv1.RegisterSearchServiceServer(grpcServer, api.NewServer(meiliClient))
 	reflection.Register(grpcServer)
 
@@ -5,15 +5,17 @@ import (
 )
 
 type Config struct {
-	MeiliHost    string
-	MeiliKey     string
-	KafkaBrokers string
+	MeiliHost       string
+	MeiliKey        string
+	KafkaBrokers    string
+	TrustedServices string
 }
 
 func Load() *Config {
 	return &Config{
-		MeiliHost:    config.GetEnv("APP_MEILI_HOST", "http://meilisearch:7700"),
-		MeiliKey:     config.MustGetEnv("MEILI_MASTER_KEY"),
-		KafkaBrokers: config.GetEnv("APP_KAFKA_BROKERS", "kafka:29092"),
+		MeiliHost:       config.GetEnv("APP_MEILI_HOST", "http://meilisearch:7700"),
+		MeiliKey:        config.MustGetEnv("MEILI_MASTER_KEY"),
+		KafkaBrokers:    config.GetEnv("APP_KAFKA_BROKERS", "kafka:29092"),
+		TrustedServices: config.MustGetEnv("APP_TRUSTED_SERVICES"),
 	}
 }
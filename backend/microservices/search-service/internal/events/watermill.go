@@ -36,6 +36,20 @@ func NewRouter(logger *slog.Logger, pub message.Publisher, sub message.Subscribe
 		h.HandleUserCreated,
 	)
 
+	router.AddConsumerHandler(
+		"search_user_updated",
+		"user_updated",
+		sub,
+		h.HandleUserUpdated,
+	)
+
+	router.AddConsumerHandler(
+		"search_user_deletion_requested",
+		"user_deletion_requested",
+		sub,
+		h.HandleUserDeletionRequested,
+	)
+
 	return &WatermillManager{
 		Publisher:  pub,
 		Subscriber: sub,
@@ -7,6 +7,7 @@ import (
 	"github.com/ThreeDotsLabs/watermill"
 	"github.com/ThreeDotsLabs/watermill/message"
 	"github.com/username/progetto/search-service/internal/search"
+	"github.com/username/progetto/shared/pkg/model"
 	api_errors "github.com/username/progetto/shared/pkg/resiliency"
 )
 
@@ -29,6 +30,7 @@ func (h *NotificationHandler) HandleUserCreated(msg *message.Message) error {
 		UserID   string `json:"user_id"`
 		Username string `json:"username"`
 		Email    string `json:"email"`
+		Version  int64  `json:"version"`
 	}
 	if err := json.Unmarshal(msg.Payload, &payload); err != nil {
 		h.Logger.Error("failed to unmarshal payload", "error", err)
@@ -41,6 +43,7 @@ func (h *NotificationHandler) HandleUserCreated(msg *message.Message) error {
 		ID:       payload.UserID,
 		Username: payload.Username,
 		Email:    payload.Email,
+		Version:  payload.Version,
 	}
 
 	if err := h.Meili.IndexUser(msg.Context(), user); err != nil {
@@ -69,3 +72,63 @@ func (h *NotificationHandler) HandleUserCreated(msg *message.Message) error {
 	h.Logger.Info("user indexed and sync event published", "user_id", payload.UserID)
 	return nil
 }
+
+// HandleUserUpdated reindexes the user, older versions are ignored
+func (h *NotificationHandler) HandleUserUpdated(msg *message.Message) error {
+	var payload model.UserUpdatedEvent
+	if err := json.Unmarshal(msg.Payload, &payload); err != nil {
+		h.Logger.Error("failed to unmarshal payload", "error", err)
+		return api_errors.NewPermanentError(err)
+	}
+
+	h.Logger.Info("reindexing user", "user_id", payload.UserID, "version", payload.Version)
+
+	user := search.User{
+		ID:          payload.UserID,
+		Username:    payload.Username,
+		Email:       payload.Email,
+		DisplayName: payload.DisplayName,
+		AvatarURL:   payload.AvatarURL,
+		Version:     payload.Version,
+	}
+
+	if err := h.Meili.IndexUser(msg.Context(), user); err != nil {
+		h.Logger.Error("failed to index user", "error", err)
+		return err // retry
+	}
+	return nil
+}
+
+// HandleUserDeletionRequested removes the user from the index, then confirms with user_deleted_search
+func (h *NotificationHandler) HandleUserDeletionRequested(msg *message.Message) error {
+	var payload struct {
+		DeletionID string `json:"deletion_id"`
+		UserID     string `json:"user_id"`
+	}
+	if err := json.Unmarshal(msg.Payload, &payload); err != nil {
+		h.Logger.Error("failed to unmarshal payload", "error", err)
+		return api_errors.NewPermanentError(err)
+	}
+	if payload.UserID == "" {
+		h.Logger.Error("deletion request without user_id", "deletion_id", payload.DeletionID)
+		return nil
+	}
+
+	h.Logger.Info("deleting user from index", "user_id", payload.UserID, "deletion_id", payload.DeletionID)
+
+	if err := h.Meili.DeleteUser(msg.Context(), payload.UserID); err != nil {
+		h.Logger.Error("failed to delete user", "error", err)
+		return err // retry
+	}
+
+	b, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	outMsg := message.NewMessage(watermill.NewUUID(), b)
+	outMsg.Metadata.Set("user_id", payload.UserID)
+	outMsg.SetContext(msg.Context())
+
+	return h.Publisher.Publish("user_deleted_search", outMsg)
+}
@@ -3,7 +3,9 @@ package search
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"net/http"
 	"time"
 
 	meilisearch "github.com/meilisearch/meilisearch-go"
@@ -16,9 +18,12 @@ type SearchResponse struct {
 }
 
 type User struct {
-	ID       string `json:"id"`
-	Username string `json:"username"`
-	Email    string `json:"email"`
+	ID          string `json:"id"`
+	Username    string `json:"username"`
+	Email       string `json:"email"`
+	DisplayName string `json:"display_name,omitempty"`
+	AvatarURL   string `json:"avatar_url,omitempty"`
+	Version     int64  `json:"version"`
 }
 
 type MeiliClient struct {
@@ -56,7 +61,19 @@ func (m *MeiliClient) EnsureIndex(ctx context.Context) error {
 	return nil
 }
 
+// IndexUser adds or replaces the user document, unless the index already holds a newer version.
+// The check is not atomic, it protects against redelivered and late events rather than concurrent ones.
 func (m *MeiliClient) IndexUser(ctx context.Context, user User) error {
+	var existing User
+	err := m.index.GetDocumentWithContext(ctx, user.ID, nil, &existing)
+	if err == nil && existing.Version >= user.Version {
+		return nil
+	}
+	var meiliErr *meilisearch.Error
+	if err != nil && !(errors.As(err, &meiliErr) && meiliErr.StatusCode == http.StatusNotFound) {
+		return fmt.Errorf("failed to get document: %w", err)
+	}
+
 	// AddDocumentsWithContext
 	task, err := m.index.AddDocumentsWithContext(ctx, []User{user}, nil)
 	if err != nil {
@@ -71,6 +88,20 @@ func (m *MeiliClient) IndexUser(ctx context.Context, user User) error {
 	return nil
 }
 
+// DeleteUser removes the user document, deleting a missing document succeeds
+func (m *MeiliClient) DeleteUser(ctx context.Context, userID string) error {
+	task, err := m.index.DeleteDocumentWithContext(ctx, userID, nil)
+	if err != nil {
+		return fmt.Errorf("failed to delete document: %w", err)
+	}
+
+	_, err = m.client.WaitForTaskWithContext(ctx, task.TaskUID, 50*time.Millisecond)
+	if err != nil {
+		return fmt.Errorf("failed to wait for task: %w", err)
+	}
+	return nil
+}
+
 func (m *MeiliClient) SearchUsers(ctx context.Context, query string, limit, offset int64) (*SearchResponse, error) {
 	// SearchWithContext
 	searchRes, err := m.index.SearchWithContext(ctx, query, &meilisearch.SearchRequest{
@@ -9,7 +9,9 @@ replace github.com/username/progetto/proto => ../../shared/proto
 require (
 	github.com/ThreeDotsLabs/watermill v1.5.1
 	github.com/neo4j/neo4j-go-driver/v5 v5.28.4
+	github.com/username/progetto/proto v0.0.0-00010101000000-000000000000
 	github.com/username/progetto/shared/pkg v0.0.0-00010101000000-000000000000
+	google.golang.org/grpc v1.77.0
 )
 
 require (
@@ -28,6 +30,7 @@ require (
 	github.com/go-chi/chi/v5 v5.2.2 // indirect
 	github.com/go-logr/logr v1.4.3 // indirect
 	github.com/go-logr/stdr v1.2.2 // indirect
+	github.com/golang-jwt/jwt/v5 v5.3.0 // indirect
 	github.com/golang/snappy v1.0.0 // indirect
 	github.com/google/uuid v1.6.0 // indirect
 	github.com/grafana/pyroscope-go v1.2.7 // indirect
@@ -76,7 +79,6 @@ require (
 	golang.org/x/text v0.31.0 // indirect
 	google.golang.org/genproto/googleapis/api v0.0.0-20251202230838-ff82c1b0f217 // indirect
 	google.golang.org/genproto/googleapis/rpc v0.0.0-20251202230838-ff82c1b0f217 // indirect
-	google.golang.org/grpc v1.77.0 // indirect
-	google.golang.org/protobuf v1.36.10 // indirect
+	google.golang.org/protobuf v1.36.11 // indirect
 	gorm.io/gorm v1.31.1 // indirect
 )
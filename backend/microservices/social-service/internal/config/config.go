@@ -10,6 +10,7 @@ type Config struct {
 	Neo4jUser            string
 	Neo4jPassword        string
 	KafkaBrokers         string
+	TrustedServices      string
 	OtelExporterEndpoint string
 	OtelServiceName      string
 }
@@ -20,6 +21,7 @@ func Load() *Config {
 		Neo4jUser:            mustGetEnv("NEO4J_USER"),
 		Neo4jPassword:        mustGetEnv("NEO4J_PASSWORD"),
 		KafkaBrokers:         mustGetEnv("APP_KAFKA_BROKERS"),
+		TrustedServices:      mustGetEnv("APP_TRUSTED_SERVICES"),
 		OtelExporterEndpoint: getEnv("OTEL_EXPORTER_OTLP_ENDPOINT", "alloy:4317"),
 		OtelServiceName:      getEnv("OTEL_SERVICE_NAME", "social-service"),
 	}
@@ -45,6 +45,20 @@ func NewEventRouter(logger *slog.Logger, brokers string, publisher message.Publi
 		userHandler.HandleCreated,
 	)
 
+	router.AddConsumerHandler(
+		"social_user_updated_handler",
+		"user_updated",
+		subscriber,
+		userHandler.HandleUpdated,
+	)
+
+	router.AddConsumerHandler(
+		"social_user_deletion_requested_handler",
+		"user_deletion_requested",
+		subscriber,
+		userHandler.HandleDeletionRequested,
+	)
+
 	return &EventRouter{
 		Router:     router,
 		Subscriber: subscriber,
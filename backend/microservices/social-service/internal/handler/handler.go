@@ -31,6 +31,7 @@ func (h *UserHandler) HandleCreated(msg *message.Message) error {
 		UserID   string `json:"user_id"`
 		Email    string `json:"email"`
 		Username string `json:"username"`
+		Version  int64  `json:"version"`
 	}
 
 	if err := json.Unmarshal(msg.Payload, &payload); err != nil {
@@ -53,6 +54,7 @@ func (h *UserHandler) HandleCreated(msg *message.Message) error {
 		ID:       uint(userIDUint),
 		Username: payload.Username,
 		Email:    payload.Email,
+		Version:  payload.Version,
 	}
 
 	if err := h.Repo.CreatePerson(msg.Context(), user); err != nil {
@@ -80,6 +82,61 @@ func (h *UserHandler) HandleCreated(msg *message.Message) error {
 	return nil
 }
 
+// HandleUpdated refreshes the Person node of the user, older versions are ignored
+func (h *UserHandler) HandleUpdated(msg *message.Message) error {
+	var payload model.UserUpdatedEvent
+	if err := json.Unmarshal(msg.Payload, &payload); err != nil {
+		h.Logger.ErrorContext(msg.Context(), "failed to unmarshal message", "error", err)
+		return nil // Don't retry malformed messages
+	}
+
+	user, err := payload.User()
+	if err != nil {
+		h.Logger.ErrorContext(msg.Context(), "failed to parse user_id", "error", err)
+		return resiliency.NewPermanentError(err)
+	}
+
+	h.Logger.InfoContext(msg.Context(), "received user_updated event", "user_id", payload.UserID, "version", payload.Version)
+
+	if err := h.Repo.CreatePerson(msg.Context(), user); err != nil {
+		h.Logger.ErrorContext(msg.Context(), "failed to update person in neo4j", "error", err)
+		return err // Retry
+	}
+	return nil
+}
+
+// HandleDeletionRequested removes the Person node, then confirms with user_deleted_social
+func (h *UserHandler) HandleDeletionRequested(msg *message.Message) error {
+	var payload struct {
+		DeletionID string `json:"deletion_id"`
+		UserID     string `json:"user_id"`
+	}
+	if err := json.Unmarshal(msg.Payload, &payload); err != nil || payload.UserID == "" {
+		h.Logger.ErrorContext(msg.Context(), "failed to unmarshal message", "error", err)
+		return nil // Don't retry malformed messages
+	}
+
+	h.Logger.InfoContext(msg.Context(), "received user_deletion_requested event", "user_id", payload.UserID, "deletion_id", payload.DeletionID)
+
+	if err := h.Repo.DeletePerson(msg.Context(), payload.UserID); err != nil {
+		h.Logger.ErrorContext(msg.Context(), "failed to delete person in neo4j", "error", err)
+		return err // Retry
+	}
+
+	confirmBytes, _ := json.Marshal(payload)
+	confirmMsg := message.NewMessage(watermill.NewUUID(), confirmBytes)
+	confirmMsg.Metadata.Set("user_id", payload.UserID)
+	confirmMsg.SetContext(msg.Context())
+
+	if err := h.Publisher.Publish("user_deleted_social", confirmMsg); err != nil {
+		h.Logger.ErrorContext(msg.Context(), "failed to publish user_deleted_social event", "error", err)
+		return err // Retry
+	}
+
+	h.Logger.InfoContext(msg.Context(), "person deleted", "user_id", payload.UserID)
+	return nil
+}
+
 // HandleFailure constructs a compensation message for user creation failure.
 func (h *UserHandler) HandleFailure(err error, msg *message.Message) (string, *message.Message, error) {
 	userID := msg.Metadata.Get("user_id")
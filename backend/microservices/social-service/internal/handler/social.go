@@ -0,0 +1,90 @@
+package handler
+
+import (
+	"context"
+	"log/slog"
+
+	socialv1 "github.com/username/progetto/proto/gen/go/social/v1"
+	"github.com/username/progetto/shared/pkg/grpcutil"
+	"github.com/username/progetto/social-service/internal/repository"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	defaultPageSize = 100
+	maxPageSize     = 1000
+)
+
+// AllowList is the services allowed to call each method of SocialService
+var AllowList = grpcutil.AllowService(&socialv1.SocialService_ServiceDesc, "post-service")
+
+// SocialHandler serves the follow graph to the other services
+type SocialHandler struct {
+	socialv1.UnimplementedSocialServiceServer
+	repo   *repository.Neo4jRepository
+	logger *slog.Logger
+}
+
+func NewSocialHandler(repo *repository.Neo4jRepository) *SocialHandler {
+	return &SocialHandler{
+		repo:   repo,
+		logger: slog.Default().With("component", "social_handler"),
+	}
+}
+
+func (h *SocialHandler) ListFollowers(ctx context.Context, req *socialv1.ListFollowersRequest) (*socialv1.ListFollowersResponse, error) {
+	if req.UserId == "" {
+		return nil, status.Error(codes.InvalidArgument, "user_id is required")
+	}
+	limit := pageSize(req.Limit)
+	ids, err := h.repo.ListFollowers(ctx, req.UserId, req.NextPageToken, limit)
+	if err != nil {
+		h.logger.ErrorContext(ctx, "failed to list followers", "error", err, "user_id", req.UserId)
+		return nil, status.Error(codes.Unavailable, "follow graph is temporarily unavailable")
+	}
+	return &socialv1.ListFollowersResponse{FollowerIds: ids, NextPageToken: nextToken(ids, limit)}, nil
+}
+
+func (h *SocialHandler) CountFollowers(ctx context.Context, req *socialv1.CountFollowersRequest) (*socialv1.CountFollowersResponse, error) {
+	if req.UserId == "" {
+		return nil, status.Error(codes.InvalidArgument, "user_id is required")
+	}
+	count, err := h.repo.CountFollowers(ctx, req.UserId)
+	if err != nil {
+		h.logger.ErrorContext(ctx, "failed to count followers", "error", err, "user_id", req.UserId)
+		return nil, status.Error(codes.Unavailable, "follow graph is temporarily unavailable")
+	}
+	return &socialv1.CountFollowersResponse{Count: count}, nil
+}
+
+func (h *SocialHandler) ListFollowing(ctx context.Context, req *socialv1.ListFollowingRequest) (*socialv1.ListFollowingResponse, error) {
+	if req.UserId == "" {
+		return nil, status.Error(codes.InvalidArgument, "user_id is required")
+	}
+	if req.MinFollowers < 0 {
+		return nil, status.Error(codes.InvalidArgument, "min_followers can't be negative")
+	}
+	limit := pageSize(req.Limit)
+	ids, err := h.repo.ListFollowing(ctx, req.UserId, req.MinFollowers, req.NextPageToken, limit)
+	if err != nil {
+		h.logger.ErrorContext(ctx, "failed to list following", "error", err, "user_id", req.UserId)
+		return nil, status.Error(codes.Unavailable, "follow graph is temporarily unavailable")
+	}
+	return &socialv1.ListFollowingResponse{UserIds: ids, NextPageToken: nextToken(ids, limit)}, nil
+}
+
+func pageSize(limit int32) int {
+	if limit <= 0 {
+		return defaultPageSize
+	}
+	return min(int(limit), maxPageSize)
+}
+
+// nextToken is the last ID of a full page, a shorter page is the last one
+func nextToken(ids []string, limit int) string {
+	if len(ids) < limit {
+		return ""
+	}
+	return ids[len(ids)-1]
+}
@@ -17,22 +17,35 @@ func NewNeo4jRepository(driver neo4j.DriverWithContext) *Neo4jRepository {
 	return &Neo4jRepository{driver: driver}
 }
 
-// CreatePerson creates a new Person node in Neo4j.
+// CreatePerson creates a new Person node in Neo4j, or updates it if the user version is newer.
 func (r *Neo4jRepository) CreatePerson(ctx context.Context, user model.User) error {
 	session := r.driver.NewSession(ctx, neo4j.SessionConfig{AccessMode: neo4j.AccessModeWrite})
 	defer session.Close(ctx)
 
 	_, err := session.ExecuteWrite(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
+		// Events can arrive out of order: nodes only move forward in version
 		query := `
 			MERGE (p:Person {id: $userID})
-			ON CREATE SET p.username = $username, p.email = $email, p.created_at = datetime()
-			ON MATCH SET p.username = $username, p.email = $email
+			ON CREATE SET p.created_at = datetime()
+			WITH p
+			WHERE p.version IS NULL OR p.version < $version
+			SET p.username = $username, p.email = $email,
+				p.display_name = $displayName, p.bio = $bio, p.avatar_url = $avatarURL,
+				p.book_genres = $bookGenres, p.film_genres = $filmGenres, p.music_genres = $musicGenres,
+				p.version = $version
 			RETURN p
 		`
 		params := map[string]any{
-			"userID":   strconv.Itoa(int(user.ID)),
-			"username": user.Username,
-			"email":    user.Email,
+			"userID":      strconv.Itoa(int(user.ID)),
+			"username":    user.Username,
+			"email":       user.Email,
+			"displayName": user.DisplayName,
+			"bio":         user.Bio,
+			"avatarURL":   user.AvatarURL,
+			"bookGenres":  user.BookGenres,
+			"filmGenres":  user.FilmGenres,
+			"musicGenres": user.MusicGenres,
+			"version":     user.Version,
 		}
 
 		result, err := tx.Run(ctx, query, params)
@@ -49,3 +62,104 @@ func (r *Neo4jRepository) CreatePerson(ctx context.Context, user model.User) err
 
 	return nil
 }
+
+// DeletePerson removes the Person node of the user with all its relationships.
+// Deleting a missing node is not an error.
+func (r *Neo4jRepository) DeletePerson(ctx context.Context, userID string) error {
+	session := r.driver.NewSession(ctx, neo4j.SessionConfig{AccessMode: neo4j.AccessModeWrite})
+	defer session.Close(ctx)
+
+	_, err := session.ExecuteWrite(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
+		result, err := tx.Run(ctx, `MATCH (p:Person {id: $userID}) DETACH DELETE p`, map[string]any{"userID": userID})
+		if err != nil {
+			return nil, err
+		}
+		return result.Consume(ctx)
+	})
+
+	if err != nil {
+		return fmt.Errorf("failed to delete person transaction: %w", err)
+	}
+
+	return nil
+}
+
+// ListFollowers returns up to limit IDs of the users following the user, ordered by ID
+// and starting after the given ID, empty for the first page.
+func (r *Neo4jRepository) ListFollowers(ctx context.Context, userID, after string, limit int) ([]string, error) {
+	return r.readIDs(ctx, `
+		MATCH (f:Person)-[:FOLLOWS]->(:Person {id: $userID})
+		WHERE f.id > $after
+		RETURN f.id AS id
+		ORDER BY id
+		LIMIT $limit
+	`, map[string]any{"userID": userID, "after": after, "limit": limit})
+}
+
+// CountFollowers returns the number of users following the user
+func (r *Neo4jRepository) CountFollowers(ctx context.Context, userID string) (int64, error) {
+	session := r.driver.NewSession(ctx, neo4j.SessionConfig{AccessMode: neo4j.AccessModeRead})
+	defer session.Close(ctx)
+
+	count, err := session.ExecuteRead(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
+		result, err := tx.Run(ctx, `RETURN COUNT { (:Person)-[:FOLLOWS]->(:Person {id: $userID}) } AS count`, map[string]any{"userID": userID})
+		if err != nil {
+			return nil, err
+		}
+		record, err := result.Single(ctx)
+		if err != nil {
+			return nil, err
+		}
+		count, _, err := neo4j.GetRecordValue[int64](record, "count")
+		return count, err
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to count followers: %w", err)
+	}
+
+	return count.(int64), nil
+}
+
+// ListFollowing returns up to limit IDs of the users the user follows that have at least
+// minFollowers followers, ordered by ID and starting after the given ID.
+func (r *Neo4jRepository) ListFollowing(ctx context.Context, userID string, minFollowers int64, after string, limit int) ([]string, error) {
+	return r.readIDs(ctx, `
+		MATCH (:Person {id: $userID})-[:FOLLOWS]->(p:Person)
+		WHERE p.id > $after AND ($minFollowers = 0 OR COUNT { (:Person)-[:FOLLOWS]->(p) } >= $minFollowers)
+		RETURN p.id AS id
+		ORDER BY id
+		LIMIT $limit
+	`, map[string]any{"userID": userID, "minFollowers": minFollowers, "after": after, "limit": limit})
+}
+
+// readIDs runs a read query returning one "id" column
+func (r *Neo4jRepository) readIDs(ctx context.Context, query string, params map[string]any) ([]string, error) {
+	session := r.driver.NewSession(ctx, neo4j.SessionConfig{AccessMode: neo4j.AccessModeRead})
+	defer session.Close(ctx)
+
+	ids, err := session.ExecuteRead(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
+		result, err := tx.Run(ctx, query, params)
+		if err != nil {
+			return nil, err
+		}
+		records, err := result.Collect(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		ids := make([]string, 0, len(records))
+		for _, record := range records {
+			id, _, err := neo4j.GetRecordValue[string](record, "id")
+			if err != nil {
+				return nil, err
+			}
+			ids = append(ids, id)
+		}
+		return ids, nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read follow graph: %w", err)
+	}
+
+	return ids.([]string), nil
+}
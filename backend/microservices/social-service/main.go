@@ -3,17 +3,21 @@ package main
 import (
 	"context"
 	"log/slog"
+	"net"
 	"os"
 	"os/signal"
 	"syscall"
 
+	socialv1 "github.com/username/progetto/proto/gen/go/social/v1"
 	"github.com/username/progetto/shared/pkg/database/neo4j"
+	"github.com/username/progetto/shared/pkg/grpcutil"
 	"github.com/username/progetto/shared/pkg/observability"
 	"github.com/username/progetto/shared/pkg/watermillutil"
 	"github.com/username/progetto/social-service/internal/config"
 	"github.com/username/progetto/social-service/internal/events"
 	"github.com/username/progetto/social-service/internal/handler"
 	"github.com/username/progetto/social-service/internal/repository"
+	"google.golang.org/grpc/reflection"
 )
 
 func main() {
@@ -70,19 +74,44 @@ func main() {
 	}
 	defer router.Close()
 
-	// 9. Standard Graceful Shutdown
+	// 9. gRPC Server, the follow graph read by the other services
+	lis, err := net.Listen("tcp", ":50051")
+	if err != nil {
+		logger.Error("failed to listen", "error", err)
+		os.Exit(1)
+	}
+
+	trustedServices, err := grpcutil.ParseTrustedServices(cfg.TrustedServices)
+	if err != nil {
+		logger.Error("failed to parse trusted services", "error", err)
+		os.Exit(1)
+	}
+	srv := grpcutil.NewServer(grpcutil.WithServiceAuth(trustedServices, handler.AllowList)...)
+	socialv1.RegisterSocialServiceServer(srv, handler.NewSocialHandler(neo4jRepo))
+	reflection.Register(srv)
+
+	// 10. Standard Graceful Shutdown
 	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
 	defer stop()
 
-	// 10. Run Router
+	// 11. Run Router
 	go func() {
 		if err := router.Run(ctx); err != nil {
 			logger.Error("router failed", "error", err)
 		}
 	}()
 
+	// 12. Run Server
+	go func() {
+		logger.Info("social-service gRPC server listening on :50051")
+		if err := srv.Serve(lis); err != nil {
+			logger.Error("failed to serve", "error", err)
+		}
+	}()
+
 	logger.Info("social-service started")
 
 	<-ctx.Done()
 	logger.Info("shutting down social-service")
+	srv.GracefulStop()
 }
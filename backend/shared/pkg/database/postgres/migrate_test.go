@@ -0,0 +1,369 @@
+package postgres
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"testing/fstest"
+
+	sqlite "github.com/glebarez/go-sqlite"
+	gormsqlite "github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+var (
+	registerLockOnce sync.Once
+	// lockHeld counts the advisory locks taken and not yet released
+	lockHeld atomic.Int64
+	// lockFails makes pg_advisory_lock fail, as if the connection were lost
+	lockFails atomic.Bool
+)
+
+// newTestDB opens an in-memory SQLite database where pg_advisory_lock and pg_advisory_unlock are stubbed,
+// lock_held() returns how many migration locks are held
+func newTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	registerLockOnce.Do(func() {
+		stubs := map[string]func([]driver.Value) (driver.Value, error){
+			"pg_advisory_lock": func(args []driver.Value) (driver.Value, error) {
+				if lockFails.Load() {
+					return nil, errors.New("connection lost")
+				}
+				if args[0] != int64(migrationLockKey) {
+					return nil, fmt.Errorf("lock key = %v, want %d", args[0], migrationLockKey)
+				}
+				lockHeld.Add(1)
+				return nil, nil
+			},
+			"pg_advisory_unlock": func([]driver.Value) (driver.Value, error) {
+				return lockHeld.Add(-1) >= 0, nil
+			},
+		}
+		for name, fn := range stubs {
+			err := sqlite.RegisterScalarFunction(name, 1, func(_ *sqlite.FunctionContext, args []driver.Value) (driver.Value, error) {
+				return fn(args)
+			})
+			if err != nil {
+				t.Fatalf("failed to register the %s stub: %v", name, err)
+			}
+		}
+		err := sqlite.RegisterScalarFunction("lock_held", 0, func(*sqlite.FunctionContext, []driver.Value) (driver.Value, error) {
+			return lockHeld.Load(), nil
+		})
+		if err != nil {
+			t.Fatalf("failed to register lock_held: %v", err)
+		}
+	})
+
+	db, err := gorm.Open(gormsqlite.Open("file::memory:"), &gorm.Config{Logger: logger.Discard})
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatalf("failed to get database: %v", err)
+	}
+	// Every connection would open its own in-memory database
+	sqlDB.SetMaxOpenConns(1)
+	t.Cleanup(func() { sqlDB.Close() })
+	return db
+}
+
+func tableExists(t *testing.T, db *gorm.DB, name string) bool {
+	t.Helper()
+	var count int
+	if err := db.Raw("SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = ?", name).Scan(&count).Error; err != nil {
+		t.Fatalf("failed to look for table %q: %v", name, err)
+	}
+	return count > 0
+}
+
+func TestLoadMigrations(t *testing.T) {
+	tests := []struct {
+		name         string
+		files        fstest.MapFS
+		wantVersions []uint64
+		wantErr      bool
+	}{
+		{
+			name: "sorted by version, not by name",
+			files: fstest.MapFS{
+				"10_third.up.sql":  {Data: []byte("SELECT 10")},
+				"2_second.up.sql":  {Data: []byte("SELECT 2")},
+				"001_first.up.sql": {Data: []byte("SELECT 1")},
+			},
+			wantVersions: []uint64{1, 2, 10},
+		},
+		{
+			name: "other files and directories ignored",
+			files: fstest.MapFS{
+				"0001_first.up.sql":       {Data: []byte("SELECT 1")},
+				"0001_first.down.sql":     {Data: []byte("SELECT -1")},
+				"migrations.go":           {Data: []byte("package migrations")},
+				"README.md":               {Data: []byte("# Migrations")},
+				"0002_nested/0002.up.sql": {Data: []byte("SELECT 2")},
+			},
+			wantVersions: []uint64{1},
+		},
+		{
+			name:  "empty directory",
+			files: fstest.MapFS{},
+		},
+		{
+			name: "down script without up",
+			files: fstest.MapFS{
+				"0001_first.down.sql": {Data: []byte("SELECT -1")},
+			},
+			wantErr: true,
+		},
+		{
+			name: "version used by two names",
+			files: fstest.MapFS{
+				"0001_first.up.sql": {Data: []byte("SELECT 1")},
+				"0001_other.up.sql": {Data: []byte("SELECT 1")},
+			},
+			wantErr: true,
+		},
+		{
+			name: "version out of range",
+			files: fstest.MapFS{
+				"99999999999999999999_first.up.sql": {Data: []byte("SELECT 1")},
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := LoadMigrations(tt.files)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("LoadMigrations() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			var versions []uint64
+			for _, m := range got {
+				versions = append(versions, m.Version)
+			}
+			if !slices.Equal(versions, tt.wantVersions) {
+				t.Errorf("LoadMigrations() versions = %v, want %v", versions, tt.wantVersions)
+			}
+		})
+	}
+}
+
+func TestLoadMigrationsScripts(t *testing.T) {
+	got, err := LoadMigrations(fstest.MapFS{
+		"0001_first.up.sql":   {Data: []byte("CREATE TABLE a (id INTEGER)")},
+		"0001_first.down.sql": {Data: []byte("DROP TABLE a")},
+	})
+	if err != nil {
+		t.Fatalf("LoadMigrations() error = %v", err)
+	}
+	want := []Migration{{Version: 1, Name: "first", Up: "CREATE TABLE a (id INTEGER)", Down: "DROP TABLE a"}}
+	if !slices.Equal(got, want) {
+		t.Errorf("LoadMigrations() = %+v, want %+v", got, want)
+	}
+}
+
+func TestNewMigrator(t *testing.T) {
+	files := fstest.MapFS{
+		"0001_first.up.sql": {Data: []byte("SELECT 1")},
+		"0003_third.up.sql": {Data: []byte("SELECT 3")},
+	}
+
+	tests := []struct {
+		name         string
+		extra        []Migration
+		wantVersions []uint64
+		wantErr      bool
+	}{
+		{name: "files only", wantVersions: []uint64{1, 3}},
+		{
+			name:         "extra migrations sorted with the files",
+			extra:        []Migration{{Version: 4, Name: "fourth", Up: "SELECT 4"}, {Version: 2, Name: "second", Up: "SELECT 2"}},
+			wantVersions: []uint64{1, 2, 3, 4},
+		},
+		{
+			name:    "extra migration reusing a file version",
+			extra:   []Migration{{Version: 3, Name: "outbox", Up: "SELECT 3"}},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, err := NewMigrator(nil, files, tt.extra...)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("NewMigrator() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			var versions []uint64
+			for _, migration := range m.migrations {
+				versions = append(versions, migration.Version)
+			}
+			if !slices.Equal(versions, tt.wantVersions) {
+				t.Errorf("NewMigrator() versions = %v, want %v", versions, tt.wantVersions)
+			}
+		})
+	}
+}
+
+func TestMigratorUp(t *testing.T) {
+	db := newTestDB(t)
+	ctx := context.Background()
+	files := fstest.MapFS{
+		"0001_first.up.sql": {Data: []byte("CREATE TABLE first (id INTEGER)")},
+		// Records whether the migration lock was held while migrating
+		"0002_probe.up.sql": {Data: []byte("CREATE TABLE probe AS SELECT lock_held() AS held")},
+	}
+	m, err := NewMigrator(db, files)
+	if err != nil {
+		t.Fatalf("NewMigrator() error = %v", err)
+	}
+
+	applied, err := m.Up(ctx)
+	if err != nil {
+		t.Fatalf("Up() error = %v", err)
+	}
+	if applied != 2 {
+		t.Errorf("Up() = %d, want 2", applied)
+	}
+	var held int64
+	if err := db.Raw("SELECT held FROM probe").Scan(&held).Error; err != nil {
+		t.Fatalf("failed to read the probe: %v", err)
+	}
+	if held != 1 {
+		t.Errorf("locks held while migrating = %d, want 1", held)
+	}
+	if got := lockHeld.Load(); got != 0 {
+		t.Errorf("locks held after Up() = %d, want 0", got)
+	}
+
+	// Applied migrations are skipped, a new one runs alone
+	applied, err = m.Up(ctx)
+	if err != nil {
+		t.Fatalf("second Up() error = %v", err)
+	}
+	if applied != 0 {
+		t.Errorf("second Up() = %d, want 0", applied)
+	}
+
+	files["0003_third.up.sql"] = &fstest.MapFile{Data: []byte("CREATE TABLE third (id INTEGER)")}
+	m, err = NewMigrator(db, files)
+	if err != nil {
+		t.Fatalf("NewMigrator() error = %v", err)
+	}
+	applied, err = m.Up(ctx)
+	if err != nil {
+		t.Fatalf("Up() with a new migration error = %v", err)
+	}
+	if applied != 1 {
+		t.Errorf("Up() with a new migration = %d, want 1", applied)
+	}
+	version, err := m.Version(ctx)
+	if err != nil {
+		t.Fatalf("Version() error = %v", err)
+	}
+	if version != 3 {
+		t.Errorf("Version() = %d, want 3", version)
+	}
+}
+
+func TestMigratorUpFailure(t *testing.T) {
+	db := newTestDB(t)
+	ctx := context.Background()
+	m, err := NewMigrator(db, fstest.MapFS{
+		"0001_first.up.sql":  {Data: []byte("CREATE TABLE first (id INTEGER)")},
+		"0002_broken.up.sql": {Data: []byte("CREATE TABLE second (id INTEGER); INSERT INTO missing VALUES (1)")},
+		"0003_third.up.sql":  {Data: []byte("CREATE TABLE third (id INTEGER)")},
+	})
+	if err != nil {
+		t.Fatalf("NewMigrator() error = %v", err)
+	}
+
+	applied, err := m.Up(ctx)
+	if err == nil {
+		t.Fatalf("Up() error = nil, want the error of the broken migration")
+	}
+	if applied != 1 {
+		t.Errorf("Up() = %d, want 1", applied)
+	}
+	// The broken migration is rolled back and the next ones are not applied
+	for table, want := range map[string]bool{"first": true, "second": false, "third": false} {
+		if got := tableExists(t, db, table); got != want {
+			t.Errorf("table %q exists = %v, want %v", table, got, want)
+		}
+	}
+	version, err := m.Version(ctx)
+	if err != nil {
+		t.Fatalf("Version() error = %v", err)
+	}
+	if version != 1 {
+		t.Errorf("Version() = %d, want 1", version)
+	}
+	if got := lockHeld.Load(); got != 0 {
+		t.Errorf("locks held after a failed Up() = %d, want 0", got)
+	}
+}
+
+func TestMigratorUpLockFailure(t *testing.T) {
+	db := newTestDB(t)
+	m, err := NewMigrator(db, fstest.MapFS{
+		"0001_first.up.sql": {Data: []byte("CREATE TABLE first (id INTEGER)")},
+	})
+	if err != nil {
+		t.Fatalf("NewMigrator() error = %v", err)
+	}
+
+	lockFails.Store(true)
+	defer lockFails.Store(false)
+	if _, err := m.Up(context.Background()); err == nil {
+		t.Fatalf("Up() error = nil, want the lock error")
+	}
+	if tableExists(t, db, "first") {
+		t.Errorf("migration applied without the lock")
+	}
+}
+
+func TestMigratorDown(t *testing.T) {
+	db := newTestDB(t)
+	ctx := context.Background()
+	m, err := NewMigrator(db, fstest.MapFS{
+		"0001_first.up.sql":    {Data: []byte("CREATE TABLE first (id INTEGER)")},
+		"0002_second.up.sql":   {Data: []byte("CREATE TABLE second (id INTEGER)")},
+		"0002_second.down.sql": {Data: []byte("DROP TABLE second")},
+	})
+	if err != nil {
+		t.Fatalf("NewMigrator() error = %v", err)
+	}
+	if _, err := m.Up(ctx); err != nil {
+		t.Fatalf("Up() error = %v", err)
+	}
+
+	reverted, err := m.Down(ctx, 1)
+	if err != nil {
+		t.Fatalf("Down() error = %v", err)
+	}
+	if reverted != 1 || tableExists(t, db, "second") {
+		t.Errorf("Down() = %d, want the second migration reverted", reverted)
+	}
+
+	// The first migration has no down script
+	if _, err := m.Down(ctx, 1); !errors.Is(err, ErrIrreversibleMigration) {
+		t.Errorf("Down() error = %v, want ErrIrreversibleMigration", err)
+	}
+	version, err := m.Version(ctx)
+	if err != nil {
+		t.Fatalf("Version() error = %v", err)
+	}
+	if version != 1 {
+		t.Errorf("Version() = %d, want 1", version)
+	}
+}
@@ -13,7 +13,8 @@ import (
 // NewPostgres creates a new Postgres connection with OpenTelemetry instrumentation.
 // It sets connection pooling settings generally suitable for microservices.
 func NewPostgres(dsn string, logger *slog.Logger) (*gorm.DB, error) {
-	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{})
+	// TranslateError maps unique violations to gorm.ErrDuplicatedKey
+	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{TranslateError: true})
 	if err != nil {
 		return nil, fmt.Errorf("failed to connect to postgres: %w", err)
 	}
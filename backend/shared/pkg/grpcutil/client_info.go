@@ -0,0 +1,69 @@
+package grpcutil
+
+import (
+	"context"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+const (
+	ClientIPHeader        = "x-client-ip"
+	ClientUserAgentHeader = "x-client-user-agent"
+	ClientDeviceHeader    = "x-client-device"
+)
+
+// ClientInfo describes the end-user client behind a request.
+// The gateway captures it from HTTP and forwards it to backend services as gRPC metadata.
+type ClientInfo struct {
+	IP        string
+	UserAgent string
+	Device    string
+}
+
+type clientInfoKey struct{}
+
+// WithClientInfo stores the client info in the context so outgoing calls can forward it.
+func WithClientInfo(ctx context.Context, info ClientInfo) context.Context {
+	return context.WithValue(ctx, clientInfoKey{}, info)
+}
+
+// ClientInfoFromContext returns the client info of the current request.
+// It checks the local context first, then the incoming gRPC metadata.
+func ClientInfoFromContext(ctx context.Context) ClientInfo {
+	if info, ok := ctx.Value(clientInfoKey{}).(ClientInfo); ok {
+		return info
+	}
+
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return ClientInfo{}
+	}
+	return ClientInfo{
+		IP:        firstValue(md, ClientIPHeader),
+		UserAgent: firstValue(md, ClientUserAgentHeader),
+		Device:    firstValue(md, ClientDeviceHeader),
+	}
+}
+
+// UnaryClientInfoInterceptor returns a new unary client interceptor that forwards
+// the client info stored in the context as outgoing metadata.
+func UnaryClientInfoInterceptor() grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		if info, ok := ctx.Value(clientInfoKey{}).(ClientInfo); ok {
+			ctx = metadata.AppendToOutgoingContext(ctx,
+				ClientIPHeader, info.IP,
+				ClientUserAgentHeader, info.UserAgent,
+				ClientDeviceHeader, info.Device,
+			)
+		}
+		return invoker(ctx, method, req, reply, cc, opts...)
+	}
+}
+
+func firstValue(md metadata.MD, key string) string {
+	if values := md.Get(key); len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
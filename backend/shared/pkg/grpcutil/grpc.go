@@ -37,7 +37,8 @@ func NewServer(opts ...grpc.ServerOption) *grpc.Server {
 // - OTel Observability
 // - Circuit Breaker (using the provided name)
 // - Retry Logic
-// - Insecure credentials (for internal mesh)
+// - Client info propagation
+// - Insecure credentials (for internal mesh), callers authenticate with WithServiceIdentity
 func NewClient(target string, cbName string, opts ...grpc.DialOption) (*grpc.ClientConn, error) {
 	// Initialize Circuit Breaker for this client
 	cb := resiliency.NewCircuitBreaker(cbName)
@@ -47,6 +48,8 @@ func NewClient(target string, cbName string, opts ...grpc.DialOption) (*grpc.Cli
 		grpc.WithStatsHandler(otelgrpc.NewClientHandler()),
 		// Chained Interceptors for Resiliency
 		grpc.WithUnaryInterceptor(resiliency.CircuitBreakerUnaryClientInterceptor(cb)),
+		// Forward end-user client info (IP, user agent) when present
+		grpc.WithChainUnaryInterceptor(UnaryClientInfoInterceptor()),
 	}
 	baseOpts = append(baseOpts, opts...)
 
@@ -0,0 +1,277 @@
+package grpcutil
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// ServiceTokenHeader carries the identity token of the calling service
+const ServiceTokenHeader = "x-service-token"
+
+// serviceTokenTTL is short, a token is signed for each call and is only valid for its method.
+// Servers don't remember the tokens they accepted: whoever captures one on the network can replay it
+// to the same method, for the same user, until it expires. The TTL bounds that window. Each token
+// has a random ID (jti), so calls never share a token and a repeated ID is a replay.
+const serviceTokenTTL = time.Minute
+
+// ServiceIdentity is the name and signing key a service presents to the services it calls.
+// Keys are Ed25519, servers trust the public key of each caller (see ParseTrustedServices).
+type ServiceIdentity struct {
+	Name string
+	Key  ed25519.PrivateKey
+}
+
+// NewServiceIdentity parses a base64 encoded 32 byte Ed25519 seed, e.g. from
+// openssl genpkey -algorithm ed25519 -outform DER -out key.der && tail -c 32 key.der | base64
+func NewServiceIdentity(name, encodedSeed string) (ServiceIdentity, error) {
+	seed, err := base64.StdEncoding.DecodeString(encodedSeed)
+	if err != nil {
+		return ServiceIdentity{}, fmt.Errorf("invalid service key encoding: %w", err)
+	}
+	if len(seed) != ed25519.SeedSize {
+		return ServiceIdentity{}, fmt.Errorf("service key must be %d bytes, got %d", ed25519.SeedSize, len(seed))
+	}
+	return ServiceIdentity{Name: name, Key: ed25519.NewKeyFromSeed(seed)}, nil
+}
+
+// ParseTrustedServices parses "name=base64 public key" pairs separated by commas, e.g.
+// "gateway-service=<base64 key>". The public key of key.der is printed by
+// openssl pkey -inform DER -in key.der -pubout -outform DER | tail -c 32 | base64
+func ParseTrustedServices(value string) (map[string]ed25519.PublicKey, error) {
+	trusted := make(map[string]ed25519.PublicKey)
+	for _, pair := range strings.Split(value, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		name, encoded, ok := strings.Cut(pair, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid trusted service %q, expected name=key", pair)
+		}
+		key, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil || len(key) != ed25519.PublicKeySize {
+			return nil, fmt.Errorf("invalid public key of service %q", name)
+		}
+		trusted[name] = ed25519.PublicKey(key)
+	}
+	return trusted, nil
+}
+
+type userIDKey struct{}
+
+// WithUserID stores the authenticated end user in the context,
+// outgoing calls assert it in the service token so servers don't have to trust request fields.
+func WithUserID(ctx context.Context, userID string) context.Context {
+	return context.WithValue(ctx, userIDKey{}, userID)
+}
+
+// Caller is the verified identity behind an incoming call
+type Caller struct {
+	Service string
+	// UserID is the end user the calling service authenticated, empty for calls not made for a user
+	UserID string
+}
+
+type callerKey struct{}
+
+// CallerFromContext returns the caller verified by the service auth server interceptors
+func CallerFromContext(ctx context.Context) (Caller, bool) {
+	caller, ok := ctx.Value(callerKey{}).(Caller)
+	return caller, ok
+}
+
+// WithCaller stores a verified caller in the context, as UnaryServiceAuthServerInterceptor does.
+// Handlers invoked in process, like in tests, are called with it instead of a service token.
+func WithCaller(ctx context.Context, caller Caller) context.Context {
+	return context.WithValue(ctx, callerKey{}, caller)
+}
+
+// UserIDFromContext returns the end user of the incoming call, asserted by the calling service
+func UserIDFromContext(ctx context.Context) (string, bool) {
+	caller, ok := CallerFromContext(ctx)
+	if !ok || caller.UserID == "" {
+		return "", false
+	}
+	return caller.UserID, true
+}
+
+// UnaryServiceAuthClientInterceptor returns a new unary client interceptor that signs
+// a service token for each call, carrying the end user stored with WithUserID.
+func UnaryServiceAuthClientInterceptor(identity ServiceIdentity) grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		ctx, err := withServiceToken(ctx, identity, method)
+		if err != nil {
+			return err
+		}
+		return invoker(ctx, method, req, reply, cc, opts...)
+	}
+}
+
+// StreamServiceAuthClientInterceptor is the streaming counterpart of UnaryServiceAuthClientInterceptor,
+// the token is signed once for the stream
+func StreamServiceAuthClientInterceptor(identity ServiceIdentity) grpc.StreamClientInterceptor {
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		ctx, err := withServiceToken(ctx, identity, method)
+		if err != nil {
+			return nil, err
+		}
+		return streamer(ctx, desc, cc, method, opts...)
+	}
+}
+
+// WithServiceIdentity returns the NewClient options authenticating the calls as the given service
+func WithServiceIdentity(identity ServiceIdentity) []grpc.DialOption {
+	return []grpc.DialOption{
+		grpc.WithChainUnaryInterceptor(UnaryServiceAuthClientInterceptor(identity)),
+		grpc.WithChainStreamInterceptor(StreamServiceAuthClientInterceptor(identity)),
+	}
+}
+
+// withServiceToken signs the service token of a call to the method and adds it to the outgoing metadata
+func withServiceToken(ctx context.Context, identity ServiceIdentity, method string) (context.Context, error) {
+	userID, _ := ctx.Value(userIDKey{}).(string)
+	tokenID := make([]byte, 16)
+	if _, err := rand.Read(tokenID); err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to generate service token ID: %v", err)
+	}
+	now := time.Now()
+	// The issuer is the calling service, the audience the called method
+	// and the subject the end user the call is made for, if any
+	claims := jwt.RegisteredClaims{
+		ID:        base64.RawURLEncoding.EncodeToString(tokenID),
+		Issuer:    identity.Name,
+		Subject:   userID,
+		Audience:  jwt.ClaimStrings{method},
+		IssuedAt:  jwt.NewNumericDate(now),
+		ExpiresAt: jwt.NewNumericDate(now.Add(serviceTokenTTL)),
+	}
+
+	token, err := jwt.NewWithClaims(jwt.SigningMethodEdDSA, claims).SignedString(identity.Key)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to sign service token: %v", err)
+	}
+	return metadata.AppendToOutgoingContext(ctx, ServiceTokenHeader, token), nil
+}
+
+// MethodAllowList maps full method names (e.g. "/auth.v1.AuthService/Login")
+// to the services allowed to call them. Methods missing from the list can't be called.
+type MethodAllowList map[string][]string
+
+// AllowService allows the services to call every method of the gRPC service, streaming ones included
+func AllowService(desc *grpc.ServiceDesc, services ...string) MethodAllowList {
+	allowList := make(MethodAllowList, len(desc.Methods)+len(desc.Streams))
+	for _, method := range desc.Methods {
+		allowList["/"+desc.ServiceName+"/"+method.MethodName] = services
+	}
+	for _, stream := range desc.Streams {
+		allowList["/"+desc.ServiceName+"/"+stream.StreamName] = services
+	}
+	return allowList
+}
+
+var errInvalidServiceToken = errors.New("invalid service token")
+
+// UnaryServiceAuthServerInterceptor returns a new unary server interceptor that verifies the
+// service token of each call against the trusted keys and enforces the allow-list.
+// The verified caller is stored in the context, see CallerFromContext.
+func UnaryServiceAuthServerInterceptor(trusted map[string]ed25519.PublicKey, allowList MethodAllowList) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		caller, err := authorizeCall(ctx, trusted, allowList, info.FullMethod)
+		if err != nil {
+			return nil, err
+		}
+		return handler(WithCaller(ctx, caller), req)
+	}
+}
+
+// StreamServiceAuthServerInterceptor is the streaming counterpart of UnaryServiceAuthServerInterceptor.
+// Server reflection is a streaming service too: it is only reachable by the services allowed on it.
+func StreamServiceAuthServerInterceptor(trusted map[string]ed25519.PublicKey, allowList MethodAllowList) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		caller, err := authorizeCall(ss.Context(), trusted, allowList, info.FullMethod)
+		if err != nil {
+			return err
+		}
+		return handler(srv, &callerStream{ServerStream: ss, ctx: WithCaller(ss.Context(), caller)})
+	}
+}
+
+// callerStream exposes the context carrying the verified caller to the stream handler
+type callerStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *callerStream) Context() context.Context {
+	return s.ctx
+}
+
+// WithServiceAuth returns the NewServer options verifying the callers of every method, unary and streaming
+func WithServiceAuth(trusted map[string]ed25519.PublicKey, allowList MethodAllowList) []grpc.ServerOption {
+	return []grpc.ServerOption{
+		grpc.ChainUnaryInterceptor(UnaryServiceAuthServerInterceptor(trusted, allowList)),
+		grpc.ChainStreamInterceptor(StreamServiceAuthServerInterceptor(trusted, allowList)),
+	}
+}
+
+// authorizeCall verifies the service token of the call and that its caller may call the method
+func authorizeCall(ctx context.Context, trusted map[string]ed25519.PublicKey, allowList MethodAllowList, method string) (Caller, error) {
+	caller, err := verifyServiceToken(ctx, trusted, method)
+	if err != nil {
+		slog.WarnContext(ctx, "rejected unauthenticated call", "method", method, "error", err)
+		return Caller{}, status.Error(codes.Unauthenticated, "service authentication required")
+	}
+
+	if !slices.Contains(allowList[method], caller.Service) {
+		slog.WarnContext(ctx, "rejected call from service not allowed", "method", method, "service", caller.Service)
+		return Caller{}, status.Errorf(codes.PermissionDenied, "service %s may not call %s", caller.Service, method)
+	}
+	return caller, nil
+}
+
+func verifyServiceToken(ctx context.Context, trusted map[string]ed25519.PublicKey, method string) (Caller, error) {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return Caller{}, errInvalidServiceToken
+	}
+	tokenString := firstValue(md, ServiceTokenHeader)
+	if tokenString == "" {
+		return Caller{}, errInvalidServiceToken
+	}
+
+	claims := &jwt.RegisteredClaims{}
+	_, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
+		issuer, err := token.Claims.GetIssuer()
+		if err != nil {
+			return nil, err
+		}
+		key, ok := trusted[issuer]
+		if !ok {
+			return nil, fmt.Errorf("unknown service %q", issuer)
+		}
+		return key, nil
+	},
+		jwt.WithValidMethods([]string{jwt.SigningMethodEdDSA.Alg()}),
+		jwt.WithAudience(method),
+		jwt.WithExpirationRequired(),
+		jwt.WithIssuedAt(),
+	)
+	if err != nil {
+		return Caller{}, err
+	}
+	return Caller{Service: claims.Issuer, UserID: claims.Subject}, nil
+}
@@ -0,0 +1,443 @@
+package grpcutil
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/base64"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	testMethod   = "/post.v1.PostService/CreatePost"
+	otherMethod  = "/post.v1.PostService/DeletePost"
+	streamMethod = "/post.v1.PostService/WatchPosts"
+)
+
+func newTestIdentity(t *testing.T, name string) ServiceIdentity {
+	t.Helper()
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	return ServiceIdentity{Name: name, Key: key}
+}
+
+// signedContext signs a service token with the client interceptor
+// and returns the incoming context of the call as the server sees it
+func signedContext(t *testing.T, ctx context.Context, identity ServiceIdentity, method string) context.Context {
+	t.Helper()
+	var md metadata.MD
+	invoker := func(ctx context.Context, _ string, _, _ interface{}, _ *grpc.ClientConn, _ ...grpc.CallOption) error {
+		md, _ = metadata.FromOutgoingContext(ctx)
+		return nil
+	}
+	if err := UnaryServiceAuthClientInterceptor(identity)(ctx, method, nil, nil, nil, invoker); err != nil {
+		t.Fatalf("client interceptor error = %v", err)
+	}
+	return metadata.NewIncomingContext(context.Background(), md)
+}
+
+// tokenContext returns an incoming context carrying the token
+func tokenContext(t *testing.T, method jwt.SigningMethod, claims jwt.RegisteredClaims, key interface{}) context.Context {
+	t.Helper()
+	token, err := jwt.NewWithClaims(method, claims).SignedString(key)
+	if err != nil {
+		t.Fatalf("failed to sign token: %v", err)
+	}
+	return metadata.NewIncomingContext(context.Background(), metadata.Pairs(ServiceTokenHeader, token))
+}
+
+func TestUnaryServiceAuthServerInterceptor(t *testing.T) {
+	gateway := newTestIdentity(t, "gateway-service")
+	social := newTestIdentity(t, "social-service")
+	untrusted := newTestIdentity(t, "search-service")
+	impostor := newTestIdentity(t, "gateway-service")
+
+	trusted := map[string]ed25519.PublicKey{
+		gateway.Name: gateway.Key.Public().(ed25519.PublicKey),
+		social.Name:  social.Key.Public().(ed25519.PublicKey),
+	}
+	allowList := MethodAllowList{
+		testMethod:  {gateway.Name},
+		otherMethod: {gateway.Name, social.Name},
+	}
+	now := time.Now()
+
+	tests := []struct {
+		name       string
+		ctx        func(t *testing.T) context.Context
+		method     string
+		wantCode   codes.Code
+		wantCaller Caller
+	}{
+		{
+			name: "allowed service with a user",
+			ctx: func(t *testing.T) context.Context {
+				return signedContext(t, WithUserID(context.Background(), "user-1"), gateway, testMethod)
+			},
+			method:     testMethod,
+			wantCode:   codes.OK,
+			wantCaller: Caller{Service: gateway.Name, UserID: "user-1"},
+		},
+		{
+			name: "allowed service without a user",
+			ctx: func(t *testing.T) context.Context {
+				return signedContext(t, context.Background(), social, otherMethod)
+			},
+			method:     otherMethod,
+			wantCode:   codes.OK,
+			wantCaller: Caller{Service: social.Name},
+		},
+		{
+			name: "trusted service not allowed on the method",
+			ctx: func(t *testing.T) context.Context {
+				return signedContext(t, context.Background(), social, testMethod)
+			},
+			method:   testMethod,
+			wantCode: codes.PermissionDenied,
+		},
+		{
+			name: "method missing from the allow-list",
+			ctx: func(t *testing.T) context.Context {
+				return signedContext(t, context.Background(), gateway, "/post.v1.PostService/ListPosts")
+			},
+			method:   "/post.v1.PostService/ListPosts",
+			wantCode: codes.PermissionDenied,
+		},
+		{
+			name: "untrusted service",
+			ctx: func(t *testing.T) context.Context {
+				return signedContext(t, context.Background(), untrusted, testMethod)
+			},
+			method:   testMethod,
+			wantCode: codes.Unauthenticated,
+		},
+		{
+			name: "trusted name signed with another key",
+			ctx: func(t *testing.T) context.Context {
+				return signedContext(t, context.Background(), impostor, testMethod)
+			},
+			method:   testMethod,
+			wantCode: codes.Unauthenticated,
+		},
+		{
+			name: "token signed for another method",
+			ctx: func(t *testing.T) context.Context {
+				return signedContext(t, context.Background(), gateway, otherMethod)
+			},
+			method:   testMethod,
+			wantCode: codes.Unauthenticated,
+		},
+		{
+			name:     "no metadata",
+			ctx:      func(*testing.T) context.Context { return context.Background() },
+			method:   testMethod,
+			wantCode: codes.Unauthenticated,
+		},
+		{
+			name: "no token",
+			ctx: func(*testing.T) context.Context {
+				return metadata.NewIncomingContext(context.Background(), metadata.Pairs("other", "value"))
+			},
+			method:   testMethod,
+			wantCode: codes.Unauthenticated,
+		},
+		{
+			name: "expired token",
+			ctx: func(t *testing.T) context.Context {
+				return tokenContext(t, jwt.SigningMethodEdDSA, jwt.RegisteredClaims{
+					Issuer:    gateway.Name,
+					Audience:  jwt.ClaimStrings{testMethod},
+					IssuedAt:  jwt.NewNumericDate(now.Add(-2 * serviceTokenTTL)),
+					ExpiresAt: jwt.NewNumericDate(now.Add(-serviceTokenTTL)),
+				}, gateway.Key)
+			},
+			method:   testMethod,
+			wantCode: codes.Unauthenticated,
+		},
+		{
+			name: "token without expiry",
+			ctx: func(t *testing.T) context.Context {
+				return tokenContext(t, jwt.SigningMethodEdDSA, jwt.RegisteredClaims{
+					Issuer:   gateway.Name,
+					Audience: jwt.ClaimStrings{testMethod},
+					IssuedAt: jwt.NewNumericDate(now),
+				}, gateway.Key)
+			},
+			method:   testMethod,
+			wantCode: codes.Unauthenticated,
+		},
+		{
+			name: "token issued in the future",
+			ctx: func(t *testing.T) context.Context {
+				return tokenContext(t, jwt.SigningMethodEdDSA, jwt.RegisteredClaims{
+					Issuer:    gateway.Name,
+					Audience:  jwt.ClaimStrings{testMethod},
+					IssuedAt:  jwt.NewNumericDate(now.Add(time.Hour)),
+					ExpiresAt: jwt.NewNumericDate(now.Add(2 * time.Hour)),
+				}, gateway.Key)
+			},
+			method:   testMethod,
+			wantCode: codes.Unauthenticated,
+		},
+		{
+			name: "token signed with HMAC on the public key",
+			ctx: func(t *testing.T) context.Context {
+				return tokenContext(t, jwt.SigningMethodHS256, jwt.RegisteredClaims{
+					Issuer:    gateway.Name,
+					Audience:  jwt.ClaimStrings{testMethod},
+					IssuedAt:  jwt.NewNumericDate(now),
+					ExpiresAt: jwt.NewNumericDate(now.Add(serviceTokenTTL)),
+				}, []byte(trusted[gateway.Name]))
+			},
+			method:   testMethod,
+			wantCode: codes.Unauthenticated,
+		},
+	}
+
+	interceptor := UnaryServiceAuthServerInterceptor(trusted, allowList)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var gotCaller Caller
+			called := false
+			handler := func(ctx context.Context, _ interface{}) (interface{}, error) {
+				called = true
+				gotCaller, _ = CallerFromContext(ctx)
+				return "ok", nil
+			}
+
+			_, err := interceptor(tt.ctx(t), nil, &grpc.UnaryServerInfo{FullMethod: tt.method}, handler)
+			if got := status.Code(err); got != tt.wantCode {
+				t.Fatalf("interceptor code = %v, want %v (error %v)", got, tt.wantCode, err)
+			}
+			if called != (tt.wantCode == codes.OK) {
+				t.Errorf("handler called = %v, want %v", called, tt.wantCode == codes.OK)
+			}
+			if gotCaller != tt.wantCaller {
+				t.Errorf("CallerFromContext() = %+v, want %+v", gotCaller, tt.wantCaller)
+			}
+		})
+	}
+}
+
+// fakeServerStream is a server stream with only a context
+type fakeServerStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *fakeServerStream) Context() context.Context {
+	return s.ctx
+}
+
+// streamSignedContext signs a service token with the stream client interceptor
+// and returns the incoming context of the stream as the server sees it
+func streamSignedContext(t *testing.T, identity ServiceIdentity, method string) context.Context {
+	t.Helper()
+	var md metadata.MD
+	streamer := func(ctx context.Context, _ *grpc.StreamDesc, _ *grpc.ClientConn, _ string, _ ...grpc.CallOption) (grpc.ClientStream, error) {
+		md, _ = metadata.FromOutgoingContext(ctx)
+		return nil, nil
+	}
+	ctx := WithUserID(context.Background(), "user-1")
+	if _, err := StreamServiceAuthClientInterceptor(identity)(ctx, &grpc.StreamDesc{}, nil, method, streamer); err != nil {
+		t.Fatalf("stream client interceptor error = %v", err)
+	}
+	return metadata.NewIncomingContext(context.Background(), md)
+}
+
+func TestStreamServiceAuthServerInterceptor(t *testing.T) {
+	gateway := newTestIdentity(t, "gateway-service")
+	social := newTestIdentity(t, "social-service")
+	trusted := map[string]ed25519.PublicKey{
+		gateway.Name: gateway.Key.Public().(ed25519.PublicKey),
+		social.Name:  social.Key.Public().(ed25519.PublicKey),
+	}
+	allowList := MethodAllowList{streamMethod: {gateway.Name}}
+	reflectionMethod := "/grpc.reflection.v1.ServerReflection/ServerReflectionInfo"
+
+	tests := []struct {
+		name       string
+		ctx        func(t *testing.T) context.Context
+		method     string
+		wantCode   codes.Code
+		wantCaller Caller
+	}{
+		{
+			name:       "allowed service",
+			ctx:        func(t *testing.T) context.Context { return streamSignedContext(t, gateway, streamMethod) },
+			method:     streamMethod,
+			wantCode:   codes.OK,
+			wantCaller: Caller{Service: gateway.Name, UserID: "user-1"},
+		},
+		{
+			name:     "trusted service not allowed on the method",
+			ctx:      func(t *testing.T) context.Context { return streamSignedContext(t, social, streamMethod) },
+			method:   streamMethod,
+			wantCode: codes.PermissionDenied,
+		},
+		{
+			name:     "token signed for another method",
+			ctx:      func(t *testing.T) context.Context { return streamSignedContext(t, gateway, otherMethod) },
+			method:   streamMethod,
+			wantCode: codes.Unauthenticated,
+		},
+		{
+			name:     "server reflection without a token",
+			ctx:      func(*testing.T) context.Context { return context.Background() },
+			method:   reflectionMethod,
+			wantCode: codes.Unauthenticated,
+		},
+		{
+			name:     "server reflection not allowed",
+			ctx:      func(t *testing.T) context.Context { return streamSignedContext(t, gateway, reflectionMethod) },
+			method:   reflectionMethod,
+			wantCode: codes.PermissionDenied,
+		},
+	}
+
+	interceptor := StreamServiceAuthServerInterceptor(trusted, allowList)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var gotCaller Caller
+			called := false
+			handler := func(_ interface{}, ss grpc.ServerStream) error {
+				called = true
+				gotCaller, _ = CallerFromContext(ss.Context())
+				return nil
+			}
+
+			err := interceptor(nil, &fakeServerStream{ctx: tt.ctx(t)}, &grpc.StreamServerInfo{FullMethod: tt.method}, handler)
+			if got := status.Code(err); got != tt.wantCode {
+				t.Fatalf("interceptor code = %v, want %v (error %v)", got, tt.wantCode, err)
+			}
+			if called != (tt.wantCode == codes.OK) {
+				t.Errorf("handler called = %v, want %v", called, tt.wantCode == codes.OK)
+			}
+			if gotCaller != tt.wantCaller {
+				t.Errorf("CallerFromContext() = %+v, want %+v", gotCaller, tt.wantCaller)
+			}
+		})
+	}
+}
+
+func TestServiceTokenID(t *testing.T) {
+	gateway := newTestIdentity(t, "gateway-service")
+
+	seen := make(map[string]bool)
+	for i := 0; i < 3; i++ {
+		md, _ := metadata.FromIncomingContext(signedContext(t, context.Background(), gateway, testMethod))
+		claims := &jwt.RegisteredClaims{}
+		if _, _, err := jwt.NewParser().ParseUnverified(firstValue(md, ServiceTokenHeader), claims); err != nil {
+			t.Fatalf("failed to parse the service token: %v", err)
+		}
+		if claims.ID == "" {
+			t.Fatalf("service token ID is empty")
+		}
+		if seen[claims.ID] {
+			t.Errorf("service token ID %q reused", claims.ID)
+		}
+		seen[claims.ID] = true
+	}
+}
+
+func TestAllowService(t *testing.T) {
+	desc := &grpc.ServiceDesc{
+		ServiceName: "post.v1.PostService",
+		Methods:     []grpc.MethodDesc{{MethodName: "CreatePost"}, {MethodName: "DeletePost"}},
+		Streams:     []grpc.StreamDesc{{StreamName: "WatchPosts"}},
+	}
+	allowList := AllowService(desc, "gateway-service", "social-service")
+
+	if len(allowList) != 3 {
+		t.Fatalf("AllowService() has %d methods, want 3", len(allowList))
+	}
+	for _, method := range []string{testMethod, otherMethod, streamMethod} {
+		if got := allowList[method]; !slices.Equal(got, []string{"gateway-service", "social-service"}) {
+			t.Errorf("AllowService()[%q] = %v, want [gateway-service social-service]", method, got)
+		}
+	}
+}
+
+func TestParseTrustedServices(t *testing.T) {
+	public, _, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	encoded := base64.StdEncoding.EncodeToString(public)
+
+	tests := []struct {
+		name      string
+		value     string
+		wantNames []string
+		wantErr   bool
+	}{
+		{name: "empty", value: "", wantNames: nil},
+		{name: "one service", value: "gateway-service=" + encoded, wantNames: []string{"gateway-service"}},
+		{name: "spaces and empty pairs", value: " gateway-service=" + encoded + " ,, social-service=" + encoded + ",", wantNames: []string{"gateway-service", "social-service"}},
+		{name: "missing key", value: "gateway-service", wantErr: true},
+		{name: "invalid encoding", value: "gateway-service=not base64!", wantErr: true},
+		{name: "short key", value: "gateway-service=" + base64.StdEncoding.EncodeToString(public[:16]), wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseTrustedServices(tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseTrustedServices() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if len(got) != len(tt.wantNames) {
+				t.Fatalf("ParseTrustedServices() = %d services, want %d", len(got), len(tt.wantNames))
+			}
+			for _, name := range tt.wantNames {
+				if !public.Equal(got[name]) {
+					t.Errorf("ParseTrustedServices()[%q] = %v, want the public key", name, got[name])
+				}
+			}
+		})
+	}
+}
+
+func TestNewServiceIdentity(t *testing.T) {
+	seed := make([]byte, ed25519.SeedSize)
+	if _, err := rand.Read(seed); err != nil {
+		t.Fatalf("failed to generate seed: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		seed    string
+		wantErr bool
+	}{
+		{name: "valid seed", seed: base64.StdEncoding.EncodeToString(seed)},
+		{name: "invalid encoding", seed: "not base64!", wantErr: true},
+		{name: "short seed", seed: base64.StdEncoding.EncodeToString(seed[:16]), wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			identity, err := NewServiceIdentity("gateway-service", tt.seed)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("NewServiceIdentity() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if want := ed25519.NewKeyFromSeed(seed); !want.Equal(identity.Key) || identity.Name != "gateway-service" {
+				t.Errorf("NewServiceIdentity() = %+v, want the key of the seed", identity)
+			}
+		})
+	}
+}
@@ -0,0 +1,49 @@
+package jwtutil
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Denylist tracks sessions whose access tokens must be rejected before they expire.
+// Entries only need to outlive the access token TTL, so they are short-lived.
+type Denylist interface {
+	// Revoke denylists every access token carrying the given session ID for ttl.
+	Revoke(ctx context.Context, sessionID string, ttl time.Duration) error
+	// IsRevoked reports whether the session has been denylisted.
+	IsRevoked(ctx context.Context, sessionID string) (bool, error)
+}
+
+// RedisDenylist implements Denylist using Redis keys with a TTL.
+type RedisDenylist struct {
+	client *redis.Client
+}
+
+// NewRedisDenylist creates a new RedisDenylist.
+func NewRedisDenylist(client *redis.Client) *RedisDenylist {
+	return &RedisDenylist{client: client}
+}
+
+func denylistKey(sessionID string) string {
+	return fmt.Sprintf("denylist:session:%s", sessionID)
+}
+
+// Revoke implements Denylist.
+func (d *RedisDenylist) Revoke(ctx context.Context, sessionID string, ttl time.Duration) error {
+	return d.client.Set(ctx, denylistKey(sessionID), "1", ttl).Err()
+}
+
+// IsRevoked implements Denylist.
+func (d *RedisDenylist) IsRevoked(ctx context.Context, sessionID string) (bool, error) {
+	if sessionID == "" {
+		return false, nil
+	}
+	n, err := d.client.Exists(ctx, denylistKey(sessionID)).Result()
+	if err != nil {
+		return false, fmt.Errorf("failed to check denylist in redis: %w", err)
+	}
+	return n > 0, nil
+}
@@ -1,8 +1,9 @@
 package jwtutil
 
 import (
+	"context"
 	"errors"
-	"fmt"
+	"slices"
 
 	"github.com/golang-jwt/jwt/v5"
 )
@@ -10,37 +11,35 @@ import (
 var (
 	ErrInvalidToken = errors.New("invalid token")
 	ErrExpiredToken = errors.New("token expired")
+	ErrUnknownKey   = errors.New("unknown signing key")
 )
 
 type Claims struct {
 	jwt.RegisteredClaims
-	UserID string `json:"sub"`
-	Role   string `json:"role"`
+	UserID    string `json:"sub"`
+	Role      string `json:"role"`
+	SessionID string `json:"sid,omitempty"`
+	// EmailVerified reflects the user state when the token was issued
+	EmailVerified bool `json:"email_verified"`
+	// Permissions granted by the role when the token was issued
+	Permissions []string `json:"perms,omitempty"`
 }
 
-// ValidateToken verifies the JWT and returns the user ID (sub)
-func ValidateToken(tokenString string, secret []byte) (string, error) {
-	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
-		}
-		return secret, nil
-	})
+func (c *Claims) HasPermission(permission string) bool {
+	return slices.Contains(c.Permissions, permission)
+}
+
+// Verifier validates access tokens signed by auth-service
+type Verifier interface {
+	// ParseToken verifies the JWT and returns its claims
+	ParseToken(ctx context.Context, tokenString string) (*Claims, error)
+}
 
+// ValidateToken verifies the JWT and returns the user ID (sub)
+func ValidateToken(ctx context.Context, verifier Verifier, tokenString string) (string, error) {
+	claims, err := verifier.ParseToken(ctx, tokenString)
 	if err != nil {
-		if errors.Is(err, jwt.ErrTokenExpired) {
-			return "", ErrExpiredToken
-		}
 		return "", err
 	}
-
-	if claims, ok := token.Claims.(jwt.MapClaims); ok && token.Valid {
-		sub, ok := claims["sub"].(string)
-		if !ok {
-			return "", ErrInvalidToken
-		}
-		return sub, nil
-	}
-
-	return "", ErrInvalidToken
+	return claims.UserID, nil
 }
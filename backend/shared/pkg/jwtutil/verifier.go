@@ -0,0 +1,183 @@
+package jwtutil
+
+import (
+	"context"
+	"crypto"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// JWKSFetcher loads the current key set, e.g. from auth-service GetJWKS
+type JWKSFetcher func(ctx context.Context) (*JWKS, error)
+
+// NewHTTPJWKSFetcher loads a JWKS document over HTTP,
+// e.g. the gateway /.well-known/jwks.json or the jwks_uri of an OIDC provider.
+func NewHTTPJWKSFetcher(client *http.Client, url string) JWKSFetcher {
+	return func(ctx context.Context) (*JWKS, error) {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+		if err != nil {
+			return nil, err
+		}
+		resp, err := client.Do(req)
+		if err != nil {
+			return nil, err
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode != http.StatusOK {
+			return nil, fmt.Errorf("unexpected jwks status %d", resp.StatusCode)
+		}
+
+		var jwks JWKS
+		if err := json.NewDecoder(resp.Body).Decode(&jwks); err != nil {
+			return nil, fmt.Errorf("failed to decode jwks: %w", err)
+		}
+		return &jwks, nil
+	}
+}
+
+// ValidMethods are the signing algorithms accepted by JWKSVerifier
+var ValidMethods = []string{"RS256", "EdDSA"}
+
+// minRefreshInterval bounds how often an unknown kid can trigger a fetch
+const minRefreshInterval = 30 * time.Second
+
+type verificationKey struct {
+	alg       string
+	publicKey crypto.PublicKey
+}
+
+// JWKSVerifier verifies tokens against a cached JWKS.
+// Every key in the set is accepted, so tokens signed before a rotation stay valid.
+type JWKSVerifier struct {
+	fetch JWKSFetcher
+	ttl   time.Duration
+
+	mu        sync.RWMutex
+	keys      map[string]verificationKey
+	fetchedAt time.Time
+
+	// refreshMu ensures a single fetch at a time
+	refreshMu sync.Mutex
+}
+
+// NewJWKSVerifier creates a verifier that refreshes the key set every ttl,
+// or earlier when a token carries a kid it has not seen yet.
+func NewJWKSVerifier(fetch JWKSFetcher, ttl time.Duration) *JWKSVerifier {
+	return &JWKSVerifier{
+		fetch: fetch,
+		ttl:   ttl,
+		keys:  make(map[string]verificationKey),
+	}
+}
+
+func (v *JWKSVerifier) ParseToken(ctx context.Context, tokenString string) (*Claims, error) {
+	claims := &Claims{}
+	token, err := jwt.ParseWithClaims(tokenString, claims, v.Keyfunc(ctx), jwt.WithValidMethods(ValidMethods))
+
+	if err != nil {
+		if errors.Is(err, jwt.ErrTokenExpired) {
+			return nil, ErrExpiredToken
+		}
+		return nil, err
+	}
+
+	if !token.Valid || claims.UserID == "" {
+		return nil, ErrInvalidToken
+	}
+
+	return claims, nil
+}
+
+// Keyfunc resolves the key of a token by its kid header.
+// It lets callers verify tokens with their own claims, e.g. OIDC ID tokens.
+func (v *JWKSVerifier) Keyfunc(ctx context.Context) jwt.Keyfunc {
+	return func(token *jwt.Token) (interface{}, error) {
+		kid, _ := token.Header["kid"].(string)
+		if kid == "" {
+			return nil, errors.New("missing kid header")
+		}
+
+		key, err := v.key(ctx, kid)
+		if err != nil {
+			return nil, err
+		}
+		if key.alg != token.Method.Alg() {
+			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+		}
+		return key.publicKey, nil
+	}
+}
+
+func (v *JWKSVerifier) key(ctx context.Context, kid string) (verificationKey, error) {
+	v.mu.RLock()
+	key, ok := v.keys[kid]
+	age := time.Since(v.fetchedAt)
+	v.mu.RUnlock()
+
+	if ok && age < v.ttl {
+		return key, nil
+	}
+	// Unknown kid right after a fetch: don't let forged kids hammer the key source
+	if !ok && age < minRefreshInterval {
+		return verificationKey{}, ErrUnknownKey
+	}
+
+	if err := v.refresh(ctx); err != nil {
+		if ok {
+			// Keep verifying with the stale set while the key source is down
+			slog.WarnContext(ctx, "failed to refresh jwks, using cached keys", "error", err)
+			return key, nil
+		}
+		return verificationKey{}, fmt.Errorf("failed to refresh jwks: %w", err)
+	}
+
+	v.mu.RLock()
+	key, ok = v.keys[kid]
+	v.mu.RUnlock()
+	if !ok {
+		return verificationKey{}, ErrUnknownKey
+	}
+	return key, nil
+}
+
+func (v *JWKSVerifier) refresh(ctx context.Context) error {
+	v.refreshMu.Lock()
+	defer v.refreshMu.Unlock()
+
+	// Another caller may have refreshed while we were waiting
+	v.mu.RLock()
+	fresh := time.Since(v.fetchedAt) < minRefreshInterval
+	v.mu.RUnlock()
+	if fresh {
+		return nil
+	}
+
+	jwks, err := v.fetch(ctx)
+	if err != nil {
+		return err
+	}
+
+	keys := make(map[string]verificationKey, len(jwks.Keys))
+	for _, jwk := range jwks.Keys {
+		publicKey, err := jwk.PublicKey()
+		if err != nil {
+			slog.WarnContext(ctx, "skipping invalid jwk", "kid", jwk.Kid, "error", err)
+			continue
+		}
+		keys[jwk.Kid] = verificationKey{alg: jwk.Algorithm(), publicKey: publicKey}
+	}
+
+	v.mu.Lock()
+	v.keys = keys
+	v.fetchedAt = time.Now()
+	v.mu.Unlock()
+	return nil
+}
@@ -7,11 +7,78 @@ import (
 )
 
 // Post represents the shared post data structure.
+// Revisions are the previous versions of an edited post, oldest first and capped.
+// DeletedAt marks the tombstone of a deleted post, whose content is erased.
 type Post struct {
 	ID        primitive.ObjectID `json:"id" bson:"_id,omitempty"`
 	AuthorID  string             `json:"author_id" bson:"author_id" validate:"required"`
 	Content   string             `json:"content" bson:"content" validate:"required"`
 	MediaURLs []string           `json:"media_urls" bson:"media_urls"`
 	Likes     int32              `json:"likes_count" bson:"likes_count"`
+	Comments  int32              `json:"comments_count" bson:"comments_count"`
 	CreatedAt time.Time          `json:"created_at" bson:"created_at"`
+	EditedAt  *time.Time         `json:"edited_at,omitempty" bson:"edited_at,omitempty"`
+	Revisions []PostRevision     `json:"-" bson:"revisions,omitempty"`
+	DeletedAt *time.Time         `json:"-" bson:"deleted_at,omitempty"`
+}
+
+// PostRevision is a replaced version of a post, WrittenAt is when it was created or last edited
+type PostRevision struct {
+	Content   string    `json:"content" bson:"content"`
+	MediaURLs []string  `json:"media_urls" bson:"media_urls"`
+	WrittenAt time.Time `json:"written_at" bson:"written_at"`
+}
+
+// PostDeletedEvent is the payload of the post.deleted topic, published by post-service
+type PostDeletedEvent struct {
+	PostID    string    `json:"post_id"`
+	AuthorID  string    `json:"author_id"`
+	DeletedAt time.Time `json:"deleted_at"`
+}
+
+// Like is a user's like of a post, a user likes a post at most once.
+// AuthorID is the author of the post, so the likes of the posts of a deleted user can be erased.
+type Like struct {
+	ID        primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	PostID    string             `json:"post_id" bson:"post_id"`
+	AuthorID  string             `json:"author_id" bson:"author_id"`
+	UserID    string             `json:"user_id" bson:"user_id"`
+	CreatedAt time.Time          `json:"created_at" bson:"created_at"`
+}
+
+// PostLikeEvent is the payload of the post.liked and post.unliked topics, published by post-service.
+// LikesCount is the count after the change.
+type PostLikeEvent struct {
+	PostID     string    `json:"post_id"`
+	AuthorID   string    `json:"author_id"`
+	UserID     string    `json:"user_id"`
+	LikesCount int32     `json:"likes_count"`
+	OccurredAt time.Time `json:"occurred_at"`
+}
+
+// Comment is a comment of a post, or a reply to a top level comment when ParentID is set.
+// Replies have a single level, PostAuthorID lets the post author moderate and erase them.
+type Comment struct {
+	ID           primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	PostID       string             `json:"post_id" bson:"post_id"`
+	PostAuthorID string             `json:"post_author_id" bson:"post_author_id"`
+	ParentID     string             `json:"parent_id,omitempty" bson:"parent_id"`
+	AuthorID     string             `json:"author_id" bson:"author_id"`
+	Content      string             `json:"content" bson:"content"`
+	Replies      int32              `json:"replies_count" bson:"replies_count"`
+	CreatedAt    time.Time          `json:"created_at" bson:"created_at"`
+	EditedAt     *time.Time         `json:"edited_at,omitempty" bson:"edited_at,omitempty"`
+}
+
+// CommentCreatedEvent is the payload of the comment.created topic, published by post-service.
+// ParentAuthorID is the author of the replied comment, empty for top level comments.
+type CommentCreatedEvent struct {
+	CommentID      string    `json:"comment_id"`
+	PostID         string    `json:"post_id"`
+	PostAuthorID   string    `json:"post_author_id"`
+	ParentID       string    `json:"parent_id,omitempty"`
+	ParentAuthorID string    `json:"parent_author_id,omitempty"`
+	AuthorID       string    `json:"author_id"`
+	Content        string    `json:"content"`
+	CreatedAt      time.Time `json:"created_at"`
 }
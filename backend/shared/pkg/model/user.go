@@ -1,6 +1,7 @@
 package model
 
 import (
+	"strconv"
 	"time"
 
 	"gorm.io/gorm"
@@ -9,12 +10,72 @@ import (
 // User represents the shared user data structure.
 // It serves as the single source of truth for API requests/responses and database persistence.
 type User struct {
-	ID        uint           `json:"id" bson:"_id,omitempty" gorm:"primaryKey" validate:"-"`
-	Username  string         `json:"username" bson:"username" gorm:"uniqueIndex" validate:"required,min=3"`
-	Email     string         `json:"email" bson:"email" gorm:"uniqueIndex" validate:"required,email"`
-	Password  string         `json:"-" bson:"password" gorm:"not null" validate:"required"`
-	Role      string         `json:"role" bson:"role" gorm:"default:'user'"`
-	CreatedAt time.Time      `json:"created_at" bson:"created_at"`
-	UpdatedAt time.Time      `json:"updated_at" bson:"updated_at"`
-	DeletedAt gorm.DeletedAt `json:"-" bson:"-" gorm:"index"`
+	ID              uint           `json:"id" bson:"_id,omitempty" gorm:"primaryKey" validate:"-"`
+	Username        string         `json:"username" bson:"username" gorm:"uniqueIndex" validate:"required,min=3"`
+	Email           string         `json:"email" bson:"email" gorm:"uniqueIndex" validate:"required,email"`
+	Password        string         `json:"-" bson:"password" gorm:"not null" validate:"required"`
+	Role            string         `json:"role" bson:"role" gorm:"default:'user'"`
+	EmailVerified   bool           `json:"email_verified" bson:"email_verified" gorm:"not null;default:false"`
+	EmailVerifiedAt *time.Time     `json:"email_verified_at,omitempty" bson:"email_verified_at,omitempty"`
+	DisplayName     string         `json:"display_name" bson:"display_name" validate:"max=50"`
+	Bio             string         `json:"bio" bson:"bio" validate:"max=300"`
+	AvatarURL       string         `json:"avatar_url" bson:"avatar_url" validate:"omitempty,url"`
+	BookGenres      []string       `json:"book_genres" bson:"book_genres" gorm:"serializer:json"`
+	FilmGenres      []string       `json:"film_genres" bson:"film_genres" gorm:"serializer:json"`
+	MusicGenres     []string       `json:"music_genres" bson:"music_genres" gorm:"serializer:json"`
+	Version         int64          `json:"version" bson:"version" gorm:"not null;default:1"`
+	CreatedAt       time.Time      `json:"created_at" bson:"created_at"`
+	UpdatedAt       time.Time      `json:"updated_at" bson:"updated_at"`
+	DeletedAt       gorm.DeletedAt `json:"-" bson:"-" gorm:"index"`
+}
+
+// UserUpdatedEvent is the payload of the user_updated topic, published by auth-service on every change.
+// Version grows with each change of the user, replicas drop events older than what they stored.
+type UserUpdatedEvent struct {
+	UserID      string   `json:"user_id"`
+	Version     int64    `json:"version"`
+	Username    string   `json:"username"`
+	Email       string   `json:"email"`
+	DisplayName string   `json:"display_name"`
+	Bio         string   `json:"bio"`
+	AvatarURL   string   `json:"avatar_url"`
+	BookGenres  []string `json:"book_genres"`
+	FilmGenres  []string `json:"film_genres"`
+	MusicGenres []string `json:"music_genres"`
+}
+
+// NewUserUpdatedEvent describes the current state of the user
+func NewUserUpdatedEvent(user *User) UserUpdatedEvent {
+	return UserUpdatedEvent{
+		UserID:      strconv.Itoa(int(user.ID)),
+		Version:     user.Version,
+		Username:    user.Username,
+		Email:       user.Email,
+		DisplayName: user.DisplayName,
+		Bio:         user.Bio,
+		AvatarURL:   user.AvatarURL,
+		BookGenres:  user.BookGenres,
+		FilmGenres:  user.FilmGenres,
+		MusicGenres: user.MusicGenres,
+	}
+}
+
+// User maps the event back to the shared model
+func (e UserUpdatedEvent) User() (User, error) {
+	id, err := strconv.Atoi(e.UserID)
+	if err != nil {
+		return User{}, err
+	}
+	return User{
+		ID:          uint(id),
+		Version:     e.Version,
+		Username:    e.Username,
+		Email:       e.Email,
+		DisplayName: e.DisplayName,
+		Bio:         e.Bio,
+		AvatarURL:   e.AvatarURL,
+		BookGenres:  e.BookGenres,
+		FilmGenres:  e.FilmGenres,
+		MusicGenres: e.MusicGenres,
+	}, nil
 }
@@ -0,0 +1,51 @@
+package observability
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+)
+
+// CacheMetrics counts the hits and misses of a cache, labeled with the cache name
+type CacheMetrics struct {
+	hits   metric.Int64Counter
+	misses metric.Int64Counter
+	attrs  metric.MeasurementOption
+}
+
+// NewCacheMetrics returns the metrics of the named cache, e.g. "post_body".
+// Instruments created before Init are bound to the global provider once it's set.
+func NewCacheMetrics(name string) *CacheMetrics {
+	meter := otel.GetMeterProvider().Meter("github.com/daubog44/progetto/shared/pkg/observability")
+
+	hits, _ := meter.Int64Counter(
+		"cache_hits_total",
+		metric.WithDescription("Total number of cache hits"),
+	)
+	misses, _ := meter.Int64Counter(
+		"cache_misses_total",
+		metric.WithDescription("Total number of cache misses"),
+	)
+
+	return &CacheMetrics{
+		hits:   hits,
+		misses: misses,
+		attrs:  metric.WithAttributes(attribute.String("cache", name)),
+	}
+}
+
+// Hit records n cache hits
+func (m *CacheMetrics) Hit(ctx context.Context, n int) {
+	if n > 0 {
+		m.hits.Add(ctx, int64(n), m.attrs)
+	}
+}
+
+// Miss records n cache misses
+func (m *CacheMetrics) Miss(ctx context.Context, n int) {
+	if n > 0 {
+		m.misses.Add(ctx, int64(n), m.attrs)
+	}
+}
@@ -0,0 +1,73 @@
+// Package outbox implements the transactional outbox pattern on Postgres.
+// Events are written in the same GORM transaction as the state they describe,
+// then a Relay publishes them through a Watermill publisher.
+package outbox
+
+import (
+	"context"
+	_ "embed"
+	"time"
+
+	"github.com/ThreeDotsLabs/watermill/message"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	"gorm.io/gorm"
+)
+
+// Schema creates the outbox_messages table. It is the only definition of the table,
+// services apply it with one of their versioned migrations.
+//
+//go:embed schema.sql
+var Schema string
+
+// Message is an event waiting to be published, a row of the table created by Schema.
+// The auto increment ID gives the publish order of the messages of an aggregate.
+type Message struct {
+	ID            uint64 `gorm:"primaryKey;autoIncrement"`
+	UUID          string
+	Topic         string
+	AggregateID   string
+	Payload       []byte
+	Metadata      map[string]string `gorm:"serializer:json"`
+	Attempts      int
+	LastError     string
+	NextAttemptAt time.Time
+	CreatedAt     time.Time
+	PublishedAt   *time.Time
+}
+
+func (Message) TableName() string {
+	return "outbox_messages"
+}
+
+// Store saves the message in the outbox, tx should be the transaction that writes the aggregate.
+// Messages of the same aggregate are published in the order they are stored.
+func Store(ctx context.Context, tx *gorm.DB, topic, aggregateID string, msg *message.Message) error {
+	metadata := make(map[string]string, len(msg.Metadata)+2)
+	for k, v := range msg.Metadata {
+		metadata[k] = v
+	}
+	// Keep the trace of the request, the relay publishes from another goroutine
+	otel.GetTextMapPropagator().Inject(ctx, propagation.MapCarrier(metadata))
+
+	now := time.Now()
+	return tx.WithContext(ctx).Create(&Message{
+		UUID:          msg.UUID,
+		Topic:         topic,
+		AggregateID:   aggregateID,
+		Payload:       msg.Payload,
+		Metadata:      metadata,
+		NextAttemptAt: now,
+		CreatedAt:     now,
+	}).Error
+}
+
+// toWatermill rebuilds the stored message, with the context of the request that stored it
+func (m *Message) toWatermill(ctx context.Context) *message.Message {
+	msg := message.NewMessage(m.UUID, m.Payload)
+	for k, v := range m.Metadata {
+		msg.Metadata.Set(k, v)
+	}
+	msg.SetContext(otel.GetTextMapPropagator().Extract(ctx, propagation.MapCarrier(m.Metadata)))
+	return msg
+}
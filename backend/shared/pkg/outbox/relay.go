@@ -0,0 +1,180 @@
+package outbox
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/ThreeDotsLabs/watermill/message"
+	"github.com/username/progetto/shared/pkg/watermillutil"
+	"gorm.io/gorm"
+)
+
+// relayLockKey is the Postgres advisory lock held while relaying,
+// with more replicas only one publishes at a time so the order of each aggregate is kept
+const relayLockKey = 0x6f7574626f78 // "outbox"
+
+// RelayConfig configures a Relay, zero values use the defaults
+type RelayConfig struct {
+	// Interval between two polls of the outbox. Default 1s.
+	Interval time.Duration
+	// BatchSize is the max number of messages published per poll. Default 100.
+	BatchSize int
+	// MaxBackoff caps the wait before publishing a failed message again. Default 5m.
+	MaxBackoff time.Duration
+	// Retention is how long published messages are kept. Default 24h.
+	Retention time.Duration
+	// CleanupInterval between two deletions of the expired messages. Default 1h.
+	CleanupInterval time.Duration
+}
+
+// Relay publishes the outbox messages. Messages of an aggregate are published one at a time,
+// a failed message blocks the following ones of its aggregate until it is published.
+// Delivery is at least once, consumers must be idempotent.
+type Relay struct {
+	db        *gorm.DB
+	publisher message.Publisher
+	cfg       RelayConfig
+	logger    *slog.Logger
+}
+
+func NewRelay(db *gorm.DB, publisher message.Publisher, cfg RelayConfig) *Relay {
+	if cfg.Interval <= 0 {
+		cfg.Interval = time.Second
+	}
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = 100
+	}
+	if cfg.MaxBackoff <= 0 {
+		cfg.MaxBackoff = 5 * time.Minute
+	}
+	if cfg.Retention <= 0 {
+		cfg.Retention = 24 * time.Hour
+	}
+	if cfg.CleanupInterval <= 0 {
+		cfg.CleanupInterval = time.Hour
+	}
+	return &Relay{
+		db:        db,
+		publisher: publisher,
+		cfg:       cfg,
+		logger:    slog.Default().With("component", "outbox_relay"),
+	}
+}
+
+// Run relays the outbox until the context is cancelled
+func (r *Relay) Run(ctx context.Context) {
+	ticker := time.NewTicker(r.cfg.Interval)
+	defer ticker.Stop()
+	cleanupTicker := time.NewTicker(r.cfg.CleanupInterval)
+	defer cleanupTicker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			// Each batch publishes the oldest pending message of every aggregate, drain them all
+			for {
+				published, err := r.RelayBatch(ctx)
+				if err != nil {
+					r.logger.ErrorContext(ctx, "failed to relay outbox", "error", err)
+					break
+				}
+				if published == 0 {
+					break
+				}
+			}
+		case <-cleanupTicker.C:
+			deleted, err := r.Cleanup(ctx)
+			if err != nil {
+				r.logger.ErrorContext(ctx, "failed to clean up outbox", "error", err)
+				continue
+			}
+			if deleted > 0 {
+				r.logger.InfoContext(ctx, "outbox cleaned up", "deleted", deleted)
+			}
+		}
+	}
+}
+
+// RelayBatch publishes the oldest pending message of each aggregate and returns how many were published
+func (r *Relay) RelayBatch(ctx context.Context) (int, error) {
+	published := 0
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var locked bool
+		if err := tx.Raw("SELECT pg_try_advisory_xact_lock(?)", relayLockKey).Scan(&locked).Error; err != nil {
+			return err
+		}
+		if !locked {
+			// Another replica is relaying
+			return nil
+		}
+
+		var messages []Message
+		err := tx.Where("published_at IS NULL AND next_attempt_at <= ?", time.Now()).
+			Where(`NOT EXISTS (SELECT 1 FROM outbox_messages prev
+				WHERE prev.aggregate_id = outbox_messages.aggregate_id
+				AND prev.published_at IS NULL AND prev.id < outbox_messages.id)`).
+			Order("id").
+			Limit(r.cfg.BatchSize).
+			Find(&messages).Error
+		if err != nil {
+			return err
+		}
+
+		for i := range messages {
+			m := &messages[i]
+			if err := r.publish(ctx, m); err != nil {
+				backoff := r.backoff(m.Attempts)
+				r.logger.WarnContext(ctx, "failed to publish outbox message, retrying later",
+					"error", err, "topic", m.Topic, "aggregate_id", m.AggregateID, "attempts", m.Attempts+1, "backoff", backoff)
+
+				err = tx.Model(m).Updates(map[string]interface{}{
+					"attempts":        gorm.Expr("attempts + 1"),
+					"last_error":      err.Error(),
+					"next_attempt_at": time.Now().Add(backoff),
+				}).Error
+				if err != nil {
+					return err
+				}
+				continue
+			}
+
+			if err := tx.Model(m).Update("published_at", time.Now()).Error; err != nil {
+				return err
+			}
+			published++
+		}
+		return nil
+	})
+	return published, err
+}
+
+func (r *Relay) publish(ctx context.Context, m *Message) error {
+	msg := m.toWatermill(ctx)
+	// Messages of an aggregate go to the same partition, so consumers see them in order
+	msg.Metadata.Set(watermillutil.PartitionKeyMetadata, m.AggregateID)
+	if err := r.publisher.Publish(m.Topic, msg); err != nil {
+		return fmt.Errorf("failed to publish event: %w", err)
+	}
+	return nil
+}
+
+// backoff doubles the wait after every failed attempt, up to MaxBackoff
+func (r *Relay) backoff(attempts int) time.Duration {
+	wait := r.cfg.Interval
+	for i := 0; i < attempts && wait < r.cfg.MaxBackoff; i++ {
+		wait *= 2
+	}
+	return min(wait, r.cfg.MaxBackoff)
+}
+
+// Cleanup deletes the messages published longer than Retention ago
+func (r *Relay) Cleanup(ctx context.Context) (int64, error) {
+	result := r.db.WithContext(ctx).
+		Where("published_at < ?", time.Now().Add(-r.cfg.Retention)).
+		Delete(&Message{})
+	return result.RowsAffected, result.Error
+}
@@ -0,0 +1,271 @@
+package outbox
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ThreeDotsLabs/watermill/message"
+	sqlite "github.com/glebarez/go-sqlite"
+	gormsqlite "github.com/glebarez/sqlite"
+	"github.com/username/progetto/shared/pkg/watermillutil"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+var (
+	registerLockOnce sync.Once
+	// lockHeld makes pg_try_advisory_xact_lock fail, as if another replica were relaying
+	lockHeld atomic.Bool
+)
+
+// newTestDB opens an in-memory SQLite database with the outbox table created by Schema.
+// SQLite has no BIGSERIAL, TIMESTAMPTZ nor advisory locks: the ID becomes an INTEGER auto increment,
+// timestamps DATETIME, which the driver scans into time.Time, and pg_try_advisory_xact_lock is stubbed.
+func newTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	registerLockOnce.Do(func() {
+		err := sqlite.RegisterScalarFunction("pg_try_advisory_xact_lock", 1,
+			func(*sqlite.FunctionContext, []driver.Value) (driver.Value, error) {
+				return !lockHeld.Load(), nil
+			})
+		if err != nil {
+			t.Fatalf("failed to register the advisory lock stub: %v", err)
+		}
+	})
+
+	db, err := gorm.Open(gormsqlite.Open("file::memory:"), &gorm.Config{Logger: logger.Discard})
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatalf("failed to get database: %v", err)
+	}
+	// Every connection would open its own in-memory database
+	sqlDB.SetMaxOpenConns(1)
+	t.Cleanup(func() { sqlDB.Close() })
+
+	schema := strings.NewReplacer(
+		"BIGSERIAL PRIMARY KEY", "INTEGER PRIMARY KEY AUTOINCREMENT",
+		"TIMESTAMPTZ", "DATETIME",
+	).Replace(Schema)
+	if err := db.Exec(schema).Error; err != nil {
+		t.Fatalf("failed to create the outbox table: %v", err)
+	}
+	return db
+}
+
+// fakePublisher records the published messages, failing the first failures[uuid] attempts of a message
+type fakePublisher struct {
+	failures  map[string]int
+	published []*message.Message
+}
+
+func (p *fakePublisher) Publish(_ string, messages ...*message.Message) error {
+	for _, msg := range messages {
+		if p.failures[msg.UUID] > 0 {
+			p.failures[msg.UUID]--
+			return errors.New("broker unavailable")
+		}
+		p.published = append(p.published, msg)
+	}
+	return nil
+}
+
+func (p *fakePublisher) Close() error { return nil }
+
+func storeMessages(t *testing.T, db *gorm.DB, messages [][2]string) {
+	t.Helper()
+	for _, m := range messages {
+		uuid, aggregateID := m[0], m[1]
+		if err := Store(context.Background(), db, "topic", aggregateID, message.NewMessage(uuid, []byte(uuid))); err != nil {
+			t.Fatalf("Store() error = %v", err)
+		}
+	}
+}
+
+// makeDue lets the failed messages be published again, as if their backoff was over
+func makeDue(t *testing.T, db *gorm.DB) {
+	t.Helper()
+	err := db.Model(&Message{}).Where("published_at IS NULL").
+		Update("next_attempt_at", time.Now().Add(-time.Second)).Error
+	if err != nil {
+		t.Fatalf("failed to make the messages due: %v", err)
+	}
+}
+
+func TestRelayBatch(t *testing.T) {
+	// a1, a2 and a3 belong to aggregate a, b1 and b2 to aggregate b
+	stored := [][2]string{{"a1", "a"}, {"b1", "b"}, {"a2", "a"}, {"a3", "a"}, {"b2", "b"}}
+
+	type batch struct {
+		// due makes the failed messages due before the batch
+		due  bool
+		want []string
+	}
+	tests := []struct {
+		name     string
+		failures map[string]int
+		batches  []batch
+	}{
+		{
+			name: "oldest message of each aggregate per batch",
+			batches: []batch{
+				{want: []string{"a1", "b1"}},
+				{want: []string{"a2", "b2"}},
+				{want: []string{"a3"}},
+				{want: nil},
+			},
+		},
+		{
+			name:     "failed message blocks its aggregate",
+			failures: map[string]int{"a1": 2},
+			batches: []batch{
+				{want: []string{"b1"}},
+				{want: []string{"b2"}},
+				{want: nil},
+				{due: true, want: nil},
+				{due: true, want: []string{"a1"}},
+				{want: []string{"a2"}},
+				{want: []string{"a3"}},
+			},
+		},
+		{
+			name:     "failed message in the middle of an aggregate",
+			failures: map[string]int{"a2": 1},
+			batches: []batch{
+				{want: []string{"a1", "b1"}},
+				{want: []string{"b2"}},
+				{want: nil},
+				{due: true, want: []string{"a2"}},
+				{want: []string{"a3"}},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := newTestDB(t)
+			storeMessages(t, db, stored)
+			publisher := &fakePublisher{failures: tt.failures}
+			relay := NewRelay(db, publisher, RelayConfig{Interval: time.Minute})
+
+			for i, b := range tt.batches {
+				if b.due {
+					makeDue(t, db)
+				}
+				before := len(publisher.published)
+				n, err := relay.RelayBatch(context.Background())
+				if err != nil {
+					t.Fatalf("RelayBatch() #%d error = %v", i, err)
+				}
+
+				var got []string
+				for _, msg := range publisher.published[before:] {
+					got = append(got, msg.UUID)
+				}
+				if !slices.Equal(got, b.want) {
+					t.Errorf("RelayBatch() #%d published %v, want %v", i, got, b.want)
+				}
+				if n != len(b.want) {
+					t.Errorf("RelayBatch() #%d = %d, want %d", i, n, len(b.want))
+				}
+			}
+		})
+	}
+}
+
+func TestRelayBatchPublish(t *testing.T) {
+	db := newTestDB(t)
+	storeMessages(t, db, [][2]string{{"a1", "a"}, {"b1", "b"}})
+	publisher := &fakePublisher{failures: map[string]int{"b1": 1}}
+	relay := NewRelay(db, publisher, RelayConfig{Interval: time.Minute})
+
+	start := time.Now()
+	if _, err := relay.RelayBatch(context.Background()); err != nil {
+		t.Fatalf("RelayBatch() error = %v", err)
+	}
+
+	if len(publisher.published) != 1 {
+		t.Fatalf("published %d messages, want 1", len(publisher.published))
+	}
+	// Messages of an aggregate go to the same partition
+	if got := publisher.published[0].Metadata.Get(watermillutil.PartitionKeyMetadata); got != "a" {
+		t.Errorf("partition key = %q, want %q", got, "a")
+	}
+
+	var published, failed Message
+	if err := db.Where("uuid = ?", "a1").First(&published).Error; err != nil {
+		t.Fatalf("failed to read the published message: %v", err)
+	}
+	if published.PublishedAt == nil {
+		t.Errorf("published message PublishedAt = nil, want set")
+	}
+	if err := db.Where("uuid = ?", "b1").First(&failed).Error; err != nil {
+		t.Fatalf("failed to read the failed message: %v", err)
+	}
+	if failed.PublishedAt != nil || failed.Attempts != 1 || failed.LastError == "" {
+		t.Errorf("failed message = %+v, want unpublished with 1 attempt and its error", failed)
+	}
+	if wait := failed.NextAttemptAt.Sub(start); wait < time.Minute || wait > time.Minute+5*time.Second {
+		t.Errorf("failed message retried after %s, want %s", wait, time.Minute)
+	}
+}
+
+func TestRelayBatchLocked(t *testing.T) {
+	db := newTestDB(t)
+	storeMessages(t, db, [][2]string{{"a1", "a"}})
+	publisher := &fakePublisher{}
+	relay := NewRelay(db, publisher, RelayConfig{})
+
+	lockHeld.Store(true)
+	defer lockHeld.Store(false)
+	n, err := relay.RelayBatch(context.Background())
+	if err != nil {
+		t.Fatalf("RelayBatch() error = %v", err)
+	}
+	if n != 0 || len(publisher.published) != 0 {
+		t.Errorf("RelayBatch() published %d messages while another replica relays, want 0", len(publisher.published))
+	}
+}
+
+func TestRelayBackoff(t *testing.T) {
+	tests := []struct {
+		name     string
+		attempts int
+		want     time.Duration
+	}{
+		{name: "first failure", attempts: 0, want: time.Second},
+		{name: "second failure", attempts: 1, want: 2 * time.Second},
+		{name: "third failure", attempts: 2, want: 4 * time.Second},
+		{name: "capped", attempts: 3, want: 5 * time.Second},
+		{name: "capped without overflow", attempts: 1000, want: 5 * time.Second},
+	}
+
+	relay := NewRelay(nil, nil, RelayConfig{Interval: time.Second, MaxBackoff: 5 * time.Second})
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := relay.backoff(tt.attempts); got != tt.want {
+				t.Errorf("backoff(%d) = %s, want %s", tt.attempts, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStoreDuplicateUUID(t *testing.T) {
+	db := newTestDB(t)
+	storeMessages(t, db, [][2]string{{"a1", "a"}})
+
+	// Redelivered requests can't store their event twice
+	err := Store(context.Background(), db, "topic", "a", message.NewMessage("a1", nil))
+	if err == nil {
+		t.Errorf("Store() of a duplicate UUID error = nil, want an error")
+	}
+}
@@ -0,0 +1,18 @@
+-- Outbox table, services apply it with one of their versioned migrations (see Schema)
+
+CREATE TABLE IF NOT EXISTS outbox_messages (
+    id BIGSERIAL PRIMARY KEY,
+    uuid TEXT NOT NULL,
+    topic TEXT NOT NULL,
+    aggregate_id TEXT NOT NULL,
+    payload BYTEA NOT NULL,
+    metadata TEXT,
+    attempts BIGINT NOT NULL DEFAULT 0,
+    last_error TEXT,
+    next_attempt_at TIMESTAMPTZ NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL,
+    published_at TIMESTAMPTZ
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_outbox_messages_uuid ON outbox_messages (uuid);
+CREATE INDEX IF NOT EXISTS idx_outbox_messages_aggregate_id ON outbox_messages (aggregate_id);
+CREATE INDEX IF NOT EXISTS idx_outbox_messages_published_at ON outbox_messages (published_at);
@@ -0,0 +1,48 @@
+package ratelimit
+
+import (
+	"log/slog"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// SetHeaders writes the RateLimit headers of the IETF draft, and Retry-After on rejected requests
+func SetHeaders(h http.Header, limit Limit, res Result) {
+	h.Set("RateLimit-Policy", limit.Policy())
+	h.Set("RateLimit-Limit", strconv.Itoa(res.Limit))
+	h.Set("RateLimit-Remaining", strconv.Itoa(res.Remaining))
+	h.Set("RateLimit-Reset", strconv.Itoa(seconds(res.Reset)))
+	if !res.Allowed {
+		h.Set("Retry-After", strconv.Itoa(max(seconds(res.RetryAfter), 1)))
+	}
+}
+
+func seconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
+
+// Middleware limits the requests of each key returned by keyFunc, e.g. the client IP.
+// Requests are let through when Redis is down, the limiter must not take the API down with it.
+func Middleware(limiter Limiter, limit Limit, keyFunc func(r *http.Request) string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			res, err := limiter.Allow(r.Context(), keyFunc(r), limit)
+			if err != nil {
+				slog.ErrorContext(r.Context(), "rate limit check failed", "error", err)
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			SetHeaders(w.Header(), limit, res)
+			if !res.Allowed {
+				w.Header().Set("Content-Type", "application/problem+json")
+				w.WriteHeader(http.StatusTooManyRequests)
+				_, _ = w.Write([]byte(`{"title":"Too Many Requests","status":429,"detail":"rate limit exceeded"}`))
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
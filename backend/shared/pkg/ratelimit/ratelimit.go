@@ -0,0 +1,112 @@
+// Package ratelimit implements distributed rate limiting on Redis.
+// Each check runs a Lua script, so replicas sharing the Redis instance share the limits.
+package ratelimit
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+type Algorithm string
+
+const (
+	// TokenBucket allows bursts up to Burst requests, refilled at Requests per Period
+	TokenBucket Algorithm = "token_bucket"
+	// SlidingWindow allows Requests in any window of Period, it has no burst on window edges
+	SlidingWindow Algorithm = "sliding_window"
+)
+
+// Limit is a rate limit policy
+type Limit struct {
+	Algorithm Algorithm
+	Requests  int
+	Period    time.Duration
+	// Burst is the token bucket capacity, Requests when zero. Ignored by SlidingWindow.
+	Burst int
+}
+
+// Quota is the number of requests a client can make right away
+func (l Limit) Quota() int {
+	if l.Algorithm == TokenBucket && l.Burst > 0 {
+		return l.Burst
+	}
+	return l.Requests
+}
+
+// Policy formats the limit for the RateLimit-Policy header, e.g. "100;w=60"
+func (l Limit) Policy() string {
+	return fmt.Sprintf("%d;w=%d", l.Quota(), int(l.Period.Seconds()))
+}
+
+// Result is the outcome of a rate limit check
+type Result struct {
+	Allowed   bool
+	Limit     int
+	Remaining int
+	// Reset is when the quota is fully available again
+	Reset time.Duration
+	// RetryAfter is when the next request can be allowed, zero when this one was
+	RetryAfter time.Duration
+}
+
+// Limiter checks and counts the requests of a key, e.g. a user or an IP
+type Limiter interface {
+	Allow(ctx context.Context, key string, limit Limit) (Result, error)
+}
+
+// RedisLimiter implements Limiter with atomic Lua scripts.
+// Time comes from the Redis server, so the clocks of the replicas don't matter.
+type RedisLimiter struct {
+	client *redis.Client
+	prefix string
+}
+
+func NewRedisLimiter(client *redis.Client, prefix string) *RedisLimiter {
+	return &RedisLimiter{
+		client: client,
+		prefix: prefix,
+	}
+}
+
+func (l *RedisLimiter) Allow(ctx context.Context, key string, limit Limit) (Result, error) {
+	if limit.Requests <= 0 || limit.Period <= 0 {
+		return Result{}, fmt.Errorf("invalid rate limit: %d requests per %s", limit.Requests, limit.Period)
+	}
+	fullKey := fmt.Sprintf("%s:%s:%s", l.prefix, limit.Algorithm, key)
+
+	var values []int64
+	var err error
+	switch limit.Algorithm {
+	case TokenBucket:
+		// Tokens refilled per millisecond
+		rate := float64(limit.Requests) / float64(limit.Period.Milliseconds())
+		values, err = tokenBucketScript.Run(ctx, l.client, []string{fullKey}, limit.Quota(), rate).Int64Slice()
+	case SlidingWindow:
+		values, err = slidingWindowScript.Run(ctx, l.client, []string{fullKey}, limit.Requests, limit.Period.Milliseconds(), requestID()).Int64Slice()
+	default:
+		return Result{}, fmt.Errorf("unknown rate limit algorithm %q", limit.Algorithm)
+	}
+	if err != nil {
+		return Result{}, fmt.Errorf("failed to check rate limit in redis: %w", err)
+	}
+
+	return Result{
+		Allowed:    values[0] == 1,
+		Limit:      limit.Quota(),
+		Remaining:  int(values[1]),
+		RetryAfter: time.Duration(values[2]) * time.Millisecond,
+		Reset:      time.Duration(values[3]) * time.Millisecond,
+	}, nil
+}
+
+// requestID tells apart the requests of the same millisecond in the sliding window
+func requestID() string {
+	b := make([]byte, 8)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
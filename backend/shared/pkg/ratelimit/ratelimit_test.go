@@ -0,0 +1,193 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+// step is a request made after advancing the Redis clock
+type step struct {
+	advance       time.Duration
+	wantAllowed   bool
+	wantRemaining int
+	wantRetry     time.Duration
+	wantReset     time.Duration
+}
+
+func newTestLimiter(t *testing.T) (*RedisLimiter, func(time.Duration)) {
+	t.Helper()
+	mr := miniredis.RunT(t)
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	mr.SetTime(now)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { client.Close() })
+
+	advance := func(d time.Duration) {
+		now = now.Add(d)
+		mr.SetTime(now)
+		mr.FastForward(d)
+	}
+	return NewRedisLimiter(client, "test"), advance
+}
+
+func runSteps(t *testing.T, limit Limit, steps []step) {
+	t.Helper()
+	limiter, advance := newTestLimiter(t)
+	for i, s := range steps {
+		advance(s.advance)
+		got, err := limiter.Allow(context.Background(), "client", limit)
+		if err != nil {
+			t.Fatalf("Allow() #%d error = %v", i, err)
+		}
+		want := Result{
+			Allowed:    s.wantAllowed,
+			Limit:      limit.Quota(),
+			Remaining:  s.wantRemaining,
+			RetryAfter: s.wantRetry,
+			Reset:      s.wantReset,
+		}
+		if got != want {
+			t.Errorf("Allow() #%d = %+v, want %+v", i, got, want)
+		}
+	}
+}
+
+func TestRedisLimiterTokenBucket(t *testing.T) {
+	tests := []struct {
+		name  string
+		limit Limit
+		steps []step
+	}{
+		{
+			name:  "burst then rejected until a refill",
+			limit: Limit{Algorithm: TokenBucket, Requests: 1, Period: time.Second, Burst: 3},
+			steps: []step{
+				{wantAllowed: true, wantRemaining: 2, wantReset: time.Second},
+				{wantAllowed: true, wantRemaining: 1, wantReset: 2 * time.Second},
+				{wantAllowed: true, wantRemaining: 0, wantReset: 3 * time.Second},
+				{wantAllowed: false, wantRemaining: 0, wantRetry: time.Second, wantReset: 3 * time.Second},
+				{advance: 400 * time.Millisecond, wantAllowed: false, wantRemaining: 0, wantRetry: 600 * time.Millisecond, wantReset: 2600 * time.Millisecond},
+				{advance: 600 * time.Millisecond, wantAllowed: true, wantRemaining: 0, wantReset: 3 * time.Second},
+			},
+		},
+		{
+			name:  "refill capped at the burst",
+			limit: Limit{Algorithm: TokenBucket, Requests: 1, Period: time.Second, Burst: 2},
+			steps: []step{
+				{wantAllowed: true, wantRemaining: 1, wantReset: time.Second},
+				{advance: time.Hour, wantAllowed: true, wantRemaining: 1, wantReset: time.Second},
+				{wantAllowed: true, wantRemaining: 0, wantReset: 2 * time.Second},
+				{wantAllowed: false, wantRemaining: 0, wantRetry: time.Second, wantReset: 2 * time.Second},
+			},
+		},
+		{
+			name:  "capacity defaults to the requests",
+			limit: Limit{Algorithm: TokenBucket, Requests: 2, Period: 2 * time.Second},
+			steps: []step{
+				{wantAllowed: true, wantRemaining: 1, wantReset: time.Second},
+				{wantAllowed: true, wantRemaining: 0, wantReset: 2 * time.Second},
+				{wantAllowed: false, wantRemaining: 0, wantRetry: time.Second, wantReset: 2 * time.Second},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			runSteps(t, tt.limit, tt.steps)
+		})
+	}
+}
+
+func TestRedisLimiterSlidingWindow(t *testing.T) {
+	tests := []struct {
+		name  string
+		limit Limit
+		steps []step
+	}{
+		{
+			name:  "rejected until the oldest request leaves the window",
+			limit: Limit{Algorithm: SlidingWindow, Requests: 3, Period: 10 * time.Second},
+			steps: []step{
+				{wantAllowed: true, wantRemaining: 2, wantReset: 10 * time.Second},
+				{advance: time.Second, wantAllowed: true, wantRemaining: 1, wantReset: 9 * time.Second},
+				{advance: time.Second, wantAllowed: true, wantRemaining: 0, wantReset: 8 * time.Second},
+				{advance: 4 * time.Second, wantAllowed: false, wantRemaining: 0, wantRetry: 4 * time.Second, wantReset: 4 * time.Second},
+				{advance: 4 * time.Second, wantAllowed: true, wantRemaining: 0, wantReset: time.Second},
+				{wantAllowed: false, wantRemaining: 0, wantRetry: time.Second, wantReset: time.Second},
+			},
+		},
+		{
+			name:  "burst ignored",
+			limit: Limit{Algorithm: SlidingWindow, Requests: 1, Period: time.Second, Burst: 5},
+			steps: []step{
+				{wantAllowed: true, wantRemaining: 0, wantReset: time.Second},
+				{advance: 500 * time.Millisecond, wantAllowed: false, wantRemaining: 0, wantRetry: 500 * time.Millisecond, wantReset: 500 * time.Millisecond},
+				{advance: time.Second, wantAllowed: true, wantRemaining: 0, wantReset: time.Second},
+			},
+		},
+		{
+			name:  "rejected requests not counted",
+			limit: Limit{Algorithm: SlidingWindow, Requests: 1, Period: time.Second},
+			steps: []step{
+				{wantAllowed: true, wantRemaining: 0, wantReset: time.Second},
+				{advance: 200 * time.Millisecond, wantAllowed: false, wantRemaining: 0, wantRetry: 800 * time.Millisecond, wantReset: 800 * time.Millisecond},
+				{advance: 200 * time.Millisecond, wantAllowed: false, wantRemaining: 0, wantRetry: 600 * time.Millisecond, wantReset: 600 * time.Millisecond},
+				{advance: 600 * time.Millisecond, wantAllowed: true, wantRemaining: 0, wantReset: time.Second},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			runSteps(t, tt.limit, tt.steps)
+		})
+	}
+}
+
+func TestRedisLimiterKeys(t *testing.T) {
+	limiter, _ := newTestLimiter(t)
+	ctx := context.Background()
+	limit := Limit{Algorithm: SlidingWindow, Requests: 1, Period: time.Minute}
+
+	for _, key := range []string{"a", "b"} {
+		res, err := limiter.Allow(ctx, key, limit)
+		if err != nil {
+			t.Fatalf("Allow(%q) error = %v", key, err)
+		}
+		if !res.Allowed {
+			t.Errorf("Allow(%q) allowed = false, want true", key)
+		}
+	}
+	// The algorithms of a key don't share their state
+	res, err := limiter.Allow(ctx, "a", Limit{Algorithm: TokenBucket, Requests: 1, Period: time.Minute})
+	if err != nil {
+		t.Fatalf("Allow() error = %v", err)
+	}
+	if !res.Allowed {
+		t.Errorf("Allow() with another algorithm allowed = false, want true")
+	}
+}
+
+func TestRedisLimiterInvalidLimit(t *testing.T) {
+	tests := []struct {
+		name  string
+		limit Limit
+	}{
+		{name: "no requests", limit: Limit{Algorithm: TokenBucket, Period: time.Second}},
+		{name: "no period", limit: Limit{Algorithm: SlidingWindow, Requests: 1}},
+		{name: "unknown algorithm", limit: Limit{Algorithm: "leaky_bucket", Requests: 1, Period: time.Second}},
+	}
+
+	limiter, _ := newTestLimiter(t)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := limiter.Allow(context.Background(), "client", tt.limit); err == nil {
+				t.Errorf("Allow() error = nil, want an error")
+			}
+		})
+	}
+}
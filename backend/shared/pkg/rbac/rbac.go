@@ -0,0 +1,46 @@
+// Package rbac defines the permissions checked across services and the default roles granting them.
+// Roles and their permissions live in the auth-service database, these are only the seed.
+package rbac
+
+// Permissions, named <resource>:<action>
+const (
+	PermUsersRead       = "users:read"
+	PermUsersManage     = "users:manage"
+	PermRolesManage     = "roles:manage"
+	PermContentModerate = "content:moderate"
+	PermCatalogManage   = "catalog:manage"
+)
+
+// Default roles, every user starts as RoleUser
+const (
+	RoleUser      = "user"
+	RoleModerator = "moderator"
+	RoleCurator   = "curator"
+	RoleAdmin     = "admin"
+)
+
+type PermissionDefinition struct {
+	Name        string
+	Description string
+}
+
+type RoleDefinition struct {
+	Name        string
+	Description string
+	Permissions []string
+}
+
+var DefaultPermissions = []PermissionDefinition{
+	{Name: PermUsersRead, Description: "View user accounts and their moderation history"},
+	{Name: PermUsersManage, Description: "Suspend, ban and reinstate users"},
+	{Name: PermRolesManage, Description: "Assign roles to users"},
+	{Name: PermContentModerate, Description: "Hide or remove posts and comments of other users"},
+	{Name: PermCatalogManage, Description: "Edit the books, films and music of the cultural catalog"},
+}
+
+var DefaultRoles = []RoleDefinition{
+	{Name: RoleUser, Description: "Regular user"},
+	{Name: RoleModerator, Description: "Moderates the content of the users", Permissions: []string{PermUsersRead, PermContentModerate}},
+	{Name: RoleCurator, Description: "Curates the cultural catalog", Permissions: []string{PermCatalogManage}},
+	{Name: RoleAdmin, Description: "Full access", Permissions: []string{PermUsersRead, PermUsersManage, PermRolesManage, PermContentModerate, PermCatalogManage}},
+}
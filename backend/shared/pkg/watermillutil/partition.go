@@ -0,0 +1,28 @@
+package watermillutil
+
+import (
+	"github.com/IBM/sarama"
+	"github.com/ThreeDotsLabs/watermill-kafka/v3/pkg/kafka"
+	"github.com/ThreeDotsLabs/watermill/message"
+)
+
+// PartitionKeyMetadata is the metadata key used as Kafka message key.
+// Messages with the same key land on the same partition and keep their order.
+const PartitionKeyMetadata = "partition_key"
+
+// partitionKeyMarshaler is the DefaultMarshaler plus the partition key.
+// Without a key the message goes to a random partition, like with the DefaultMarshaler.
+type partitionKeyMarshaler struct {
+	kafka.DefaultMarshaler
+}
+
+func (m partitionKeyMarshaler) Marshal(topic string, msg *message.Message) (*sarama.ProducerMessage, error) {
+	kafkaMsg, err := m.DefaultMarshaler.Marshal(topic, msg)
+	if err != nil {
+		return nil, err
+	}
+	if key := msg.Metadata.Get(PartitionKeyMetadata); key != "" {
+		kafkaMsg.Key = sarama.StringEncoder(key)
+	}
+	return kafkaMsg, nil
+}
@@ -108,7 +108,7 @@ func NewKafkaPublisher(brokers string, logger *slog.Logger) (message.Publisher,
 	publisher, err := kafka.NewPublisher(
 		kafka.PublisherConfig{
 			Brokers:   strings.Split(brokers, ","),
-			Marshaler: kafka.DefaultMarshaler{},
+			Marshaler: partitionKeyMarshaler{},
 		},
 		wLogger,
 	)